@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"merchandise-review-list-backend/model"
 	"merchandise-review-list-backend/usecase"
 	"net/http"
@@ -14,6 +15,7 @@ type IBudgetController interface {
 	CreateBudget(c echo.Context) error
 	UpdateBudget(c echo.Context) error
 	GetBudgetByUserId(c echo.Context) error
+	ExportBudgetActuals(c echo.Context) error
 }
 
 type budgetController struct {
@@ -82,3 +84,25 @@ func (bc *budgetController) GetBudgetByUserId(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// ExportBudgetActuals は指定した年の月・カテゴリーごとの予算と実績をCSV・JSONでダウンロードさせる
+func (bc *budgetController) ExportBudgetActuals(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.BudgetExportRequest{
+		Year:     c.QueryParam("year"),
+		Format:   c.QueryParam("format"),
+		Encoding: c.QueryParam("encoding"),
+	}
+	if req.Format == "" {
+		req.Format = model.ExportFormatCSV
+	}
+
+	w := newExportWriter(c, req.Format, req.Encoding, fmt.Sprintf("budget_actuals_%s", req.Year))
+	if err := bc.bu.ExportBudgetActuals(w, req, uint(userId.(float64))); err != nil {
+		return exportError(c, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type ICalendarFeedController interface {
+	GetFeed(c echo.Context) error
+	RegenerateFeed(c echo.Context) error
+	GetICS(c echo.Context) error
+}
+
+type calendarFeedController struct {
+	cfu usecase.ICalendarFeedUsecase
+}
+
+func NewCalendarFeedController(cfu usecase.ICalendarFeedUsecase) ICalendarFeedController {
+	return &calendarFeedController{cfu}
+}
+
+func (cfc *calendarFeedController) GetFeed(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	feedRes, err := cfc.cfu.GetFeed(uint(userId.(float64)), calendarBaseUrl(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, feedRes)
+}
+
+func (cfc *calendarFeedController) RegenerateFeed(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	feedRes, err := cfc.cfu.RegenerateFeed(uint(userId.(float64)), calendarBaseUrl(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, feedRes)
+}
+
+func (cfc *calendarFeedController) GetICS(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ics, err := cfc.cfu.GetICS(token)
+	// 再発行前の古いトークンや存在しないトークン
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, "calendar feed not found")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// calendarBaseUrl はカレンダーのURLの起点を返す（環境変数CALENDAR_BASE_URLが未指定の場合はリクエストのホスト）
+func calendarBaseUrl(c echo.Context) string {
+	if baseUrl := os.Getenv("CALENDAR_BASE_URL"); baseUrl != "" {
+		return baseUrl
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
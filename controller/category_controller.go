@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ICategoryController interface {
+	GetCategories(c echo.Context) error
+}
+
+type categoryController struct {
+	cu usecase.ICategoryUsecase
+}
+
+func NewCategoryController(cu usecase.ICategoryUsecase) ICategoryController {
+	return &categoryController{cu}
+}
+
+func (cgc *categoryController) GetCategories(c echo.Context) error {
+	lang := c.QueryParam("lang")
+
+	categoriesRes, err := cgc.cu.GetCategories(lang)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"categories": categoriesRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
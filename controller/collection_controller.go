@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ICollectionController interface {
+	CreateCollection(c echo.Context) error
+	UpdateCollection(c echo.Context) error
+	DeleteCollection(c echo.Context) error
+	GetMyCollections(c echo.Context) error
+	GetMyCollectionById(c echo.Context) error
+	GetSharedCollection(c echo.Context) error
+	AddItem(c echo.Context) error
+	RemoveItem(c echo.Context) error
+	ReorderItems(c echo.Context) error
+}
+
+type collectionController struct {
+	cu usecase.ICollectionUsecase
+}
+
+func NewCollectionController(cu usecase.ICollectionUsecase) ICollectionController {
+	return &collectionController{cu}
+}
+
+func (clc *collectionController) CreateCollection(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	collection := model.Collection{}
+	if err := c.Bind(&collection); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	collection.UserId = uint(userId.(float64))
+
+	collectionRes, err := clc.cu.CreateCollection(collection)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, collectionRes)
+}
+
+func (clc *collectionController) UpdateCollection(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	collection := model.Collection{}
+	if err := c.Bind(&collection); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	collectionRes, err := clc.cu.UpdateCollection(collection, uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, collectionRes)
+}
+
+func (clc *collectionController) DeleteCollection(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	err := clc.cu.DeleteCollection(uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (clc *collectionController) GetMyCollections(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	collectionsRes, err := clc.cu.GetMyCollections(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"collections": collectionsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (clc *collectionController) GetMyCollectionById(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	collectionRes, err := clc.cu.GetMyCollectionById(uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, collectionRes)
+}
+
+func (clc *collectionController) GetSharedCollection(c echo.Context) error {
+	slug := c.Param("slug")
+	userId, _ := strconv.Atoi(c.QueryParam("userId"))
+
+	collectionRes, err := clc.cu.GetSharedCollection(slug, uint(userId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, collectionRes)
+}
+
+func (clc *collectionController) AddItem(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	item := model.CollectionItemRequest{}
+	if err := c.Bind(&item); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	collectionRes, err := clc.cu.AddItem(uint(userId.(float64)), uint(id), item.PostId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, collectionRes)
+}
+
+func (clc *collectionController) RemoveItem(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	postId, _ := strconv.Atoi(c.Param("postId"))
+
+	err := clc.cu.RemoveItem(uint(userId.(float64)), uint(id), uint(postId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (clc *collectionController) ReorderItems(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	order := model.CollectionItemOrderRequest{}
+	if err := c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	collectionRes, err := clc.cu.ReorderItems(uint(userId.(float64)), uint(id), order.PostIds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, collectionRes)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IModerationController interface {
+	GetPendingHolds(c echo.Context) error
+	ApproveHold(c echo.Context) error
+	RejectHold(c echo.Context) error
+}
+
+type moderationController struct {
+	mu usecase.IModerationUsecase
+}
+
+func NewModerationController(mu usecase.IModerationUsecase) IModerationController {
+	return &moderationController{mu}
+}
+
+func (mdc *moderationController) GetPendingHolds(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	holdsRes, totalPageCount, err := mdc.mu.GetPendingHolds(uint(userId.(float64)), page, pageSize)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"totalPageCount": totalPageCount,
+		"holds":          holdsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (mdc *moderationController) ApproveHold(c echo.Context) error {
+	return mdc.resolveHold(c, true)
+}
+
+func (mdc *moderationController) RejectHold(c echo.Context) error {
+	return mdc.resolveHold(c, false)
+}
+
+func (mdc *moderationController) resolveHold(c echo.Context, approve bool) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := mdc.mu.ResolveHold(uint(userId.(float64)), uint(id), approve); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
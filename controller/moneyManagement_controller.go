@@ -1,10 +1,18 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/spreadsheet"
 	"merchandise-review-list-backend/usecase"
+	"merchandise-review-list-backend/validator"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -16,6 +24,8 @@ type IMoneyManagementController interface {
 	UpdateMoneyManagement(c echo.Context) error
 	DeleteMoneyManagement(c echo.Context) error
 	GetMyMoneyManagements(c echo.Context) error
+	ImportMoneyManagements(c echo.Context) error
+	ExportMoneyManagements(c echo.Context) error
 }
 
 type moneyManagementController struct {
@@ -104,3 +114,128 @@ func (mc *moneyManagementController) GetMyMoneyManagements(c echo.Context) error
 
 	return c.JSON(http.StatusOK, moneyManagementsRes)
 }
+
+// ImportMoneyManagements はmultipart/form-dataのfileで受け取ったCSV・JSONを一括登録する
+// format（省略時は拡張子から判定）、encoding、mapping（項目名と列名の対応のJSON）、dryRun、allowDuplicatesはフォームまたはクエリで指定する
+func (mc *moneyManagementController) ImportMoneyManagements(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "file is required")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	// 上限を超えたことを検知できるよう1バイト多く読む
+	data, err := io.ReadAll(io.LimitReader(f, validator.MaxMoneyManagementImportSize+1))
+	f.Close()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	req := model.MoneyManagementImportRequest{
+		Format:   c.FormValue("format"),
+		Encoding: c.FormValue("encoding"),
+		Mapping:  map[string]string{},
+		Data:     data,
+	}
+	if req.Format == "" {
+		req.Format = model.MoneyManagementImportFormatCSV
+		if strings.EqualFold(filepath.Ext(fh.Filename), ".json") {
+			req.Format = model.MoneyManagementImportFormatJSON
+		}
+	}
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &req.Mapping); err != nil {
+			return c.JSON(http.StatusBadRequest, "Invalid mapping format")
+		}
+	}
+	if v := c.FormValue("dryRun"); v != "" {
+		if req.DryRun, err = strconv.ParseBool(v); err != nil {
+			return c.JSON(http.StatusBadRequest, "Invalid dryRun format")
+		}
+	}
+	if v := c.FormValue("allowDuplicates"); v != "" {
+		if req.AllowDuplicates, err = strconv.ParseBool(v); err != nil {
+			return c.JSON(http.StatusBadRequest, "Invalid allowDuplicates format")
+		}
+	}
+
+	importRes, err := mc.mu.ImportMoneyManagements(req, uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	// エラーのある行があった場合は何も登録していないため、行ごとの結果と合わせて400を返す
+	if importRes.ErrorCount > 0 && !importRes.DryRun {
+		return c.JSON(http.StatusBadRequest, importRes)
+	}
+	if !importRes.Committed {
+		return c.JSON(http.StatusOK, importRes)
+	}
+	return c.JSON(http.StatusCreated, importRes)
+}
+
+// ExportMoneyManagements は期間内の家計簿をCSV・JSONでダウンロードさせる
+func (mc *moneyManagementController) ExportMoneyManagements(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.MoneyManagementExportRequest{
+		From:     c.QueryParam("from"),
+		To:       c.QueryParam("to"),
+		Format:   c.QueryParam("format"),
+		Encoding: c.QueryParam("encoding"),
+	}
+	if req.Format == "" {
+		req.Format = model.ExportFormatCSV
+	}
+
+	w := newExportWriter(c, req.Format, req.Encoding, fmt.Sprintf("money_management_%s_%s", req.From, req.To))
+	if err := mc.mu.ExportMoneyManagements(w, req, uint(userId.(float64))); err != nil {
+		return exportError(c, err)
+	}
+	return nil
+}
+
+// exportWriter は最初の書き込みでダウンロード用のヘッダーを送る
+// 書き込み前に発生したエラー（検証エラーなど）は通常どおりJSONで返せる
+type exportWriter struct {
+	c           echo.Context
+	contentType string
+	filename    string
+}
+
+func newExportWriter(c echo.Context, format string, encoding string, name string) *exportWriter {
+	if format == model.ExportFormatJSON {
+		return &exportWriter{c, echo.MIMEApplicationJSONCharsetUTF8, name + ".json"}
+	}
+	charset := spreadsheet.EncodingUTF8
+	if enc, _ := spreadsheet.NormalizeEncoding(encoding); enc == spreadsheet.EncodingShiftJIS {
+		charset = spreadsheet.EncodingShiftJIS
+	}
+	return &exportWriter{c, "text/csv; charset=" + charset, name + ".csv"}
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	res := w.c.Response()
+	if !res.Committed {
+		res.Header().Set(echo.HeaderContentType, w.contentType)
+		res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", w.filename))
+		res.WriteHeader(http.StatusOK)
+	}
+	return res.Write(p)
+}
+
+// exportError は書き出しのエラーを返す。既に書き出しを始めている場合はステータスを変えられないため記録のみ行う
+func exportError(c echo.Context, err error) error {
+	if c.Response().Committed {
+		log.Printf("export interrupted: %v", err)
+		return nil
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
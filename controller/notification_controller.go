@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type INotificationController interface {
+	GetSetting(c echo.Context) error
+	UpdateSetting(c echo.Context) error
+	GetNotifications(c echo.Context) error
+	MarkAsRead(c echo.Context) error
+}
+
+type notificationController struct {
+	nu usecase.INotificationUsecase
+}
+
+func NewNotificationController(nu usecase.INotificationUsecase) INotificationController {
+	return &notificationController{nu}
+}
+
+func (nc *notificationController) GetSetting(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	settingRes, err := nc.nu.GetSetting(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, settingRes)
+}
+
+func (nc *notificationController) UpdateSetting(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.NotificationSettingRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	settingRes, err := nc.nu.UpdateSetting(req, uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, settingRes)
+}
+
+func (nc *notificationController) GetNotifications(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	notificationsRes, totalPageCount, err := nc.nu.GetNotifications(uint(userId.(float64)), page, pageSize)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"totalPageCount": totalPageCount,
+		"notifications":  notificationsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (nc *notificationController) MarkAsRead(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := nc.nu.MarkAsRead(uint(userId.(float64)), uint(id)); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
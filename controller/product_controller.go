@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"merchandise-review-list-backend/model"
 	"merchandise-review-list-backend/usecase"
 	"net/http"
@@ -13,8 +14,17 @@ import (
 
 type IProductController interface {
 	CreateProduct(c echo.Context) error
+	UpdateProduct(c echo.Context) error
 	UpdateTimeLimit(c echo.Context) error
+	RecordPriceSnapshot(c echo.Context) error
+	GetPriceTimeline(c echo.Context) error
+	UpdateTargetPrice(c echo.Context) error
+	RefreshProduct(c echo.Context) error
+	LookupProduct(c echo.Context) error
+	PurchaseProduct(c echo.Context) error
 	DeleteProduct(c echo.Context) error
+	DeleteProducts(c echo.Context) error
+	GetProductHistories(c echo.Context) error
 	GetMyProducts(c echo.Context) error
 	GetMyProductsTimeLimitAll(c echo.Context) error
 	GetMyProductsTimeLimitYearMonth(c echo.Context) error
@@ -46,7 +56,7 @@ func (pc *productController) CreateProduct(c echo.Context) error {
 	return c.JSON(http.StatusCreated, productRes)
 }
 
-func (pc *productController) UpdateTimeLimit(c echo.Context) error {
+func (pc *productController) UpdateProduct(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
 	userId := claims["user_id"]
@@ -58,13 +68,135 @@ func (pc *productController) UpdateTimeLimit(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	productRes, err := pc.pu.UpdateTimeLimit(product, uint(userId.(float64)), uint(productId))
+	productRes, err := pc.pu.UpdateProduct(product, uint(userId.(float64)), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, productRes)
+}
+
+func (pc *productController) UpdateTimeLimit(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	req := model.ProductTimeLimitRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	productRes, err := pc.pu.UpdateTimeLimit(req, uint(userId.(float64)), uint(productId))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 	return c.JSON(http.StatusCreated, productRes)
 }
 
+func (pc *productController) RecordPriceSnapshot(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	req := model.ProductPriceSnapshotRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	snapshotRes, err := pc.pu.RecordPriceSnapshot(req, uint(userId.(float64)), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, snapshotRes)
+}
+
+func (pc *productController) GetPriceTimeline(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+	// 期間（"2006-01-02"形式、省略可）
+	from := c.QueryParam("from")
+	to := c.QueryParam("to")
+
+	timelineRes, err := pc.pu.GetPriceTimeline(uint(userId.(float64)), uint(productId), from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, timelineRes)
+}
+
+func (pc *productController) UpdateTargetPrice(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	req := model.ProductTargetPriceRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	productRes, err := pc.pu.UpdateTargetPrice(req, uint(userId.(float64)), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, productRes)
+}
+
+func (pc *productController) RefreshProduct(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	productRes, err := pc.pu.RefreshProduct(uint(userId.(float64)), uint(productId))
+	if errors.Is(err, usecase.ErrProductNotLinked) {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, productRes)
+}
+
+func (pc *productController) LookupProduct(c echo.Context) error {
+	provider := c.QueryParam("provider")
+	code := c.QueryParam("code")
+	url := c.QueryParam("url")
+
+	itemRes, err := pc.pu.LookupProduct(provider, code, url)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, itemRes)
+}
+
+func (pc *productController) PurchaseProduct(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	req := model.ProductPurchaseRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	purchaseRes, err := pc.pu.PurchaseProduct(req, uint(userId.(float64)), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, purchaseRes)
+}
+
 func (pc *productController) DeleteProduct(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
@@ -79,6 +211,47 @@ func (pc *productController) DeleteProduct(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (pc *productController) DeleteProducts(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.ProductBulkDeleteRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	deletedCount, err := pc.pu.DeleteProducts(uint(userId.(float64)), req.ProductIds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"deletedCount": deletedCount,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (pc *productController) GetProductHistories(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("productId")
+	productId, _ := strconv.Atoi(id)
+
+	historiesRes, err := pc.pu.GetProductHistories(uint(userId.(float64)), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"histories": historiesRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 func (pc *productController) GetMyProducts(c echo.Context) error {
 	user := c.Get("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
@@ -86,7 +259,13 @@ func (pc *productController) GetMyProducts(c echo.Context) error {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
 
-	productsRes, totalPageCount, err := pc.pu.GetMyProducts(uint(userId.(float64)), page, pageSize)
+	// 期限による絞り込み（with, without, overdue, dueWithin）。dueWithinの場合はdaysも指定する
+	deadline := c.QueryParam("deadline")
+	days, _ := strconv.Atoi(c.QueryParam("days"))
+	// trueの場合はアーカイブした商品のみ
+	archived := c.QueryParam("archived") == "true"
+
+	productsRes, totalPageCount, err := pc.pu.GetMyProducts(uint(userId.(float64)), page, pageSize, deadline, days, archived)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
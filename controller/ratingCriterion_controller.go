@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IRatingCriterionController interface {
+	GetCriteriaByCategory(c echo.Context) error
+}
+
+type ratingCriterionController struct {
+	cu usecase.IRatingCriterionUsecase
+}
+
+func NewRatingCriterionController(cu usecase.IRatingCriterionUsecase) IRatingCriterionController {
+	return &ratingCriterionController{cu}
+}
+
+func (rcc *ratingCriterionController) GetCriteriaByCategory(c echo.Context) error {
+	category := c.QueryParam("category")
+
+	criteriaRes, err := rcc.cu.GetCriteriaByCategory(category)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"criteria": criteriaRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"io"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/usecase"
+	"merchandise-review-list-backend/validator"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IReviewPostImageController interface {
+	UploadImages(c echo.Context) error
+	DeleteImage(c echo.Context) error
+	ReorderImages(c echo.Context) error
+}
+
+type reviewPostImageController struct {
+	iu usecase.IReviewPostImageUsecase
+}
+
+func NewReviewPostImageController(iu usecase.IReviewPostImageUsecase) IReviewPostImageController {
+	return &reviewPostImageController{iu}
+}
+
+func (ic *reviewPostImageController) UploadImages(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id := c.Param("postId")
+	postId, _ := strconv.Atoi(id)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	fileHeaders := form.File["images"]
+	if len(fileHeaders) == 0 {
+		return c.JSON(http.StatusBadRequest, "images is required")
+	}
+
+	files := [][]byte{}
+	for _, fh := range fileHeaders {
+		f, err := fh.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		// 上限を超えたことを検知できるよう1バイト多く読む
+		data, err := io.ReadAll(io.LimitReader(f, validator.MaxReviewPostImageSize+1))
+		f.Close()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		files = append(files, data)
+	}
+
+	imagesRes, err := ic.iu.UploadImages(uint(userId.(float64)), uint(postId), files)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, imagesRes)
+}
+
+func (ic *reviewPostImageController) DeleteImage(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	postId, _ := strconv.Atoi(c.Param("postId"))
+	imageId, _ := strconv.Atoi(c.Param("imageId"))
+
+	err := ic.iu.DeleteImage(uint(userId.(float64)), uint(postId), uint(imageId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ic *reviewPostImageController) ReorderImages(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	postId, _ := strconv.Atoi(c.Param("postId"))
+
+	order := model.ReviewPostImageOrderRequest{}
+	if err := c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	imagesRes, err := ic.iu.ReorderImages(uint(userId.(float64)), uint(postId), order.ImageIds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, imagesRes)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IReviewPostScoreController interface {
+	GetRanking(c echo.Context) error
+}
+
+type reviewPostScoreController struct {
+	rsu usecase.IReviewPostScoreUsecase
+}
+
+func NewReviewPostScoreController(rsu usecase.IReviewPostScoreUsecase) IReviewPostScoreController {
+	return &reviewPostScoreController{rsu}
+}
+
+func (rsc *reviewPostScoreController) GetRanking(c echo.Context) error {
+	period := c.QueryParam("period")
+	category := c.QueryParam("category")
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+	userId, _ := strconv.Atoi(c.QueryParam("userId"))
+
+	reviewPostsRes, totalPageCount, err := rsc.rsu.GetRanking(period, category, page, pageSize, uint(userId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"totalPageCount": totalPageCount,
+		"reviewPosts":    reviewPostsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
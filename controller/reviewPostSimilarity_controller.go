@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IReviewPostSimilarityController interface {
+	GetSimilarReviewPosts(c echo.Context) error
+}
+
+type reviewPostSimilarityController struct {
+	rsu usecase.IReviewPostSimilarityUsecase
+}
+
+func NewReviewPostSimilarityController(rsu usecase.IReviewPostSimilarityUsecase) IReviewPostSimilarityController {
+	return &reviewPostSimilarityController{rsu}
+}
+
+func (rsc *reviewPostSimilarityController) GetSimilarReviewPosts(c echo.Context) error {
+	postId, _ := strconv.Atoi(c.Param("postId"))
+	userId, _ := strconv.Atoi(c.QueryParam("userId"))
+
+	reviewPostsRes, err := rsc.rsu.GetSimilarReviewPosts(uint(postId), uint(userId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"reviewPosts": reviewPostsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
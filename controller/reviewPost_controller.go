@@ -18,6 +18,8 @@ type IReviewPostController interface {
 	GetReviewPostById(c echo.Context) error
 	GetReviewPostLists(c echo.Context) error
 	GetMyLikes(c echo.Context) error
+	GetProductReviews(c echo.Context) error
+	GetReviewPostRevisions(c echo.Context) error
 }
 
 type reviewPostController struct {
@@ -144,3 +146,40 @@ func (rc *reviewPostController) GetMyLikes(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+func (rc *reviewPostController) GetProductReviews(c echo.Context) error {
+	provider := c.Param("provider")
+	code := c.Param("code")
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+	userId, _ := strconv.Atoi(c.QueryParam("userId"))
+
+	reviewPostsRes, totalPageCount, aggregateRes, err := rc.ru.GetProductReviews(provider, code, page, pageSize, uint(userId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"totalPageCount": totalPageCount,
+		"reviewPosts":    reviewPostsRes,
+		"aggregate":      aggregateRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (rc *reviewPostController) GetReviewPostRevisions(c echo.Context) error {
+	id := c.Param("postId")
+	postId, _ := strconv.Atoi(id)
+
+	revisionsRes, err := rc.ru.GetReviewPostRevisions(uint(postId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"revisions": revisionsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
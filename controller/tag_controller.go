@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ITagController interface {
+	GetReviewPostsByTag(c echo.Context) error
+	AutocompleteTags(c echo.Context) error
+	GetTrendingTags(c echo.Context) error
+}
+
+type tagController struct {
+	tu usecase.ITagUsecase
+}
+
+func NewTagController(tu usecase.ITagUsecase) ITagController {
+	return &tagController{tu}
+}
+
+func (tc *tagController) GetReviewPostsByTag(c echo.Context) error {
+	tag := c.Param("tag")
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+	userId, _ := strconv.Atoi(c.QueryParam("userId"))
+
+	reviewPostsRes, totalPageCount, err := tc.tu.GetReviewPostsByTag(tag, page, pageSize, uint(userId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"totalPageCount": totalPageCount,
+		"reviewPosts":    reviewPostsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (tc *tagController) AutocompleteTags(c echo.Context) error {
+	q := c.QueryParam("q")
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	tagsRes, err := tc.tu.AutocompleteTags(q, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"tags": tagsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (tc *tagController) GetTrendingTags(c echo.Context) error {
+	hours, _ := strconv.Atoi(c.QueryParam("hours"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	tagsRes, err := tc.tu.GetTrendingTags(hours, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	response := map[string]interface{}{
+		"tags": tagsRes,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IWishlistController interface {
+	CreateWishlist(c echo.Context) error
+	UpdateWishlist(c echo.Context) error
+	DeleteWishlist(c echo.Context) error
+	GetMyWishlists(c echo.Context) error
+	GetWishlistById(c echo.Context) error
+	InviteMember(c echo.Context) error
+	UpdateMemberRole(c echo.Context) error
+	RemoveMember(c echo.Context) error
+	AcceptInvite(c echo.Context) error
+	AddItem(c echo.Context) error
+	RemoveItem(c echo.Context) error
+	MarkBought(c echo.Context) error
+	UnmarkBought(c echo.Context) error
+}
+
+type wishlistController struct {
+	wu usecase.IWishlistUsecase
+}
+
+func NewWishlistController(wu usecase.IWishlistUsecase) IWishlistController {
+	return &wishlistController{wu}
+}
+
+func (wc *wishlistController) CreateWishlist(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	wishlist := model.Wishlist{}
+	if err := c.Bind(&wishlist); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	wishlist.UserId = uint(userId.(float64))
+
+	wishlistRes, err := wc.wu.CreateWishlist(wishlist)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) UpdateWishlist(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	wishlist := model.Wishlist{}
+	if err := c.Bind(&wishlist); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	wishlistRes, err := wc.wu.UpdateWishlist(wishlist, uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) DeleteWishlist(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	err := wc.wu.DeleteWishlist(uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *wishlistController) GetMyWishlists(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	wishlistsRes, err := wc.wu.GetMyWishlists(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, wishlistsRes)
+}
+
+func (wc *wishlistController) GetWishlistById(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	wishlistRes, err := wc.wu.GetWishlistById(uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, wishlistRes)
+}
+
+func (wc *wishlistController) InviteMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	req := model.WishlistInviteRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	wishlistRes, err := wc.wu.InviteMember(req, uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) UpdateMemberRole(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	memberId, _ := strconv.Atoi(c.Param("memberId"))
+
+	req := model.WishlistRoleRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	wishlistRes, err := wc.wu.UpdateMemberRole(uint(userId.(float64)), uint(id), uint(memberId), req.Role)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) RemoveMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	memberId, _ := strconv.Atoi(c.Param("memberId"))
+
+	err := wc.wu.RemoveMember(uint(userId.(float64)), uint(id), uint(memberId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *wishlistController) AcceptInvite(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	wishlistRes, err := wc.wu.AcceptInvite(uint(userId.(float64)), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) AddItem(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	item := model.WishlistItemRequest{}
+	if err := c.Bind(&item); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	wishlistRes, err := wc.wu.AddItem(uint(userId.(float64)), uint(id), item.ProductId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) RemoveItem(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	productId, _ := strconv.Atoi(c.Param("productId"))
+
+	err := wc.wu.RemoveItem(uint(userId.(float64)), uint(id), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *wishlistController) MarkBought(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	productId, _ := strconv.Atoi(c.Param("productId"))
+
+	wishlistRes, err := wc.wu.MarkBought(uint(userId.(float64)), uint(id), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, wishlistRes)
+}
+
+func (wc *wishlistController) UnmarkBought(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	id, _ := strconv.Atoi(c.Param("id"))
+	productId, _ := strconv.Atoi(c.Param("productId"))
+
+	wishlistRes, err := wc.wu.UnmarkBought(uint(userId.(float64)), uint(id), uint(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, wishlistRes)
+}
@@ -0,0 +1,120 @@
+// Package ical はiCalendar（RFC 5545）形式のカレンダーを出力する
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Calendar struct {
+	ProdId   string
+	Name     string
+	TimeZone string // X-WR-TIMEZONE（表示用）
+	Events   []Event
+}
+
+// Event は時刻の決まった予定。Endを省略した場合は開始時刻のみの予定とする
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+	// Alarms は開始時刻の何分前に通知するか
+	Alarms []int
+}
+
+// Write はカレンダーをCRLF区切り・75オクテットで折り返して出力する
+func (c *Calendar) Write(w io.Writer) error {
+	lw := &lineWriter{w: w}
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + c.ProdId)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	if c.TimeZone != "" {
+		lw.line("X-WR-TIMEZONE:" + c.TimeZone)
+	}
+
+	for _, e := range c.Events {
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.UID)
+		lw.line("DTSTAMP:" + formatTime(e.Stamp))
+		lw.line("DTSTART:" + formatTime(e.Start))
+		if !e.End.IsZero() {
+			lw.line("DTEND:" + formatTime(e.End))
+		}
+		lw.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.URL != "" {
+			lw.line("URL:" + e.URL)
+		}
+		for _, minutes := range e.Alarms {
+			lw.line("BEGIN:VALARM")
+			lw.line("ACTION:DISPLAY")
+			lw.line("DESCRIPTION:" + escape(e.Summary))
+			lw.line(fmt.Sprintf("TRIGGER:-PT%dM", minutes))
+			lw.line("END:VALARM")
+		}
+		lw.line("END:VEVENT")
+	}
+
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape はTEXT型の値の特殊文字をエスケープする
+func escape(s string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+		"\r", "\\n",
+	).Replace(s)
+}
+
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+// line は1行を出力する。75オクテットを超える場合はUTF-8の文字の途中で切らないように折り返す
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// 続きの行は先頭の空白を含めて75オクテット
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
@@ -0,0 +1,176 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "商品の期限", "商品の期限"},
+		{"backslash", `C:\path`, `C:\\path`},
+		{"semicolon and comma", "a;b,c", `a\;b\,c`},
+		{"CRLF", "1行目\r\n2行目", `1行目\n2行目`},
+		{"LF", "1行目\n2行目", `1行目\n2行目`},
+		{"CR", "1行目\r2行目", `1行目\n2行目`},
+		{"backslash before escaped characters", `\;`, `\\\;`},
+		{"colon is not escaped", "価格: 1,000円", `価格: 1\,000円`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escape(tt.in); got != tt.want {
+				t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantLines int
+	}{
+		{"short", "SUMMARY:abc", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"continuation holds 74 octets", "SUMMARY:" + strings.Repeat("a", 67+74), 2},
+		{"one octet more than two lines", "SUMMARY:" + strings.Repeat("a", 67+74+1), 3},
+		// 3オクテットの文字は75オクテット目をまたぐ
+		{"three-byte characters", "SUMMARY:" + strings.Repeat("期", 40), 2},
+		{"four-byte characters", "SUMMARY:" + strings.Repeat("😀", 40), 3},
+		{"mixed", "DESCRIPTION:" + strings.Repeat("a期😀", 30), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			lw := &lineWriter{w: &b}
+			lw.line(tt.in)
+			if lw.err != nil {
+				t.Fatal(lw.err)
+			}
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output does not end with CRLF: %q", out)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.wantLines {
+				t.Errorf("lines = %d, want %d", len(lines), tt.wantLines)
+			}
+			for i, l := range lines {
+				if len(l) > 75 {
+					t.Errorf("line %d has %d octets", i, len(l))
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a character: %q", i, l)
+				}
+			}
+			// 折り返しを戻すと元の値になる
+			if got := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); got != tt.in {
+				t.Errorf("unfolded = %q, want %q", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestCalendarWrite(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	c := Calendar{
+		ProdId:   "-//test//JA",
+		Name:     "商品の期限",
+		TimeZone: "Asia/Tokyo",
+		Events: []Event{
+			{
+				UID:         "product-1@example.com",
+				Summary:     "期限: りんご, みかん",
+				Description: "1行目\n2行目",
+				URL:         "https://example.com/products/1",
+				Start:       time.Date(2024, 3, 10, 9, 0, 0, 0, tokyo),
+				End:         time.Date(2024, 3, 10, 10, 0, 0, 0, tokyo),
+				Stamp:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				Alarms:      []int{60, 1440},
+			},
+			{
+				UID:     "product-2@example.com",
+				Summary: "本",
+				Start:   time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC),
+				Stamp:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	var b strings.Builder
+	if err := c.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//test//JA",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:商品の期限",
+		"X-WR-TIMEZONE:Asia/Tokyo",
+		"BEGIN:VEVENT",
+		"UID:product-1@example.com",
+		"DTSTAMP:20240301T000000Z",
+		"DTSTART:20240310T000000Z",
+		"DTEND:20240310T010000Z",
+		`SUMMARY:期限: りんご\, みかん`,
+		`DESCRIPTION:1行目\n2行目`,
+		"URL:https://example.com/products/1",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		`DESCRIPTION:期限: りんご\, みかん`,
+		"TRIGGER:-PT60M",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		`DESCRIPTION:期限: りんご\, みかん`,
+		"TRIGGER:-PT1440M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:product-2@example.com",
+		"DTSTAMP:20240301T000000Z",
+		"DTSTART:20241231T233000Z",
+		"SUMMARY:本",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if got := b.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	fw.writes++
+	return 0, errors.New("write failed")
+}
+
+func TestCalendarWriteStopsOnError(t *testing.T) {
+	fw := &failingWriter{}
+	c := Calendar{ProdId: "-//test//JA", Events: []Event{{UID: "1", Summary: "a"}}}
+	if err := c.Write(fw); err == nil {
+		t.Fatal("Write() error = nil")
+	}
+	if fw.writes != 1 {
+		t.Errorf("writes = %d, want 1", fw.writes)
+	}
+}
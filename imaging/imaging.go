@@ -0,0 +1,167 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// Thumbnail はサムネイルのサイズ定義（長辺のピクセル数）
+type Thumbnail struct {
+	Name    string
+	MaxSide int
+}
+
+// MaxPixels はデコードを許可する画像の最大画素数（幅×高さ）。
+// ヘッダーで巨大なサイズを申告する小さなファイル（解凍爆弾）でメモリを使い切らないようにする。
+const MaxPixels = 40_000_000
+
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// ThumbnailSizes は生成するサムネイルの一覧
+var ThumbnailSizes = []Thumbnail{
+	{Name: "small", MaxSide: 160},
+	{Name: "medium", MaxSide: 480},
+	{Name: "large", MaxSide: 1080},
+}
+
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+type Processed struct {
+	Original   Encoded
+	Thumbnails map[string]Encoded
+}
+
+// Process は画像をデコードして再エンコードする。
+// 再エンコードによりEXIFなどのメタデータは全て取り除かれる（向きの情報のみ画素に反映する）。
+func Process(data []byte, contentType string) (Processed, error) {
+	if err := CheckDimensions(data); err != nil {
+		return Processed{}, err
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, err
+	}
+
+	if contentType == "image/jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	original, err := encode(src, contentType)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	res := Processed{
+		Original:   original,
+		Thumbnails: map[string]Encoded{},
+	}
+	for _, t := range ThumbnailSizes {
+		thumb, err := encode(resize(src, t.MaxSide), "image/jpeg")
+		if err != nil {
+			return Processed{}, err
+		}
+		res.Thumbnails[t.Name] = thumb
+	}
+	return res, nil
+}
+
+// CheckDimensions は画素データをデコードせずにヘッダーのサイズだけを読み、MaxPixelsを超える画像を拒否する
+func CheckDimensions(data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return errors.New("invalid image dimensions")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+func encode(img image.Image, contentType string) (Encoded, error) {
+	buf := new(bytes.Buffer)
+	b := img.Bounds()
+	switch contentType {
+	case "image/png":
+		if err := png.Encode(buf, img); err != nil {
+			return Encoded{}, err
+		}
+		return Encoded{buf.Bytes(), "image/png", ".png", b.Dx(), b.Dy()}, nil
+	case "image/jpeg":
+		// JPEGは透過を扱えないので白背景に合成する
+		dst := image.NewRGBA(b)
+		draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(dst, b, img, b.Min, draw.Over)
+		if err := jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return Encoded{}, err
+		}
+		return Encoded{buf.Bytes(), "image/jpeg", ".jpg", b.Dx(), b.Dy()}, nil
+	}
+	return Encoded{}, errors.New("unsupported content type")
+}
+
+// resize は長辺がmaxSide以下になるよう面積平均で縮小する（拡大はしない）
+func resize(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := b.Min.Y + y*h/dh
+		sy1 := b.Min.Y + (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			sx0 := b.Min.X + x*w/dw
+			sx1 := b.Min.X + (x+1)*w/dw
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
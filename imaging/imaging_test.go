@@ -0,0 +1,95 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withDeclaredSize はPNGのIHDRチャンクの幅と高さだけを書き換える（画素データはそのまま）
+func withDeclaredSize(data []byte, w, h uint32) []byte {
+	out := append([]byte(nil), data...)
+	// シグネチャ(8) + 長さ(4) + "IHDR"(4) の後に幅と高さが続く
+	binary.BigEndian.PutUint32(out[16:20], w)
+	binary.BigEndian.PutUint32(out[20:24], h)
+	binary.BigEndian.PutUint32(out[29:33], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func TestCheckDimensions(t *testing.T) {
+	small := encodePNG(t, 4, 4)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"small image", small, nil},
+		{"exactly max pixels", withDeclaredSize(small, 8000, 5000), nil},
+		{"declares huge size", withDeclaredSize(small, 100000, 100000), ErrTooLarge},
+		{"just over max pixels", withDeclaredSize(small, 8000, 5001), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckDimensions(tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckDimensions() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := CheckDimensions([]byte("not an image")); err == nil {
+		t.Error("CheckDimensions() accepted invalid data")
+	}
+}
+
+func TestProcessRejectsDecompressionBomb(t *testing.T) {
+	bomb := withDeclaredSize(encodePNG(t, 4, 4), 60000, 60000)
+	if _, err := Process(bomb, "image/png"); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Process() = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	res, err := Process(encodePNG(t, 600, 300), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Original.ContentType != "image/png" || res.Original.Width != 600 || res.Original.Height != 300 {
+		t.Errorf("original = %s %dx%d", res.Original.ContentType, res.Original.Width, res.Original.Height)
+	}
+
+	want := map[string][2]int{
+		"small":  {160, 80},
+		"medium": {480, 240},
+		"large":  {600, 300}, // 拡大はしない
+	}
+	for name, size := range want {
+		thumb, ok := res.Thumbnails[name]
+		if !ok {
+			t.Fatalf("thumbnail %s is missing", name)
+		}
+		if thumb.ContentType != "image/jpeg" || thumb.Width != size[0] || thumb.Height != size[1] {
+			t.Errorf("thumbnail %s = %s %dx%d, want image/jpeg %dx%d", name, thumb.ContentType, thumb.Width, thumb.Height, size[0], size[1])
+		}
+	}
+}
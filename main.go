@@ -3,48 +3,120 @@ package main
 import (
 	"merchandise-review-list-backend/controller"
 	"merchandise-review-list-backend/db"
+	"merchandise-review-list-backend/marketplace"
+	"merchandise-review-list-backend/moderation"
+	"merchandise-review-list-backend/notifier"
+	"merchandise-review-list-backend/ratelimit"
 	"merchandise-review-list-backend/repository"
 	"merchandise-review-list-backend/router"
+	"merchandise-review-list-backend/scheduler"
+	"merchandise-review-list-backend/storage"
 	"merchandise-review-list-backend/usecase"
 	"merchandise-review-list-backend/validator"
+	"time"
+	// Alpineのイメージにはタイムゾーンのデータが無いため、バイナリに埋め込む
+	_ "time/tzdata"
 )
 
 func main() {
 	db := db.NewDB()
+	moderationRepository := repository.NewModerationRepository(db)
+	moderator := moderation.NewModerator(moderationRepository)
+
 	userValidator := validator.NewUserValidator()
 	userRepository := repository.NewUserRepository(db)
-	userUsecase := usecase.NweUserUsecase(userRepository, userValidator)
+	userUsecase := usecase.NweUserUsecase(userRepository, userValidator, moderator)
 	userController := controller.NewUserController(userUsecase)
 
-	productValidator := validator.NewProductValidator()
-	productRepository := repository.NewProductRepository(db)
-	productUsecase := usecase.NweProductUsecase(productRepository, productValidator)
-	productController := controller.NewProductController(productUsecase)
-
 	likeRepositor := repository.NewLikeRepository(db)
 	likeUsecase := usecase.NewLikeUsecase(likeRepositor)
 	likeController := controller.NewLikeController(likeUsecase)
 
+	categoryRepository := repository.NewCategoryRepository(db)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository)
+	categoryController := controller.NewCategoryController(categoryUsecase)
+
 	reviewPostValidator := validator.NewReviewPostValidator()
 	reviewPostRepository := repository.NewPostRepository(db)
-	reviewPostUsecase := usecase.NewReviewPostUsecase(reviewPostRepository, reviewPostValidator, likeRepositor)
+	ratingCriterionRepository := repository.NewRatingCriterionRepository(db)
+	ratingCriterionUsecase := usecase.NewRatingCriterionUsecase(ratingCriterionRepository)
+	ratingCriterionController := controller.NewRatingCriterionController(ratingCriterionUsecase)
+
+	blobStore := storage.NewBlobStore()
+	reviewPostUsecase := usecase.NewReviewPostUsecase(reviewPostRepository, reviewPostValidator, likeRepositor, ratingCriterionRepository, categoryRepository, moderationRepository, moderator, blobStore)
 	reviewPostController := controller.NewReviewPostController(reviewPostUsecase)
 
+	notificationRepository := repository.NewNotificationRepository(db)
+	notificationValidator := validator.NewNotificationValidator()
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, notificationValidator, notifier.NewNotifiers(notificationRepository))
+	notificationController := controller.NewNotificationController(notificationUsecase)
+
+	moneyManagementValidator := validator.NewMoneyManagementValidator()
+	productValidator := validator.NewProductValidator()
+	productRepository := repository.NewProductRepository(db)
+	productPriceSnapshotRepository := repository.NewProductPriceSnapshotRepository(db)
+	productUsecase := usecase.NweProductUsecase(productRepository, productValidator, reviewPostRepository, userRepository, productPriceSnapshotRepository, notificationUsecase, marketplace.NewRegistry(), moneyManagementValidator, categoryRepository)
+	productController := controller.NewProductController(productUsecase)
+
 	commentValidator := validator.NewCommentValidator()
 	commentRepository := repository.NewCommentRepository(db)
-	commentUsecase := usecase.NewCommentUsecase(commentRepository, commentValidator, reviewPostRepository)
+	commentUsecase := usecase.NewCommentUsecase(commentRepository, commentValidator, reviewPostRepository, moderationRepository, moderator)
 	commentController := controller.NewCommentController(commentUsecase)
 
 	moneyManagementRepository := repository.NewMoneyManagementRepository(db)
-	moneyManagementValidator := validator.NewMoneyManagementValidator()
-	moneyManagementUsecase := usecase.NewMoneyManagementUsecase(moneyManagementRepository, moneyManagementValidator)
+	moneyManagementUsecase := usecase.NewMoneyManagementUsecase(moneyManagementRepository, moneyManagementValidator, categoryRepository, userRepository)
 	moneyManagementController := controller.NewMoneyManagementController(moneyManagementUsecase)
 
 	budgetRepository := repository.NewBudgetRepository(db)
 	budgetValidator := validator.NewBudgetValidator()
-	budgetUsecase := usecase.NweBudgetUsecase(budgetRepository, budgetValidator)
+	budgetUsecase := usecase.NweBudgetUsecase(budgetRepository, budgetValidator, categoryRepository, userRepository, moneyManagementRepository)
 	budgetController := controller.NewBudgetController(budgetUsecase)
 
-	e := router.NewRouter(userController, productController, reviewPostController, likeController, commentController, moneyManagementController, budgetController)
+	reviewPostImageValidator := validator.NewReviewPostImageValidator()
+	reviewPostImageRepository := repository.NewReviewPostImageRepository(db)
+	reviewPostImageUsecase := usecase.NewReviewPostImageUsecase(reviewPostImageRepository, reviewPostImageValidator, reviewPostRepository, blobStore)
+	reviewPostImageController := controller.NewReviewPostImageController(reviewPostImageUsecase)
+
+	collectionValidator := validator.NewCollectionValidator()
+	collectionRepository := repository.NewCollectionRepository(db)
+	collectionUsecase := usecase.NewCollectionUsecase(collectionRepository, collectionValidator, reviewPostRepository, reviewPostUsecase)
+	collectionController := controller.NewCollectionController(collectionUsecase)
+
+	reviewPostScoreRepository := repository.NewReviewPostScoreRepository(db)
+	reviewPostScoreUsecase := usecase.NewReviewPostScoreUsecase(reviewPostScoreRepository, reviewPostUsecase)
+	reviewPostScoreController := controller.NewReviewPostScoreController(reviewPostScoreUsecase)
+
+	reviewPostSimilarityRepository := repository.NewReviewPostSimilarityRepository(db)
+	reviewPostSimilarityUsecase := usecase.NewReviewPostSimilarityUsecase(reviewPostSimilarityRepository, reviewPostRepository, reviewPostUsecase)
+	reviewPostSimilarityController := controller.NewReviewPostSimilarityController(reviewPostSimilarityUsecase)
+
+	tagRepository := repository.NewTagRepository(db)
+	tagUsecase := usecase.NewTagUsecase(tagRepository, reviewPostUsecase)
+	tagController := controller.NewTagController(tagUsecase)
+
+	moderationUsecase := usecase.NewModerationUsecase(moderationRepository, userRepository, reviewPostRepository, commentRepository)
+	moderationController := controller.NewModerationController(moderationUsecase)
+
+	calendarFeedRepository := repository.NewCalendarFeedRepository(db)
+	calendarFeedUsecase := usecase.NewCalendarFeedUsecase(calendarFeedRepository, userRepository, notificationRepository)
+	calendarFeedController := controller.NewCalendarFeedController(calendarFeedUsecase)
+
+	wishlistValidator := validator.NewWishlistValidator()
+	wishlistRepository := repository.NewWishlistRepository(db)
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepository, wishlistValidator, userRepository, productUsecase, notificationUsecase)
+	wishlistController := controller.NewWishlistController(wishlistUsecase)
+
+	rateLimitStore := ratelimit.NewStore(db)
+
+	// 予約投稿の公開
+	scheduler.Every(time.Minute, "publishScheduledReviewPosts", reviewPostUsecase.PublishScheduledReviewPosts)
+	// ランキングのスコア計算
+	scheduler.Every(10*time.Minute, "computeReviewPostScores", reviewPostScoreUsecase.ComputeScores)
+	// 商品の期限の通知
+	scheduler.Every(time.Minute, "sendProductReminders", notificationUsecase.SendDueReminders)
+	// 使われなくなったリクエスト数の制限のバケットの削除
+	scheduler.Every(10*time.Minute, "cleanupRateLimitBuckets", func() error { return rateLimitStore.Cleanup(time.Now()) })
+
+	e := router.NewRouter(userController, productController, reviewPostController, likeController, commentController, moneyManagementController, budgetController, reviewPostImageController, ratingCriterionController, collectionController, tagController, categoryController, reviewPostScoreController, reviewPostSimilarityController, moderationController, notificationController, calendarFeedController, wishlistController, rateLimitStore)
 	e.Logger.Fatal(e.Start(":8080"))
 }
@@ -0,0 +1,64 @@
+package marketplace
+
+import (
+	"strings"
+	"sync"
+)
+
+// FakeProvider はメモリ上の商品を返すProductProvider（テストやローカルでの動作確認用）
+type FakeProvider struct {
+	name  string
+	mu    sync.RWMutex
+	items map[string]Item
+}
+
+func NewFakeProvider(name string, items ...Item) *FakeProvider {
+	fp := &FakeProvider{name: name, items: map[string]Item{}}
+	for _, item := range items {
+		fp.Put(item)
+	}
+	return fp
+}
+
+// Put は商品を追加・更新する
+func (fp *FakeProvider) Put(item Item) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	item.Provider = fp.name
+	fp.items[item.Code] = item
+}
+
+func (fp *FakeProvider) Name() string {
+	return fp.name
+}
+
+func (fp *FakeProvider) LookupByCode(code string) (*Item, error) {
+	fp.mu.RLock()
+	defer fp.mu.RUnlock()
+	item, ok := fp.items[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &item, nil
+}
+
+// LookupByURL は登録された商品のURLと一致するものを返す
+func (fp *FakeProvider) LookupByURL(url string) (*Item, error) {
+	fp.mu.RLock()
+	defer fp.mu.RUnlock()
+	for _, item := range fp.items {
+		if item.Url != "" && strings.TrimRight(item.Url, "/") == strings.TrimRight(url, "/") {
+			found := item
+			return &found, nil
+		}
+	}
+	return nil, ErrUnsupportedURL
+}
+
+func (fp *FakeProvider) Refresh(code string) (*Offer, error) {
+	item, err := fp.LookupByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return &Offer{Price: item.Price, Stock: item.Stock, Review: item.Review}, nil
+}
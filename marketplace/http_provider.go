@@ -0,0 +1,218 @@
+package marketplace
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPConfig はJSONを返すAPIから商品情報を取得するProductProviderの設定
+type HTTPConfig struct {
+	Name string
+	// LookupURL は商品を取得するURL。{code}を商品コードに置き換える（例: https://api.example.com/items/{code}）
+	LookupURL string
+	// URLPattern は商品ページのURLから商品コードを取り出す正規表現（最初のグループを商品コードとする）
+	URLPattern string
+	APIKey     string
+	// APIKeyHeader はAPIKeyを送るヘッダー（未指定の場合はX-Api-Key）
+	APIKeyHeader string
+	// Fields はItemの項目名とレスポンスのJSON内のパス（"."区切り、配列は添字）の対応
+	// 未指定の項目は項目名と同じキーを使用する（例: price=Items.0.Item.itemPrice）
+	Fields  map[string]string
+	Timeout time.Duration
+}
+
+// HTTPConfigFromEnv は PRODUCT_PROVIDER_{名前の大文字}_* から設定を読み込む
+// LOOKUP_URL, URL_PATTERN, API_KEY, API_KEY_HEADER, FIELDS（"price=a.b,name=c"形式）, TIMEOUT（秒）
+func HTTPConfigFromEnv(name string) HTTPConfig {
+	cfg := HTTPConfig{
+		Name:         name,
+		LookupURL:    os.Getenv(envKey(name, "LOOKUP_URL")),
+		URLPattern:   os.Getenv(envKey(name, "URL_PATTERN")),
+		APIKey:       os.Getenv(envKey(name, "API_KEY")),
+		APIKeyHeader: os.Getenv(envKey(name, "API_KEY_HEADER")),
+		Fields:       map[string]string{},
+	}
+	for _, pair := range strings.Split(os.Getenv(envKey(name, "FIELDS")), ",") {
+		field, path, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok {
+			cfg.Fields[strings.TrimSpace(field)] = strings.TrimSpace(path)
+		}
+	}
+	if seconds, err := strconv.Atoi(os.Getenv(envKey(name, "TIMEOUT"))); err == nil && seconds > 0 {
+		cfg.Timeout = time.Duration(seconds) * time.Second
+	}
+	return cfg
+}
+
+type httpProvider struct {
+	cfg        HTTPConfig
+	urlPattern *regexp.Regexp
+	client     *http.Client
+}
+
+func NewHTTPProvider(cfg HTTPConfig) ProductProvider {
+	if cfg.APIKeyHeader == "" {
+		cfg.APIKeyHeader = "X-Api-Key"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	var urlPattern *regexp.Regexp
+	if cfg.URLPattern != "" {
+		// 不正な正規表現の場合はURLからの取得を無効にする
+		p, err := regexp.Compile(cfg.URLPattern)
+		if err != nil {
+			log.Printf("invalid url pattern for %s: %v", cfg.Name, err)
+		}
+		urlPattern = p
+	}
+	return &httpProvider{cfg, urlPattern, &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (hp *httpProvider) Name() string {
+	return hp.cfg.Name
+}
+
+func (hp *httpProvider) LookupByCode(code string) (*Item, error) {
+	if hp.cfg.LookupURL == "" {
+		return nil, fmt.Errorf("lookup url is not configured for %s", hp.cfg.Name)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.ReplaceAll(hp.cfg.LookupURL, "{code}", url.PathEscape(code)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if hp.cfg.APIKey != "" {
+		req.Header.Set(hp.cfg.APIKeyHeader, hp.cfg.APIKey)
+	}
+
+	res, err := hp.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d", hp.cfg.Name, res.StatusCode)
+	}
+
+	var body interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	item := &Item{
+		Provider:    hp.cfg.Name,
+		Code:        code,
+		Name:        toString(hp.field(body, "name")),
+		Description: toString(hp.field(body, "description")),
+		Url:         toString(hp.field(body, "url")),
+		Image:       toString(hp.field(body, "image")),
+		Price:       uint(toFloat(hp.field(body, "price"))),
+		Stock:       toBool(hp.field(body, "stock")),
+		Review:      toFloat(hp.field(body, "review")),
+	}
+	if item.Name == "" {
+		return nil, ErrNotFound
+	}
+	return item, nil
+}
+
+func (hp *httpProvider) LookupByURL(itemURL string) (*Item, error) {
+	if hp.urlPattern == nil {
+		return nil, ErrUnsupportedURL
+	}
+	m := hp.urlPattern.FindStringSubmatch(itemURL)
+	if len(m) < 2 || m[1] == "" {
+		return nil, ErrUnsupportedURL
+	}
+	return hp.LookupByCode(m[1])
+}
+
+func (hp *httpProvider) Refresh(code string) (*Offer, error) {
+	item, err := hp.LookupByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return &Offer{Price: item.Price, Stock: item.Stock, Review: item.Review}, nil
+}
+
+// field は設定されたパスでJSONの値を取り出す
+func (hp *httpProvider) field(body interface{}, name string) interface{} {
+	path := hp.cfg.Fields[name]
+	if path == "" {
+		path = name
+	}
+
+	value := body
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 {
+			return 0
+		}
+		return v
+	case string:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+		if err != nil || f < 0 {
+			return 0
+		}
+		return f
+	}
+	return 0
+}
+
+// toBool は在庫の有無を判定する（数値は在庫数、文字列はtrue/1/available/in_stockを在庫ありとする）
+func toBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v > 0
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "1", "available", "in_stock", "instock":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package marketplace
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestServer はパスごとに決まったレスポンスを返すAPIを起動する
+func newTestServer(t *testing.T, responses map[string]string) (*httptest.Server, *http.Request) {
+	t.Helper()
+	var last http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = *r.Clone(r.Context())
+		switch body, ok := responses[r.URL.EscapedPath()]; {
+		case r.URL.Path == "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &last
+}
+
+func TestHTTPProviderLookupByCode(t *testing.T) {
+	srv, last := newTestServer(t, map[string]string{
+		"/items/A-1":    `{"name":"商品A","description":"説明","url":"https://shop.example.com/items/A-1","image":"https://shop.example.com/a.png","price":1980,"stock":true,"review":4.5}`,
+		"/items/a%2Fb":  `{"name":"商品B","price":"1,200","stock":"in_stock","review":"3.5"}`,
+		"/items/empty":  `{"price":100}`,
+		"/items/broken": `{"name":`,
+	})
+	hp := NewHTTPProvider(HTTPConfig{Name: "shop", LookupURL: srv.URL + "/items/{code}", APIKey: "secret"})
+
+	tests := []struct {
+		name    string
+		code    string
+		want    Item
+		wantErr error
+	}{
+		{
+			name: "decodes every field",
+			code: "A-1",
+			want: Item{Provider: "shop", Code: "A-1", Name: "商品A", Description: "説明", Url: "https://shop.example.com/items/A-1", Image: "https://shop.example.com/a.png", Price: 1980, Stock: true, Review: 4.5},
+		},
+		{
+			name: "escapes the code and converts strings",
+			code: "a/b",
+			want: Item{Provider: "shop", Code: "a/b", Name: "商品B", Price: 1200, Stock: true, Review: 3.5},
+		},
+		{name: "item without name is not found", code: "empty", wantErr: ErrNotFound},
+		{name: "404 is not found", code: "missing", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := hp.LookupByCode(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupByCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if *item != tt.want {
+				t.Errorf("item = %+v, want %+v", *item, tt.want)
+			}
+			if got := last.Header.Get("X-Api-Key"); got != "secret" {
+				t.Errorf("X-Api-Key = %q, want secret", got)
+			}
+			if got := last.Header.Get("Accept"); got != "application/json" {
+				t.Errorf("Accept = %q", got)
+			}
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := hp.LookupByCode("broken"); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("LookupByCode() error = %v, want a decode error", err)
+		}
+	})
+}
+
+func TestHTTPProviderErrors(t *testing.T) {
+	srv, _ := newTestServer(t, map[string]string{})
+
+	if _, err := NewHTTPProvider(HTTPConfig{Name: "shop", LookupURL: srv.URL + "/error"}).LookupByCode("1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("server error: error = %v", err)
+	}
+	if _, err := NewHTTPProvider(HTTPConfig{Name: "shop"}).LookupByCode("1"); err == nil {
+		t.Error("lookup without LookupURL succeeded")
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	if _, err := NewHTTPProvider(HTTPConfig{Name: "shop", LookupURL: slow.URL + "/{code}", Timeout: 50 * time.Millisecond}).LookupByCode("1"); err == nil {
+		t.Error("lookup did not time out")
+	}
+}
+
+func TestHTTPProviderFieldsAndHeader(t *testing.T) {
+	srv, last := newTestServer(t, map[string]string{
+		"/search": `{"Items":[{"Item":{"itemName":"商品","itemPrice":500,"availability":0,"reviewAverage":2.5}}]}`,
+	})
+	hp := NewHTTPProvider(HTTPConfig{
+		Name:         "rakuten",
+		LookupURL:    srv.URL + "/search?code={code}",
+		APIKey:       "key",
+		APIKeyHeader: "Authorization",
+		Fields: map[string]string{
+			"name":   "Items.0.Item.itemName",
+			"price":  "Items.0.Item.itemPrice",
+			"stock":  "Items.0.Item.availability",
+			"review": "Items.0.Item.reviewAverage",
+			"image":  "Items.5.Item.image",
+		},
+	})
+
+	item, err := hp.LookupByCode("shop:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Item{Provider: "rakuten", Code: "shop:1", Name: "商品", Price: 500, Stock: false, Review: 2.5}
+	if *item != want {
+		t.Errorf("item = %+v, want %+v", *item, want)
+	}
+	if got := last.Header.Get("Authorization"); got != "key" {
+		t.Errorf("Authorization = %q, want key", got)
+	}
+	if got := last.URL.Query().Get("code"); got != "shop:1" {
+		t.Errorf("code = %q, want shop:1", got)
+	}
+}
+
+func TestHTTPProviderLookupByURLAndRefresh(t *testing.T) {
+	srv, _ := newTestServer(t, map[string]string{
+		"/items/42": `{"name":"商品","price":3000,"stock":3,"review":4}`,
+	})
+	hp := NewHTTPProvider(HTTPConfig{
+		Name:       "shop",
+		LookupURL:  srv.URL + "/items/{code}",
+		URLPattern: `^https://shop\.example\.com/items/(\d+)`,
+	})
+
+	item, err := hp.LookupByURL("https://shop.example.com/items/42?ref=top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Code != "42" || item.Name != "商品" {
+		t.Errorf("item = %+v", *item)
+	}
+	for _, url := range []string{"https://other.example.com/items/42", "https://shop.example.com/items/"} {
+		if _, err := hp.LookupByURL(url); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("LookupByURL(%q) error = %v, want ErrUnsupportedURL", url, err)
+		}
+	}
+
+	offer, err := hp.Refresh("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *offer != (Offer{Price: 3000, Stock: true, Review: 4}) {
+		t.Errorf("offer = %+v", *offer)
+	}
+	if _, err := hp.Refresh("43"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Refresh() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHTTPProviderInvalidURLPattern(t *testing.T) {
+	hp := NewHTTPProvider(HTTPConfig{Name: "shop", LookupURL: "https://api.example.com/{code}", URLPattern: "("})
+	if _, err := hp.LookupByURL("https://shop.example.com/items/1"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("LookupByURL() error = %v, want ErrUnsupportedURL", err)
+	}
+}
+
+func TestHTTPConfigFromEnv(t *testing.T) {
+	t.Setenv("PRODUCT_PROVIDER_SHOP_API_LOOKUP_URL", "https://api.example.com/{code}")
+	t.Setenv("PRODUCT_PROVIDER_SHOP_API_API_KEY_HEADER", "Authorization")
+	t.Setenv("PRODUCT_PROVIDER_SHOP_API_FIELDS", "price = a.price, name=a.title, invalid")
+	t.Setenv("PRODUCT_PROVIDER_SHOP_API_TIMEOUT", "3")
+
+	cfg := HTTPConfigFromEnv("shop-api")
+	if cfg.LookupURL != "https://api.example.com/{code}" || cfg.APIKeyHeader != "Authorization" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if len(cfg.Fields) != 2 || cfg.Fields["price"] != "a.price" || cfg.Fields["name"] != "a.title" {
+		t.Errorf("Fields = %v", cfg.Fields)
+	}
+	if cfg.Timeout != 3*time.Second {
+		t.Errorf("Timeout = %s, want 3s", cfg.Timeout)
+	}
+}
@@ -0,0 +1,100 @@
+// Package marketplace はショッピングサイト（楽天、Yahoo!など）の商品情報の取得を抽象化する
+package marketplace
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrNotFound は商品が見つからない場合のエラー
+var ErrNotFound = errors.New("item not found")
+
+// ErrUnsupportedURL はURLから商品コードを判別できない場合のエラー
+var ErrUnsupportedURL = errors.New("unsupported item url")
+
+// Item はショッピングサイトの商品情報
+type Item struct {
+	Provider    string  `json:"provider"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Url         string  `json:"url"`
+	Image       string  `json:"image"`
+	Price       uint    `json:"price"`
+	Stock       bool    `json:"stock"`
+	Review      float64 `json:"review"`
+}
+
+// Offer は定期的に変わる価格・在庫・評価
+type Offer struct {
+	Price  uint
+	Stock  bool
+	Review float64
+}
+
+// ProductProvider は1つのショッピングサイトから商品情報を取得する
+type ProductProvider interface {
+	// Name はProduct.Providerに保存する名前
+	Name() string
+	LookupByCode(code string) (*Item, error)
+	LookupByURL(url string) (*Item, error)
+	// Refresh は現在の価格・在庫・評価を取得する
+	Refresh(code string) (*Offer, error)
+}
+
+// NewRegistry は環境変数PRODUCT_PROVIDERS（カンマ区切り）に指定された名前ごとにProductProviderを登録する
+// 名前ごとの設定は PRODUCT_PROVIDER_{名前の大文字}_* で指定する（HTTPConfigFromEnvを参照）
+// PRODUCT_PROVIDER_{名前}_TYPE=fake の場合は商品が空のFakeProviderを登録する
+func NewRegistry() *Registry {
+	r := &Registry{providers: map[string]ProductProvider{}}
+	for _, name := range strings.Split(os.Getenv("PRODUCT_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if os.Getenv(envKey(name, "TYPE")) == "fake" {
+			r.Register(NewFakeProvider(name))
+			continue
+		}
+		r.Register(NewHTTPProvider(HTTPConfigFromEnv(name)))
+	}
+	return r
+}
+
+// Registry はProduct.Providerの名前ごとにProductProviderを保持する
+// URLからの検索で複数のProductProviderが対応する場合に結果が変わらないよう、登録順も保持する
+type Registry struct {
+	providers map[string]ProductProvider
+	order     []string
+}
+
+// Register はProductProviderを登録する。同じ名前の場合は置き換え、順番は最初に登録した位置のままにする
+func (r *Registry) Register(p ProductProvider) {
+	if _, ok := r.providers[p.Name()]; !ok {
+		r.order = append(r.order, p.Name())
+	}
+	r.providers[p.Name()] = p
+}
+
+func (r *Registry) Get(name string) (ProductProvider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// FindByURL はURLから商品を取得できるProductProviderを登録順に探して商品情報を返す
+func (r *Registry) FindByURL(url string) (*Item, error) {
+	for _, name := range r.order {
+		item, err := r.providers[name].LookupByURL(url)
+		if errors.Is(err, ErrUnsupportedURL) {
+			continue
+		}
+		return item, err
+	}
+	return nil, ErrUnsupportedURL
+}
+
+func envKey(name string, key string) string {
+	name = strings.ToUpper(strings.NewReplacer("-", "_").Replace(name))
+	return "PRODUCT_PROVIDER_" + name + "_" + key
+}
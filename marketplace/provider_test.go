@@ -0,0 +1,135 @@
+package marketplace
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRegistry(t *testing.T) {
+	t.Setenv("PRODUCT_PROVIDERS", " demo , shop-api ,,")
+	t.Setenv("PRODUCT_PROVIDER_DEMO_TYPE", "fake")
+	t.Setenv("PRODUCT_PROVIDER_SHOP_API_LOOKUP_URL", "https://api.example.com/items/{code}")
+
+	r := NewRegistry()
+
+	demo, ok := r.Get("demo")
+	if !ok {
+		t.Fatal("demo provider is not registered")
+	}
+	if _, isFake := demo.(*FakeProvider); !isFake {
+		t.Errorf("demo provider = %T, want *FakeProvider", demo)
+	}
+	shop, ok := r.Get("shop-api")
+	if !ok {
+		t.Fatal("shop-api provider is not registered")
+	}
+	hp, isHTTP := shop.(*httpProvider)
+	if !isHTTP {
+		t.Fatalf("shop-api provider = %T, want *httpProvider", shop)
+	}
+	if hp.cfg.LookupURL != "https://api.example.com/items/{code}" {
+		t.Errorf("LookupURL = %q", hp.cfg.LookupURL)
+	}
+	if _, ok := r.Get(""); ok {
+		t.Error("empty provider name is registered")
+	}
+	if _, ok := r.Get("unknown"); ok {
+		t.Error("unknown provider is registered")
+	}
+}
+
+func TestNewRegistryWithoutProviders(t *testing.T) {
+	t.Setenv("PRODUCT_PROVIDERS", "")
+
+	r := NewRegistry()
+	if _, err := r.FindByURL("https://shop.example.com/items/1"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("FindByURL() error = %v, want ErrUnsupportedURL", err)
+	}
+}
+
+func TestRegistryFindByURL(t *testing.T) {
+	r := &Registry{providers: map[string]ProductProvider{}}
+	r.Register(NewFakeProvider("a", Item{Code: "1", Name: "商品A", Url: "https://a.example.com/items/1"}))
+	r.Register(NewFakeProvider("b", Item{Code: "2", Name: "商品B", Url: "https://b.example.com/items/2"}))
+
+	tests := []struct {
+		name         string
+		url          string
+		wantProvider string
+		wantCode     string
+		wantErr      error
+	}{
+		{"first provider", "https://a.example.com/items/1", "a", "1", nil},
+		{"second provider", "https://b.example.com/items/2", "b", "2", nil},
+		{"ignores trailing slash", "https://b.example.com/items/2/", "b", "2", nil},
+		{"no provider supports the url", "https://c.example.com/items/3", "", "", ErrUnsupportedURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := r.FindByURL(tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindByURL() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if item.Provider != tt.wantProvider || item.Code != tt.wantCode {
+				t.Errorf("item = %s/%s, want %s/%s", item.Provider, item.Code, tt.wantProvider, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRegistryFindByURLUsesRegistrationOrder(t *testing.T) {
+	item := Item{Code: "1", Name: "商品", Url: "https://shop.example.com/items/1"}
+	r := &Registry{providers: map[string]ProductProvider{}}
+	for _, name := range []string{"z", "a", "m"} {
+		r.Register(NewFakeProvider(name, item))
+	}
+	// 同じ名前で登録し直しても順番は変わらない
+	r.Register(NewFakeProvider("z", item))
+
+	for i := 0; i < 20; i++ {
+		got, err := r.FindByURL(item.Url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Provider != "z" {
+			t.Fatalf("Provider = %q, want z", got.Provider)
+		}
+	}
+}
+
+func TestFakeProvider(t *testing.T) {
+	fp := NewFakeProvider("demo", Item{Provider: "other", Code: "1", Name: "商品", Price: 1000, Stock: true, Review: 4.5})
+
+	item, err := fp.LookupByCode("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Providerは登録したProductProviderの名前になる
+	if item.Provider != "demo" {
+		t.Errorf("Provider = %q, want demo", item.Provider)
+	}
+	if _, err := fp.LookupByCode("2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LookupByCode() error = %v, want ErrNotFound", err)
+	}
+
+	fp.Put(Item{Code: "1", Name: "商品", Price: 800, Stock: false, Review: 4.0})
+	offer, err := fp.Refresh("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *offer != (Offer{Price: 800, Stock: false, Review: 4.0}) {
+		t.Errorf("offer = %+v", *offer)
+	}
+	if _, err := fp.Refresh("2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Refresh() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	if got := envKey("shop-api", "LOOKUP_URL"); got != "PRODUCT_PROVIDER_SHOP_API_LOOKUP_URL" {
+		t.Errorf("envKey() = %q", got)
+	}
+}
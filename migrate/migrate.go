@@ -2,13 +2,137 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"merchandise-review-list-backend/db"
 	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 func main() {
 	dbConn := db.NewDB()
 	defer fmt.Println("Successfully Migrated")
 	defer db.CloseDB(dbConn)
-	dbConn.AutoMigrate(&model.User{}, &model.Product{}, &model.ReviewPost{}, &model.Like{}, &model.Comment{}, &model.MoneyManagement{}, &model.Budget{})
+
+	// 商品の一意制約を追加する前に、同じユーザーが重複して保存した商品がないか確認する
+	if dbConn.Migrator().HasTable(&model.Product{}) && !dbConn.Migrator().HasIndex(&model.Product{}, "idx_products_user_provider_code") {
+		checkDuplicateProducts(dbConn)
+	}
+	// 家計簿の購入日を追加する前から登録されている家計簿は、列の追加後に購入日を移す
+	backfillPurchasedAt := dbConn.Migrator().HasTable(&model.MoneyManagement{}) && !dbConn.Migrator().HasColumn(&model.MoneyManagement{}, "PurchasedAt")
+	if err := dbConn.AutoMigrate(&model.User{}, &model.Product{}, &model.ReviewPost{}, &model.Like{}, &model.Comment{}, &model.MoneyManagement{}, &model.Budget{}, &model.ReviewPostImage{}, &model.ReviewPostRevision{}, &model.RatingCriterion{}, &model.ReviewPostRating{}, &model.Collection{}, &model.CollectionItem{}, &model.Tag{}, &model.ReviewPostTag{}, &model.Category{}, &model.BudgetAmount{}, &model.ReviewPostScore{}, &model.ReviewPostSimilarity{}, &model.ModerationHold{}, &model.ContentFingerprint{}, &model.RateLimitBucket{}, &model.ProductHistory{}, &model.NotificationSetting{}, &model.ReminderDelivery{}, &model.Notification{}, &model.ProductPriceSnapshot{}, &model.CalendarFeed{}, &model.Wishlist{}, &model.WishlistMember{}, &model.WishlistItem{}); err != nil {
+		log.Fatalln(err)
+	}
+
+	// 期限なしを表していた1990年より前の日時（ゼロ値）をNULLにする
+	exec(dbConn, "ALTER TABLE products ALTER COLUMN time_limit DROP NOT NULL")
+	exec(dbConn, "UPDATE products SET time_limit = NULL WHERE time_limit < ?", time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	// 以前はupdated_atを購入日として扱っていたため、ユーザーのタイムゾーンでのその日付を購入日とする
+	if backfillPurchasedAt {
+		exec(dbConn, "UPDATE money_managements m SET purchased_at = (m.updated_at AT TIME ZONE u.time_zone)::date FROM users u WHERE u.id = m.user_id")
+	}
+
+	// 既存の商品は保存時の価格を最初の記録とする
+	exec(dbConn, "INSERT INTO product_price_snapshots (price, stock, review, source, observed_at, created_at, product_id, user_id) SELECT price, stock, review, ?, created_at, NOW(), id, user_id FROM products p WHERE NOT EXISTS (SELECT 1 FROM product_price_snapshots s WHERE s.product_id = p.id)", model.ProductPriceSourceInitial)
+
+	// 既存の投稿は作成日時に公開されたものとする
+	exec(dbConn, "UPDATE review_posts SET published_at = created_at WHERE status = ? AND published_at IS NULL", model.ReviewPostStatusPublished)
+
+	seedCategories(dbConn)
+	seedRatingCriteria(dbConn)
+
+	// 既存の予算の固定カラムをカテゴリーごとの予算に移す
+	for key, column := range map[string]string{
+		"food": "food", "drink": "drink", "book": "book", "fashion": "fashion", "furniture": "furniture",
+		"gamesToys": "games_toys", "beauty": "beauty", "everyDayItems": "every_day_items", "other": "other",
+	} {
+		exec(dbConn, "INSERT INTO budget_amounts (category_key, amount, budget_id) SELECT ?, "+column+", id FROM budgets ON CONFLICT DO NOTHING", key)
+	}
+}
+
+// exec はSQLを実行し、失敗した場合はマイグレーションを中断する
+func exec(dbConn *gorm.DB, sql string, values ...interface{}) {
+	if err := dbConn.Exec(sql, values...).Error; err != nil {
+		log.Fatalf("%s: %v", sql, err)
+	}
+}
+
+// checkDuplicateProducts は同じユーザーが同じ商品（provider, code）を重複して保存している場合に、一覧を出力して中断する。
+// 重複した商品には価格の記録・共有リスト・リマインダーなどが紐付いているため、自動では削除しない
+func checkDuplicateProducts(dbConn *gorm.DB) {
+	var duplicates []struct {
+		UserId   uint
+		Provider string
+		Code     string
+		Ids      string
+	}
+	if err := dbConn.Raw("SELECT user_id, provider, code, string_agg(id::text, ',' ORDER BY id) AS ids FROM products WHERE code <> '' GROUP BY user_id, provider, code HAVING COUNT(*) > 1").
+		Scan(&duplicates).Error; err != nil {
+		log.Fatalln(err)
+	}
+	if len(duplicates) == 0 {
+		return
+	}
+
+	for _, d := range duplicates {
+		log.Printf("duplicate products: user_id=%d provider=%s code=%s ids=%s", d.UserId, d.Provider, d.Code, d.Ids)
+	}
+	log.Fatalf("found %d duplicate products; merge or delete them before adding the unique index on (user_id, provider, code)", len(duplicates))
+}
+
+// seedCategories はカテゴリーの初期値を登録する（既に登録済みのものは変更しない）
+func seedCategories(dbConn *gorm.DB) {
+	categories := []model.Category{
+		{Key: "food", NameJa: "食べ物", NameEn: "Food", Icon: "restaurant"},
+		{Key: "drink", NameJa: "飲み物", NameEn: "Drink", Icon: "local_cafe"},
+		{Key: "book", NameJa: "本", NameEn: "Books", Icon: "menu_book"},
+		{Key: "fashion", NameJa: "ファッション", NameEn: "Fashion", Icon: "checkroom"},
+		{Key: "furniture", NameJa: "家具", NameEn: "Furniture", Icon: "chair"},
+		{Key: "gamesToys", NameJa: "ゲーム・おもちゃ", NameEn: "Games & Toys", Icon: "toys"},
+		{Key: "beauty", NameJa: "美容", NameEn: "Beauty", Icon: "face"},
+		{Key: "everyDayItems", NameJa: "日用品", NameEn: "Everyday Items", Icon: "shopping_basket"},
+		{Key: "other", NameJa: "その他", NameEn: "Other", Icon: "more_horiz"},
+	}
+
+	for i, c := range categories {
+		c.Position = i
+		if err := dbConn.Where(model.Category{Key: c.Key}).FirstOrCreate(&c).Error; err != nil {
+			log.Fatalln(err)
+		}
+	}
+}
+
+// seedRatingCriteria はカテゴリーごとの評価項目の初期値を登録する（既に登録済みのものは変更しない）
+func seedRatingCriteria(dbConn *gorm.DB) {
+	criteria := []model.RatingCriterion{
+		{Category: "food", Key: "taste", Name: "味"},
+		{Category: "food", Key: "price", Name: "価格"},
+		{Category: "food", Key: "packaging", Name: "パッケージ"},
+		{Category: "drink", Key: "taste", Name: "味"},
+		{Category: "drink", Key: "price", Name: "価格"},
+		{Category: "drink", Key: "packaging", Name: "パッケージ"},
+		{Category: "book", Key: "content", Name: "内容"},
+		{Category: "book", Key: "readability", Name: "読みやすさ"},
+		{Category: "fashion", Key: "design", Name: "デザイン"},
+		{Category: "fashion", Key: "durability", Name: "耐久性"},
+		{Category: "fashion", Key: "comfort", Name: "着心地"},
+		{Category: "furniture", Key: "design", Name: "デザイン"},
+		{Category: "furniture", Key: "durability", Name: "耐久性"},
+		{Category: "furniture", Key: "assembly", Name: "組み立てやすさ"},
+		{Category: "beauty", Key: "effect", Name: "効果"},
+		{Category: "beauty", Key: "texture", Name: "使用感"},
+		{Category: "beauty", Key: "price", Name: "価格"},
+	}
+
+	positions := map[string]int{}
+	for _, c := range criteria {
+		c.Min, c.Max, c.Step, c.Weight = 1, 5, 0.5, 1
+		c.Position = positions[c.Category]
+		positions[c.Category]++
+		if err := dbConn.Where(model.RatingCriterion{Category: c.Category, Key: c.Key}).FirstOrCreate(&c).Error; err != nil {
+			log.Fatalln(err)
+		}
+	}
 }
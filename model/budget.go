@@ -3,40 +3,84 @@ package model
 import "time"
 
 type Budget struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	Month         string    `json:"month" gorm:"not null"`
-	Year          string    `json:"year" gorm:"not null"`
-	TotalPrice    uint      `json:"total_price" gorm:"not null"`
-	Food          uint      `json:"food" gorm:"not null"`
-	Drink         uint      `json:"drink" gorm:"not null"`
-	Book          uint      `json:"book" gorm:"not null"`
-	Fashion       uint      `json:"fashion" gorm:"not null"`
-	Furniture     uint      `json:"furniture" gorm:"not null"`
-	GamesToys     uint      `json:"games_toys" gorm:"not null"`
-	Beauty        uint      `json:"beauty" gorm:"not null"`
-	EveryDayItems uint      `json:"every_day_items" gorm:"not null"`
-	Other         uint      `json:"other" gorm:"not null"`
-	Notice        bool      `json:"notice" gorm:"not null"`
-	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
-	User          User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId        uint      `json:"user_id" gorm:"not null"`
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	Month         string `json:"month" gorm:"not null"`
+	Year          string `json:"year" gorm:"not null"`
+	TotalPrice    uint   `json:"total_price" gorm:"not null"`
+	Food          uint   `json:"food" gorm:"not null"`
+	Drink         uint   `json:"drink" gorm:"not null"`
+	Book          uint   `json:"book" gorm:"not null"`
+	Fashion       uint   `json:"fashion" gorm:"not null"`
+	Furniture     uint   `json:"furniture" gorm:"not null"`
+	GamesToys     uint   `json:"games_toys" gorm:"not null"`
+	Beauty        uint   `json:"beauty" gorm:"not null"`
+	EveryDayItems uint   `json:"every_day_items" gorm:"not null"`
+	Other         uint   `json:"other" gorm:"not null"`
+	Notice        bool   `json:"notice" gorm:"not null"`
+	// カテゴリーのキーごとの予算（budget_amountsテーブルに保存する）
+	Amounts   map[string]uint `json:"amounts" gorm:"-"`
+	CreatedAt time.Time       `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time       `gorm:"autoUpdateTime"`
+	User      User            `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint            `json:"user_id" gorm:"not null"`
 }
 
 type BudgetResponse struct {
-	ID            uint      `json:"id"`
-	Month         string    `json:"month"`
-	Year          string    `json:"year"`
-	TotalPrice    uint      `json:"total_price"`
-	Food          uint      `json:"food"`
-	Drink         uint      `json:"drink"`
-	Book          uint      `json:"book"`
-	Fashion       uint      `json:"fashion"`
-	Furniture     uint      `json:"furniture"`
-	GamesToys     uint      `json:"games_toys"`
-	Beauty        uint      `json:"beauty"`
-	EveryDayItems uint      `json:"every_day_items"`
-	Other         uint      `json:"other"`
-	Notice        bool      `json:"notice"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            uint            `json:"id"`
+	Month         string          `json:"month"`
+	Year          string          `json:"year"`
+	TotalPrice    uint            `json:"total_price"`
+	Food          uint            `json:"food"`
+	Drink         uint            `json:"drink"`
+	Book          uint            `json:"book"`
+	Fashion       uint            `json:"fashion"`
+	Furniture     uint            `json:"furniture"`
+	GamesToys     uint            `json:"games_toys"`
+	Beauty        uint            `json:"beauty"`
+	EveryDayItems uint            `json:"every_day_items"`
+	Other         uint            `json:"other"`
+	Notice        bool            `json:"notice"`
+	Amounts       map[string]uint `json:"amounts"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// カテゴリーごとの予算
+type BudgetAmount struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	CategoryKey string `json:"category_key" gorm:"not null;uniqueIndex:idx_budget_amounts_budget_category"`
+	Amount      uint   `json:"amount" gorm:"not null"`
+	Budget      Budget `json:"budget" gorm:"foreignKey:BudgetId; constraint:OnDelete:CASCADE"`
+	BudgetId    uint   `json:"budget_id" gorm:"not null;uniqueIndex:idx_budget_amounts_budget_category"`
+}
+
+// LegacyCategoryColumns は固定カラムを持つカテゴリーのキーと対応するフィールドを返す
+func (b *Budget) LegacyCategoryColumns() map[string]*uint {
+	return map[string]*uint{
+		"food":          &b.Food,
+		"drink":         &b.Drink,
+		"book":          &b.Book,
+		"fashion":       &b.Fashion,
+		"furniture":     &b.Furniture,
+		"gamesToys":     &b.GamesToys,
+		"beauty":        &b.Beauty,
+		"everyDayItems": &b.EveryDayItems,
+		"other":         &b.Other,
+	}
+}
+
+// 予算と実績の書き出しのリクエスト
+type BudgetExportRequest struct {
+	Year     string `json:"year"`
+	Format   string `json:"format"`
+	Encoding string `json:"encoding"`
+}
+
+// 月・カテゴリーごとの予算と実績（Categoryが空の行は月の合計）
+type BudgetActualResponse struct {
+	Month      string `json:"month"`
+	Category   string `json:"category"`
+	Name       string `json:"name"`
+	Budget     uint   `json:"budget"`
+	Actual     uint   `json:"actual"`
+	Difference int    `json:"difference"` // 予算-実績（超過した場合は負）
 }
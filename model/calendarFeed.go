@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// 商品の期限のカレンダー（ICS）を配信する秘密のURLのトークン
+type CalendarFeed struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Token     string    `json:"token" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+}
+
+type CalendarFeedResponse struct {
+	Url       string    `json:"url"`
+	WebcalUrl string    `json:"webcal_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
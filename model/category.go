@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// 投稿・家計簿・予算で共通のカテゴリー
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Key       string    `json:"key" gorm:"not null;uniqueIndex"`
+	NameJa    string    `json:"name_ja" gorm:"not null"`
+	NameEn    string    `json:"name_en" gorm:"not null"`
+	Icon      string    `json:"icon" gorm:"not null"`
+	Position  int       `json:"position" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CategoryResponse struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	NameJa   string `json:"name_ja"`
+	NameEn   string `json:"name_en"`
+	Icon     string `json:"icon"`
+	Position int    `json:"position"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const (
+	CollectionVisibilityPrivate = "private"
+	CollectionVisibilityShared  = "shared"
+)
+
+type Collection struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description" gorm:"not null"`
+	Visibility  string    `json:"visibility" gorm:"not null;default:private"`
+	ShareSlug   string    `json:"share_slug" gorm:"not null;uniqueIndex"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	User        User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null;index"`
+}
+
+type CollectionItem struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Position     int        `json:"position" gorm:"not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Collection   Collection `json:"collection" gorm:"foreignKey:CollectionId; constraint:OnDelete:CASCADE"`
+	CollectionId uint       `json:"collection_id" gorm:"not null;uniqueIndex:idx_collection_items_collection_post"`
+	ReviewPost   ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId       uint       `json:"post_id" gorm:"not null;uniqueIndex:idx_collection_items_collection_post"`
+}
+
+type CollectionResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	ShareSlug   string    `json:"share_slug"`
+	ItemCount   uint      `json:"item_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CollectionDetailResponse struct {
+	CollectionResponse
+	Items []ReviewPostResponse `json:"items"`
+}
+
+type CollectionItemRequest struct {
+	PostId uint `json:"post_id"`
+}
+
+type CollectionItemOrderRequest struct {
+	PostIds []uint `json:"post_ids"`
+}
@@ -2,9 +2,16 @@ package model
 
 import "time"
 
+const (
+	CommentStatusVisible = "visible"
+	// 不適切な内容の可能性があり、管理者の確認待ちの状態
+	CommentStatusPending = "pending"
+)
+
 type Comment struct {
 	ID         uint       `json:"id" gorm:"primaryKey"`
 	Text       string     `json:"text" gorm:"not null"`
+	Status     string     `json:"status" gorm:"not null;default:visible"`
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
 	ReviewPost ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
@@ -16,6 +23,7 @@ type Comment struct {
 type CommentResponse struct {
 	ID        uint        `json:"id"`
 	Text      string      `json:"text"`
+	Status    string      `json:"status"`
 	User      CommentUser `json:"comment_user"`
 	UserId    uint        `json:"user_id"`
 	CreatedAt time.Time   `json:"created_at"`
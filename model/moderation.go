@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	ModerationHoldStatusPending  = "pending"
+	ModerationHoldStatusApproved = "approved"
+	ModerationHoldStatusRejected = "rejected"
+)
+
+// 管理者の確認待ちになった投稿・コメント
+type ModerationHold struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Kind       string     `json:"kind" gorm:"not null"`
+	TargetId   uint       `json:"target_id" gorm:"not null"`
+	Reasons    string     `json:"reasons" gorm:"not null"`
+	Status     string     `json:"status" gorm:"not null;default:pending;index"`
+	ResolvedBy *uint      `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint       `json:"user_id" gorm:"not null"`
+}
+
+type ModerationHoldResponse struct {
+	ID        uint      `json:"id"`
+	Kind      string    `json:"kind"`
+	TargetId  uint      `json:"target_id"`
+	Reasons   []string  `json:"reasons"`
+	Status    string    `json:"status"`
+	Text      string    `json:"text"`
+	UserId    uint      `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 連続投稿の検出に使う、ユーザーが送信したテキストの指紋
+type ContentFingerprint struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Kind        string    `json:"kind" gorm:"not null;index:idx_content_fingerprints_lookup,priority:1"`
+	Fingerprint string    `json:"fingerprint" gorm:"not null;index:idx_content_fingerprints_lookup,priority:3"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+	User        User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null;index:idx_content_fingerprints_lookup,priority:2"`
+}
@@ -3,27 +3,34 @@ package model
 import "time"
 
 type MoneyManagement struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	Title      string    `json:"title" gorm:"not null"`
-	Category   string    `json:"category" gorm:"not null"`
-	UnitPrice  uint      `json:"unit_price" gorm:"not null"`
-	Quantity   uint      `json:"quantity" gorm:"not null"`
-	TotalPrice uint      `json:"total_price" gorm:"not null"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	User       User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId     uint      `json:"user_id" gorm:"not null"`
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Title       string    `json:"title" gorm:"not null"`
+	Category    string    `json:"category" gorm:"not null"`
+	UnitPrice   uint      `json:"unit_price" gorm:"not null"`
+	Quantity    uint      `json:"quantity" gorm:"not null"`
+	TotalPrice  uint      `json:"total_price" gorm:"not null"`
+	Product     *Product  `json:"-" gorm:"foreignKey:ProductId; constraint:OnDelete:SET NULL"`
+	ProductId   *uint     `json:"product_id" gorm:"index"` // 商品から登録した場合の元の商品（削除した場合もProductUrlで辿れる）
+	ProductUrl  string    `json:"product_url" gorm:"not null;default:''"`
+	PurchasedAt time.Time `json:"purchased_at" gorm:"type:date;not null;default:CURRENT_DATE;index"` // 購入日（date型のため時刻はUTCの0時）。月・年の集計はこの日付で行う
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	User        User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null"`
 }
 
 type MoneyManagementResponse struct {
-	ID         uint      `json:"id"`
-	Title      string    `json:"title"`
-	Category   string    `json:"category"`
-	UnitPrice  uint      `json:"unit_price"`
-	Quantity   uint      `json:"quantity"`
-	TotalPrice uint      `json:"total_price"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	UnitPrice   uint      `json:"unit_price"`
+	Quantity    uint      `json:"quantity"`
+	TotalPrice  uint      `json:"total_price"`
+	ProductId   *uint     `json:"product_id"`
+	ProductUrl  string    `json:"product_url"`
+	PurchasedAt time.Time `json:"purchased_at"` // ユーザーのタイムゾーンでの購入日の0時
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type MoneyManagementByCategoryItemResponse struct {
@@ -41,5 +48,100 @@ type MoneyManagementByCategoryResponse struct {
 	Beauty        MoneyManagementByCategoryItemResponse `json:"beauty"`
 	EveryDayItems MoneyManagementByCategoryItemResponse `json:"everyDayItems"`
 	Other         MoneyManagementByCategoryItemResponse `json:"other"`
-	TotalPrice    uint                                  `json:"totalPrice"`
+	// categoriesテーブルの全カテゴリーをキーごとにまとめたもの
+	Categories map[string]MoneyManagementByCategoryItemResponse `json:"categories"`
+	TotalPrice uint                                             `json:"totalPrice"`
+}
+
+// LegacyCategoryFields は固定フィールドを持つカテゴリーのキーと対応するフィールドを返す
+func (r *MoneyManagementByCategoryResponse) LegacyCategoryFields() map[string]*MoneyManagementByCategoryItemResponse {
+	return map[string]*MoneyManagementByCategoryItemResponse{
+		"food":          &r.Food,
+		"drink":         &r.Drink,
+		"book":          &r.Book,
+		"fashion":       &r.Fashion,
+		"furniture":     &r.Furniture,
+		"gamesToys":     &r.GamesToys,
+		"beauty":        &r.Beauty,
+		"everyDayItems": &r.EveryDayItems,
+		"other":         &r.Other,
+	}
+}
+
+// 一括登録で取り込むファイルの形式
+const (
+	MoneyManagementImportFormatCSV  = "csv"
+	MoneyManagementImportFormatJSON = "json"
+)
+
+// 一括登録の行ごとの結果
+const (
+	MoneyManagementImportRowOk        = "ok"
+	MoneyManagementImportRowDuplicate = "duplicate" // 登録済みまたはファイル内で重複しているため取り込まない
+	MoneyManagementImportRowError     = "error"
+)
+
+// DefaultMoneyManagementImportMapping は列の対応を指定しない場合の、項目名と取り込むファイルの列名（JSONの場合はキー）の対応
+var DefaultMoneyManagementImportMapping = map[string]string{
+	"title":        "title",
+	"category":     "category",
+	"unit_price":   "unit_price",
+	"quantity":     "quantity",
+	"total_price":  "total_price",
+	"purchased_at": "purchased_at",
+}
+
+// 家計簿の一括登録のリクエスト
+type MoneyManagementImportRequest struct {
+	Format          string
+	Encoding        string            // CSVの文字コード（空の場合は自動判定）
+	Mapping         map[string]string // 項目名と列名の対応（指定のない項目は既定の列名）
+	DryRun          bool              // trueの場合は検証のみ行い登録しない
+	AllowDuplicates bool              // trueの場合は重複も登録する
+	Data            []byte
+}
+
+type MoneyManagementImportRowResponse struct {
+	Row    int                      `json:"row"` // CSVの場合はヘッダーを1行目とした行番号、JSONの場合は1始まりの要素の番号
+	Status string                   `json:"status"`
+	Errors map[string]string        `json:"errors,omitempty"`
+	Item   *MoneyManagementResponse `json:"item,omitempty"`
+}
+
+type MoneyManagementImportResponse struct {
+	DryRun         bool                               `json:"dry_run"`
+	Committed      bool                               `json:"committed"`
+	TotalRows      int                                `json:"total_rows"`
+	ImportCount    int                                `json:"import_count"`
+	DuplicateCount int                                `json:"duplicate_count"`
+	ErrorCount     int                                `json:"error_count"`
+	Rows           []MoneyManagementImportRowResponse `json:"rows"`
+}
+
+// 家計簿・予算の書き出しの形式
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// 家計簿の書き出しのリクエスト。From・Toは「2006-01-02」形式の日付で、Toの日を含む
+type MoneyManagementExportRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Format   string `json:"format"`
+	Encoding string `json:"encoding"` // CSVの文字コード（utf-8またはshift_jis）
+}
+
+type MoneyManagementCategorySubtotalResponse struct {
+	Category   string `json:"category"`
+	Name       string `json:"name"`
+	Count      uint   `json:"count"`
+	TotalPrice uint   `json:"total_price"`
+}
+
+// 月・カテゴリーごとの家計簿の合計
+type MoneyManagementMonthlyTotal struct {
+	Month      string // 2006-01
+	Category   string
+	TotalPrice uint
 }
@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// 通知の送信方法
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelInApp   = "inApp"
+)
+
+// DefaultReminderLeadMinutes は設定がない場合の、期限の何分前に通知するか（1日前と1時間前）
+const DefaultReminderLeadMinutes = "1440,60"
+
+// ユーザーごとの通知設定
+type NotificationSetting struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	EmailEnabled bool      `json:"email_enabled" gorm:"not null;default:false"`
+	InAppEnabled bool      `json:"in_app_enabled" gorm:"not null;default:true"`
+	WebhookUrl   string    `json:"webhook_url" gorm:"not null;default:''"`
+	LeadMinutes  string    `json:"lead_minutes" gorm:"not null;default:'1440,60'"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	User         User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId       uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+}
+
+type NotificationSettingRequest struct {
+	EmailEnabled bool   `json:"email_enabled"`
+	InAppEnabled bool   `json:"in_app_enabled"`
+	WebhookUrl   string `json:"webhook_url"`
+	LeadMinutes  []int  `json:"lead_minutes"`
+}
+
+type NotificationSettingResponse struct {
+	EmailEnabled bool   `json:"email_enabled"`
+	InAppEnabled bool   `json:"in_app_enabled"`
+	WebhookUrl   string `json:"webhook_url"`
+	LeadMinutes  []int  `json:"lead_minutes"`
+}
+
+// 期限の通知の送信記録（同じ期限・タイミング・送信方法では一度だけ送る）
+type ReminderDelivery struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	LeadMinutes int       `json:"lead_minutes" gorm:"not null;uniqueIndex:idx_reminder_deliveries_unique"`
+	Channel     string    `json:"channel" gorm:"not null;uniqueIndex:idx_reminder_deliveries_unique"`
+	TimeLimit   time.Time `json:"time_limit" gorm:"not null;uniqueIndex:idx_reminder_deliveries_unique"`
+	SentAt      time.Time `json:"sent_at" gorm:"not null"`
+	Product     Product   `json:"product" gorm:"foreignKey:ProductId; constraint:OnDelete:CASCADE"`
+	ProductId   uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_reminder_deliveries_unique"`
+	User        User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null"`
+}
+
+// アプリ内通知
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Title     string     `json:"title" gorm:"not null"`
+	Body      string     `json:"body" gorm:"not null"`
+	Link      string     `json:"link" gorm:"not null;default:''"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null;index"`
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// 通知の送信先（通知設定がない場合は設定の項目がnil）
+type NotificationRecipient struct {
+	UserId       uint
+	Email        string
+	TimeZone     string
+	EmailEnabled *bool
+	InAppEnabled *bool
+	WebhookUrl   *string
+}
+
+// 期限の通知対象の商品と送信先
+type ProductReminderTarget struct {
+	ProductId   uint
+	ProductName string
+	TimeLimit   time.Time
+	LeadMinutes *string
+	NotificationRecipient
+}
@@ -2,35 +2,111 @@ package model
 
 import "time"
 
+// 商品の優先度
+const (
+	ProductPriorityNone   = 0
+	ProductPriorityLow    = 1
+	ProductPriorityMedium = 2
+	ProductPriorityHigh   = 3
+)
+
+// 同じユーザーが同じ商品（provider, code）を重複して保存しないよう一意にする
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description" gorm:"not null"`
-	Stock       bool      `json:"stock" gorm:"not null"`
-	Price       uint      `json:"price" gorm:"not null"`
-	Review      float64   `json:"review" gorm:"not null"`
-	Url         string    `json:"url" gorm:"not null"`
-	Image       string    `json:"image" gorm:"not null"`
-	Code        string    `json:"code" gorm:"not null"`
-	Provider    string    `json:"provider" gorm:"not null"`
-	TimeLimit   time.Time `json:"timeLimit" gorm:"not null"`
-	User        User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId      uint      `json:"user_id" gorm:"not null"`
-	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	Description string     `json:"description" gorm:"not null"`
+	Stock       bool       `json:"stock" gorm:"not null"`
+	Price       uint       `json:"price" gorm:"not null"`
+	Review      float64    `json:"review" gorm:"not null"`
+	Url         string     `json:"url" gorm:"not null"`
+	Image       string     `json:"image" gorm:"not null"`
+	Code        string     `json:"code" gorm:"not null;uniqueIndex:idx_products_user_provider_code,priority:3,where:code <> ''"`
+	Provider    string     `json:"provider" gorm:"not null;uniqueIndex:idx_products_user_provider_code,priority:2,where:code <> ''"`
+	TimeLimit   *time.Time `json:"timeLimit"` // 期限なしの場合はnil
+	Notes       string     `json:"notes" gorm:"not null;default:''"`
+	Priority    int        `json:"priority" gorm:"not null;default:0"`
+	TargetPrice *uint      `json:"target_price"` // この価格以下になったら通知する
+	PurchasedAt *time.Time `json:"purchased_at"`
+	ArchivedAt  *time.Time `json:"archived_at" gorm:"index"` // アーカイブした商品は一覧・通知・カレンダーに含めない
+	User        User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_products_user_provider_code,priority:1,where:code <> ''"`
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Stock       bool      `json:"stock"`
-	Price       uint      `json:"price"`
-	Review      float64   `json:"review"`
-	Url         string    `json:"url"`
-	Image       string    `json:"image"`
-	Provider    string    `json:"provider"`
-	TimeLimit   time.Time `json:"timeLimit"`
-	CreatedAt   time.Time
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Stock           bool       `json:"stock"`
+	Price           uint       `json:"price"`
+	Review          float64    `json:"review"`
+	Url             string     `json:"url"`
+	Image           string     `json:"image"`
+	Code            string     `json:"code"`
+	Provider        string     `json:"provider"`
+	TimeLimit       *time.Time `json:"timeLimit"`
+	Overdue         bool       `json:"overdue"` // 期限を過ぎているか
+	Notes           string     `json:"notes"`
+	Priority        int        `json:"priority"`
+	TargetPrice     *uint      `json:"target_price"`
+	PurchasedAt     *time.Time `json:"purchased_at"`
+	ArchivedAt      *time.Time `json:"archived_at"`
+	CreatedAt       time.Time
+	ReviewAggregate ProductReviewAggregateResponse `json:"review_aggregate"`
+}
+
+// 期限による商品の絞り込み
+const (
+	ProductDeadlineWith      = "with"      // 期限あり
+	ProductDeadlineWithout   = "without"   // 期限なし
+	ProductDeadlineOverdue   = "overdue"   // 期限切れ
+	ProductDeadlineDueWithin = "dueWithin" // Days日以内に期限を迎える
+)
+
+type ProductDeadlineFilter struct {
+	Deadline string
+	Days     int
+	Now      time.Time
+	Until    time.Time // dueWithinの場合の終わり（Nowから利用者のタイムゾーンでDays日後）
+}
+
+// ショッピングサイトから取得した商品情報
+type ProductLookupResponse struct {
+	Provider    string  `json:"provider"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Url         string  `json:"url"`
+	Image       string  `json:"image"`
+	Price       uint    `json:"price"`
+	Stock       bool    `json:"stock"`
+	Review      float64 `json:"review"`
+}
+
+// 購入後の商品の扱い
+const (
+	ProductPurchaseKeep    = "keep"    // そのまま残す
+	ProductPurchaseArchive = "archive" // アーカイブする
+	ProductPurchaseRemove  = "remove"  // 削除する
+)
+
+// 商品から家計簿を登録するリクエスト。省略した項目は商品の値（数量は1、カテゴリーは同じ商品のレビューの多いもの）を使用する
+type ProductPurchaseRequest struct {
+	Title       string     `json:"title"`
+	Category    string     `json:"category"`
+	UnitPrice   *uint      `json:"unit_price"`
+	Quantity    uint       `json:"quantity"`
+	PurchasedAt *time.Time `json:"purchased_at"`
+	After       string     `json:"after"`
+}
+
+type ProductPurchaseResponse struct {
+	MoneyManagement MoneyManagementResponse `json:"money_management"`
+	Product         *ProductResponse        `json:"product"` // 削除した場合はnull
+}
+
+type ProductBulkDeleteRequest struct {
+	ProductIds []uint `json:"product_ids"`
 }
 
 type ProductYearMonthResponse struct {
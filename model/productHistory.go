@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// 商品の期限の変更方法
+const (
+	ProductTimeLimitModeSet    = "set"
+	ProductTimeLimitModeSnooze = "snooze"
+	ProductTimeLimitModeClear  = "clear"
+)
+
+// 期限を延長する長さ
+const (
+	ProductSnoozeHour   = "1h"
+	ProductSnoozeDay    = "1d"
+	ProductSnoozeWeek   = "1w"
+	ProductSnoozeCustom = "custom"
+)
+
+// 商品の変更履歴
+type ProductHistory struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Action          string     `json:"action" gorm:"not null"`
+	Detail          string     `json:"detail" gorm:"not null;default:''"`
+	BeforeTimeLimit *time.Time `json:"before_time_limit"`
+	AfterTimeLimit  *time.Time `json:"after_time_limit"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Product         Product    `json:"product" gorm:"foreignKey:ProductId; constraint:OnDelete:CASCADE"`
+	ProductId       uint       `json:"product_id" gorm:"not null;index"`
+	User            User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId          uint       `json:"user_id" gorm:"not null"`
+}
+
+type ProductHistoryResponse struct {
+	ID              uint       `json:"id"`
+	Action          string     `json:"action"`
+	Detail          string     `json:"detail"`
+	BeforeTimeLimit *time.Time `json:"before_time_limit"`
+	AfterTimeLimit  *time.Time `json:"after_time_limit"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// 期限の変更リクエスト
+// setはTimeLimitに、snoozeはSnooze（customの場合はMinutes分）だけ延長、clearは期限なしにする
+type ProductTimeLimitRequest struct {
+	Mode      string    `json:"mode"`
+	TimeLimit time.Time `json:"timeLimit"`
+	Snooze    string    `json:"snooze"`
+	Minutes   int       `json:"minutes"`
+}
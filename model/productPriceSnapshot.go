@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// 価格の記録方法
+const (
+	ProductPriceSourceInitial = "initial" // 商品の保存時
+	ProductPriceSourceManual  = "manual"
+	ProductPriceSourceImport  = "import"
+)
+
+// 商品の価格・在庫・評価の記録
+type ProductPriceSnapshot struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Price      uint      `json:"price" gorm:"not null"`
+	Stock      bool      `json:"stock" gorm:"not null"`
+	Review     float64   `json:"review" gorm:"not null"`
+	Source     string    `json:"source" gorm:"not null"`
+	ObservedAt time.Time `json:"observed_at" gorm:"not null;index:idx_product_price_snapshots_product_observed,priority:2"`
+	CreatedAt  time.Time `json:"created_at"`
+	Product    Product   `json:"product" gorm:"foreignKey:ProductId; constraint:OnDelete:CASCADE"`
+	ProductId  uint      `json:"product_id" gorm:"not null;index:idx_product_price_snapshots_product_observed,priority:1"`
+	User       User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint      `json:"user_id" gorm:"not null"`
+}
+
+type ProductPriceSnapshotResponse struct {
+	ID         uint      `json:"id"`
+	Price      uint      `json:"price"`
+	Stock      bool      `json:"stock"`
+	Review     float64   `json:"review"`
+	Source     string    `json:"source"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// ObservedAtを省略した場合は現在日時とする
+type ProductPriceSnapshotRequest struct {
+	Price      uint       `json:"price"`
+	Stock      bool       `json:"stock"`
+	Review     float64    `json:"review"`
+	Source     string     `json:"source"`
+	ObservedAt *time.Time `json:"observed_at"`
+}
+
+// TargetPriceをnullにすると目標価格を解除する
+type ProductTargetPriceRequest struct {
+	TargetPrice *uint `json:"target_price"`
+}
+
+type ProductPriceTimelineResponse struct {
+	Snapshots    []ProductPriceSnapshotResponse `json:"snapshots"`
+	LowestPrice  uint                           `json:"lowest_price"`
+	HighestPrice uint                           `json:"highest_price"`
+	TargetPrice  *uint                          `json:"target_price"`
+}
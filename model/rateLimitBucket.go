@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// リクエスト数制限のトークンバケット（複数インスタンスで共有する場合に使用）
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime:false;index"` // 古いバケットの削除に使う
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// カテゴリーごとの評価項目の設定
+type RatingCriterion struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Category  string    `json:"category" gorm:"not null;uniqueIndex:idx_rating_criteria_category_key"`
+	Key       string    `json:"key" gorm:"not null;uniqueIndex:idx_rating_criteria_category_key"`
+	Name      string    `json:"name" gorm:"not null"`
+	Min       float64   `json:"min" gorm:"not null"`
+	Max       float64   `json:"max" gorm:"not null"`
+	Step      float64   `json:"step" gorm:"not null"`
+	Weight    float64   `json:"weight" gorm:"not null;default:1"`
+	Position  int       `json:"position" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 投稿の評価項目ごとの点数
+type ReviewPostRating struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CriterionKey string     `json:"key" gorm:"not null"`
+	Score        float64    `json:"score" gorm:"not null"`
+	ReviewPost   ReviewPost `json:"-" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId       uint       `json:"post_id" gorm:"not null;index"`
+}
+
+type RatingCriterionResponse struct {
+	Key    string  `json:"key"`
+	Name   string  `json:"name"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Step   float64 `json:"step"`
+	Weight float64 `json:"weight"`
+}
+
+type ReviewPostRatingResponse struct {
+	Key   string  `json:"key"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type CriterionAggregateResponse struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name"`
+	AverageScore float64 `json:"average_score"`
+	Count        uint    `json:"count"`
+}
@@ -2,32 +2,56 @@ package model
 
 import "time"
 
+const (
+	ReviewPostStatusDraft     = "draft"
+	ReviewPostStatusScheduled = "scheduled"
+	ReviewPostStatusPublished = "published"
+	// 不適切な内容の可能性があり、管理者の確認待ちの状態
+	ReviewPostStatusPending = "pending"
+)
+
 type ReviewPost struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	Text      string    `json:"text" gorm:"not null"`
-	Image     string    `json:"image"`
-	Review    float64   `json:"review" gorm:"not null"`
-	Category  string    `json:"category" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId    uint      `json:"user_id" gorm:"not null"`
+	ID              uint               `json:"id" gorm:"primaryKey"`
+	Title           string             `json:"title" gorm:"not null"`
+	Text            string             `json:"text" gorm:"not null"`
+	Image           string             `json:"image"`
+	Review          float64            `json:"review" gorm:"not null"`
+	Category        string             `json:"category" gorm:"not null"`
+	ProductProvider string             `json:"product_provider" gorm:"index:idx_review_posts_product"`
+	ProductCode     string             `json:"product_code" gorm:"index:idx_review_posts_product"`
+	EditedAt        *time.Time         `json:"edited_at"`
+	Status          string             `json:"status" gorm:"not null;default:published;index"`
+	PublishedAt     *time.Time         `json:"published_at" gorm:"index"` // 予約投稿の場合は公開予定日時
+	Ratings         []ReviewPostRating `json:"ratings" gorm:"-"`
+	Tags            []string           `json:"tags" gorm:"-"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	User            User               `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId          uint               `json:"user_id" gorm:"not null"`
 }
 
 type ReviewPostResponse struct {
-	ID           uint                   `json:"id"`
-	Title        string                 `json:"title"`
-	Text         string                 `json:"text"`
-	Image        string                 `json:"image"`
-	Review       float64                `json:"review"`
-	Category     string                 `json:"category"`
-	CreatedAt    time.Time              `json:"created_at"`
-	User         ReviewPostUserResponse `json:"reviewPostUserResponse"`
-	UserId       uint                   `json:"user_id"`
-	LikeCount    uint                   `json:"like_count"`
-	LikeId       uint                   `json:"like_id"`
-	CommentCount uint                   `json:"comment_count"`
+	ID              uint                       `json:"id"`
+	Title           string                     `json:"title"`
+	Text            string                     `json:"text"`
+	Image           string                     `json:"image"`
+	Images          []ReviewPostImageResponse  `json:"images"`
+	Review          float64                    `json:"review"`
+	Ratings         []ReviewPostRatingResponse `json:"ratings"`
+	Category        string                     `json:"category"`
+	Tags            []string                   `json:"tags"`
+	ProductProvider string                     `json:"product_provider"`
+	ProductCode     string                     `json:"product_code"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	Edited          bool                       `json:"edited"`
+	EditedAt        *time.Time                 `json:"edited_at"`
+	Status          string                     `json:"status"`
+	PublishedAt     *time.Time                 `json:"published_at"`
+	User            ReviewPostUserResponse     `json:"reviewPostUserResponse"`
+	UserId          uint                       `json:"user_id"`
+	LikeCount       uint                       `json:"like_count"`
+	LikeId          uint                       `json:"like_id"`
+	CommentCount    uint                       `json:"comment_count"`
 }
 
 type ReviewPostUserResponse struct {
@@ -35,3 +59,22 @@ type ReviewPostUserResponse struct {
 	Name  string `json:"name"`
 	Image string `json:"image"`
 }
+
+// レビューを紐付ける商品の識別子
+type ProductKey struct {
+	Provider string
+	Code     string
+}
+
+// 商品（provider, code）ごとのレビュー集計
+type ProductReviewAggregateResponse struct {
+	AverageRating float64                          `json:"average_rating"`
+	ReviewCount   uint                             `json:"review_count"`
+	Histogram     []ProductReviewHistogramResponse `json:"histogram"`
+	Criteria      []CriterionAggregateResponse     `json:"criteria"`
+}
+
+type ProductReviewHistogramResponse struct {
+	Rating int  `json:"rating"`
+	Count  uint `json:"count"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type ReviewPostImage struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Key         string     `json:"key" gorm:"not null"`
+	Ext         string     `json:"ext" gorm:"not null"`
+	ContentType string     `json:"content_type" gorm:"not null"`
+	Url         string     `json:"url" gorm:"not null"`
+	SmallUrl    string     `json:"small_url" gorm:"not null"`
+	MediumUrl   string     `json:"medium_url" gorm:"not null"`
+	LargeUrl    string     `json:"large_url" gorm:"not null"`
+	Width       int        `json:"width" gorm:"not null"`
+	Height      int        `json:"height" gorm:"not null"`
+	Position    int        `json:"position" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewPost  ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId      uint       `json:"post_id" gorm:"not null;index"`
+}
+
+type ReviewPostImageResponse struct {
+	ID        uint   `json:"id"`
+	Url       string `json:"url"`
+	SmallUrl  string `json:"small_url"`
+	MediumUrl string `json:"medium_url"`
+	LargeUrl  string `json:"large_url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Position  int    `json:"position"`
+}
+
+type ReviewPostImageOrderRequest struct {
+	ImageIds []uint `json:"image_ids"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// 更新前の投稿内容のスナップショット
+type ReviewPostRevision struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Title           string     `json:"title" gorm:"not null"`
+	Text            string     `json:"text" gorm:"not null"`
+	Image           string     `json:"image"`
+	Review          float64    `json:"review" gorm:"not null"`
+	Category        string     `json:"category" gorm:"not null"`
+	ProductProvider string     `json:"product_provider"`
+	ProductCode     string     `json:"product_code"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ReviewPost      ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId          uint       `json:"post_id" gorm:"not null;index"`
+}
+
+type ReviewPostRevisionResponse struct {
+	Revision int                           `json:"revision"`
+	EditedAt time.Time                     `json:"edited_at"`
+	Changes  []ReviewPostFieldDiffResponse `json:"changes"`
+}
+
+type ReviewPostFieldDiffResponse struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
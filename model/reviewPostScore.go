@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// ランキングの集計期間
+const (
+	RankingPeriodDay   = "day"
+	RankingPeriodWeek  = "week"
+	RankingPeriodMonth = "month"
+)
+
+// 集計期間ごとの投稿のランキングスコア（バックグラウンドで定期的に再計算する）
+type ReviewPostScore struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Period     string     `json:"period" gorm:"not null;uniqueIndex:idx_review_post_scores_period_post;index:idx_review_post_scores_ranking,priority:1"`
+	Category   string     `json:"category" gorm:"not null;index:idx_review_post_scores_ranking,priority:2"`
+	Score      float64    `json:"score" gorm:"not null;index:idx_review_post_scores_ranking,priority:3"`
+	Likes      uint       `json:"likes" gorm:"not null"`
+	Comments   uint       `json:"comments" gorm:"not null"`
+	ComputedAt time.Time  `json:"computed_at" gorm:"not null"`
+	ReviewPost ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId     uint       `json:"post_id" gorm:"not null;uniqueIndex:idx_review_post_scores_period_post"`
+}
+
+// スコア計算に使う集計期間内の投稿の反応数
+type ReviewPostActivity struct {
+	PostId      uint
+	Category    string
+	PublishedAt time.Time
+	Likes       uint
+	Comments    uint
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// 投稿ごとの類似投稿のキャッシュ
+type ReviewPostSimilarity struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Score         float64    `json:"score" gorm:"not null"`
+	ComputedAt    time.Time  `json:"computed_at" gorm:"not null"`
+	ReviewPost    ReviewPost `json:"reviewPost" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId        uint       `json:"post_id" gorm:"not null;index"`
+	SimilarPost   ReviewPost `json:"similarPost" gorm:"foreignKey:SimilarPostId; constraint:OnDelete:CASCADE"`
+	SimilarPostId uint       `json:"similar_post_id" gorm:"not null"`
+}
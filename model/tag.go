@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReviewPostTag struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+	ReviewPost ReviewPost `json:"-" gorm:"foreignKey:PostId; constraint:OnDelete:CASCADE"`
+	PostId     uint       `json:"post_id" gorm:"not null;uniqueIndex:idx_review_post_tags_post_tag"`
+	Tag        Tag        `json:"-" gorm:"foreignKey:TagId; constraint:OnDelete:CASCADE"`
+	TagId      uint       `json:"tag_id" gorm:"not null;uniqueIndex:idx_review_post_tags_post_tag;index"`
+}
+
+type TagResponse struct {
+	Name  string `json:"name"`
+	Count uint   `json:"count"`
+}
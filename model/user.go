@@ -2,6 +2,9 @@ package model
 
 import "time"
 
+// DefaultTimeZone はユーザーのタイムゾーンが未設定の場合に使用する
+const DefaultTimeZone = "Asia/Tokyo"
+
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Email     string    `json:"email" gorm:"unique"`
@@ -9,6 +12,7 @@ type User struct {
 	Name      string    `json:"name"`
 	Image     string    `json:"image"`
 	Admin     bool      `json:"admin"`
+	TimeZone  string    `json:"time_zone" gorm:"not null;default:'Asia/Tokyo'"` // IANAのタイムゾーン名
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
@@ -19,5 +23,25 @@ type UserResponse struct {
 	Name      string    `json:"name"`
 	Image     string    `json:"image"`
 	Admin     bool      `json:"admin"`
+	TimeZone  string    `json:"time_zone"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// Location はユーザーのタイムゾーンを返す。読み込めない場合はDefaultTimeZoneを使用する
+func (u *User) Location() *time.Location {
+	return LoadLocation(u.TimeZone)
+}
+
+// LoadLocation はタイムゾーン名からtime.Locationを返す。空や不正な名前の場合はDefaultTimeZoneを使用する
+func LoadLocation(name string) *time.Location {
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(DefaultTimeZone)
+	if err != nil {
+		return time.FixedZone(DefaultTimeZone, 9*60*60)
+	}
+	return loc
+}
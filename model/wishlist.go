@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// 共有リストのメンバーの権限
+const (
+	WishlistRoleOwner  = "owner"  // 作成者。リストの変更・削除とメンバーの招待ができる
+	WishlistRoleEditor = "editor" // 商品の追加・削除ができる
+	WishlistRoleViewer = "viewer" // 閲覧と「自分が購入」の登録のみ
+)
+
+// 家族などと共有する欲しいものリスト
+type Wishlist struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null;index"` // 作成者
+}
+
+// 共有リストのメンバー（作成者もownerとして登録する）。招待を承認するまでAcceptedAtはnil
+type WishlistMember struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Role       string     `json:"role" gorm:"not null"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Wishlist   Wishlist   `json:"wishlist" gorm:"foreignKey:WishlistId; constraint:OnDelete:CASCADE"`
+	WishlistId uint       `json:"wishlist_id" gorm:"not null;uniqueIndex:idx_wishlist_members_unique"`
+	User       User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_wishlist_members_unique"`
+}
+
+// 共有リストの商品。BoughtByは購入したメンバー
+type WishlistItem struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	BoughtAt   *time.Time `json:"bought_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Wishlist   Wishlist   `json:"wishlist" gorm:"foreignKey:WishlistId; constraint:OnDelete:CASCADE"`
+	WishlistId uint       `json:"wishlist_id" gorm:"not null;uniqueIndex:idx_wishlist_items_unique"`
+	Product    Product    `json:"product" gorm:"foreignKey:ProductId; constraint:OnDelete:CASCADE"`
+	ProductId  uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_wishlist_items_unique"`
+	AddedBy    uint       `json:"added_by" gorm:"not null"`
+	Buyer      *User      `json:"buyer" gorm:"foreignKey:BoughtBy; constraint:OnDelete:SET NULL"`
+	BoughtBy   *uint      `json:"bought_by"`
+}
+
+type WishlistResponse struct {
+	ID        uint                     `json:"id"`
+	Name      string                   `json:"name"`
+	Role      string                   `json:"role"` // ログインユーザーの権限
+	Accepted  bool                     `json:"accepted"`
+	Members   []WishlistMemberResponse `json:"members"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type WishlistMemberResponse struct {
+	ID         uint       `json:"id"`
+	UserId     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+type WishlistItemResponse struct {
+	ID         uint            `json:"id"`
+	Product    ProductResponse `json:"product"`
+	AddedBy    uint            `json:"added_by"`
+	BoughtBy   *uint           `json:"bought_by"`
+	BoughtName string          `json:"bought_name"`
+	BoughtAt   *time.Time      `json:"bought_at"`
+}
+
+type WishlistInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type WishlistRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type WishlistItemRequest struct {
+	ProductId uint `json:"product_id"`
+}
+
+type WishlistDetailResponse struct {
+	WishlistResponse
+	Items []WishlistItemResponse `json:"items"`
+}
@@ -0,0 +1,93 @@
+package moderation
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type bannedWordFilter struct {
+	words  [][]rune
+	action Action
+}
+
+// NewBannedWordFilter は禁止語を含むテキストにactionを返すフィルターを返す
+// 全角・半角と大文字・小文字は区別しない。ActionMaskの場合は該当部分を*に置き換える
+func NewBannedWordFilter(words []string, action Action) Filter {
+	folded := [][]rune{}
+	for _, w := range words {
+		runes, _ := fold(w)
+		if len(runes) > 0 {
+			folded = append(folded, runes)
+		}
+	}
+	return &bannedWordFilter{folded, action}
+}
+
+func (bf *bannedWordFilter) Apply(content *Content) (Action, string, error) {
+	found := false
+	for _, field := range content.Fields {
+		original := []rune(*field)
+		matched := bf.match(original)
+		if len(matched) == 0 {
+			continue
+		}
+		found = true
+
+		if bf.action == ActionMask {
+			for i := range matched {
+				original[i] = '*'
+			}
+			*field = string(original)
+		}
+	}
+
+	if !found {
+		return ActionAllow, "", nil
+	}
+	return bf.action, "contains banned words", nil
+}
+
+// match は禁止語に該当する元のテキストの文字位置を返す
+func (bf *bannedWordFilter) match(original []rune) map[int]bool {
+	folded, positions := foldRunes(original)
+	matched := map[int]bool{}
+	for _, w := range bf.words {
+		for i := 0; i+len(w) <= len(folded); i++ {
+			if !equalRunes(folded[i:i+len(w)], w) {
+				continue
+			}
+			for j := i; j < i+len(w); j++ {
+				matched[positions[j]] = true
+			}
+		}
+	}
+	return matched
+}
+
+func fold(s string) ([]rune, []int) {
+	return foldRunes([]rune(s))
+}
+
+// foldRunes は1文字ずつNFKD正規化して小文字にした文字列と、各文字の元の位置を返す
+// 合成ではなく分解するため、半角カナの濁点（ﾊﾞ）と全角の濁音（バ）が同じ並びになる
+func foldRunes(original []rune) ([]rune, []int) {
+	folded := []rune{}
+	positions := []int{}
+	for i, r := range original {
+		for _, f := range strings.ToLower(norm.NFKD.String(string(r))) {
+			folded = append(folded, f)
+			positions = append(positions, i)
+		}
+	}
+	return folded, positions
+}
+
+func equalRunes(a []rune, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package moderation
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxLinks     = 3
+	defaultRepeatMax    = 3
+	defaultRepeatWindow = 24 * time.Hour
+)
+
+// defaultWords は設定ファイルがない場合の禁止語
+var defaultWords = map[Action][]string{
+	ActionReject: {"死ね", "殺すぞ", "kill yourself"},
+	ActionMask:   {"バカ", "アホ", "クソ", "fuck", "shit"},
+	ActionHold:   {"出会い系", "副業で稼", "casino"},
+}
+
+// NewModerator は環境変数の設定からModeratorを返す
+// MODERATION_WORDS_FILE: 1行に「対応:禁止語」（例: mask:バカ）を書いたファイル。#で始まる行は無視する
+// MODERATION_MAX_LINKS: 保留にしないリンク数の上限
+// MODERATION_REPEAT_MAX: 24時間以内に同じテキストを送信できる回数
+func NewModerator(store FingerprintStore) Moderator {
+	words := defaultWords
+	if path := os.Getenv("MODERATION_WORDS_FILE"); path != "" {
+		loaded, err := loadWords(path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		words = loaded
+	}
+
+	filters := []Filter{}
+	for _, action := range []Action{ActionReject, ActionHold, ActionMask} {
+		if len(words[action]) > 0 {
+			filters = append(filters, NewBannedWordFilter(words[action], action))
+		}
+	}
+	filters = append(filters,
+		NewLinkFilter(envInt("MODERATION_MAX_LINKS", defaultMaxLinks), ActionHold),
+		NewRepeatedContentFilter(store, defaultRepeatWindow, envInt("MODERATION_REPEAT_MAX", defaultRepeatMax),
+			[]string{KindReviewPost, KindComment}, ActionHold),
+	)
+	return NewPipeline(filters...)
+}
+
+func loadWords(path string) (map[Action][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := map[Action][]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 対応の指定がない行はrejectとして扱う
+		action := ActionReject
+		if name, word, ok := strings.Cut(line, ":"); ok {
+			if a, ok := ParseAction(name); ok {
+				action, line = a, word
+			}
+		}
+		words[action] = append(words[action], strings.TrimSpace(line))
+	}
+	return words, scanner.Err()
+}
+
+func envInt(key string, defaultValue int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
@@ -0,0 +1,29 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+type linkFilter struct {
+	max    int
+	action Action
+}
+
+// NewLinkFilter はリンクの数がmaxを超えるテキストにactionを返すフィルターを返す
+func NewLinkFilter(max int, action Action) Filter {
+	return &linkFilter{max, action}
+}
+
+func (lf *linkFilter) Apply(content *Content) (Action, string, error) {
+	count := 0
+	for _, field := range content.Fields {
+		count += len(linkPattern.FindAllStringIndex(*field, -1))
+	}
+	if count <= lf.max {
+		return ActionAllow, "", nil
+	}
+	return lf.action, fmt.Sprintf("contains more than %d links", lf.max), nil
+}
@@ -0,0 +1,103 @@
+// Package moderation は投稿・コメント・ユーザー名などの投稿テキストを検査するフィルターのパイプライン
+package moderation
+
+import "strings"
+
+// Action はフィルターが判定した対応（値が大きいほど重い）
+type Action int
+
+const (
+	ActionAllow Action = iota
+	ActionMask
+	ActionHold
+	ActionReject
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMask:
+		return "mask"
+	case ActionHold:
+		return "hold"
+	case ActionReject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// ParseAction は設定の文字列をActionに変換する
+func ParseAction(s string) (Action, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "allow":
+		return ActionAllow, true
+	case "mask":
+		return ActionMask, true
+	case "hold":
+		return ActionHold, true
+	case "reject":
+		return ActionReject, true
+	}
+	return ActionAllow, false
+}
+
+// 検査するテキストの種類
+const (
+	KindReviewPost = "reviewPost"
+	KindComment    = "comment"
+	KindUserName   = "userName"
+)
+
+// Content は検査するテキスト。Fieldsはマスクされた場合に書き換えられる
+type Content struct {
+	Kind   string
+	UserId uint
+	Fields []*string
+}
+
+// Filter はContentを検査し、対応と理由を返す。問題がない場合はActionAllowを返す
+type Filter interface {
+	Apply(content *Content) (Action, string, error)
+}
+
+// Verdict はパイプライン全体の判定結果
+type Verdict struct {
+	Action  Action
+	Reasons []string
+}
+
+type Moderator interface {
+	Moderate(content *Content) (Verdict, error)
+}
+
+type pipeline struct {
+	filters []Filter
+}
+
+// NewPipeline はfiltersを順に適用するModeratorを返す
+func NewPipeline(filters ...Filter) Moderator {
+	return &pipeline{filters}
+}
+
+// Moderate は全てのフィルターを適用し、最も重い対応を返す（rejectの時点で打ち切る）
+func (p *pipeline) Moderate(content *Content) (Verdict, error) {
+	verdict := Verdict{Action: ActionAllow, Reasons: []string{}}
+	for _, f := range p.filters {
+		action, reason, err := f.Apply(content)
+		if err != nil {
+			return Verdict{}, err
+		}
+		if action == ActionAllow {
+			continue
+		}
+
+		verdict.Reasons = append(verdict.Reasons, reason)
+		if action > verdict.Action {
+			verdict.Action = action
+		}
+		if action == ActionReject {
+			break
+		}
+	}
+	return verdict, nil
+}
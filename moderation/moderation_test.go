@@ -0,0 +1,191 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newContent(kind string, userId uint, fields ...string) *Content {
+	content := &Content{Kind: kind, UserId: userId}
+	for i := range fields {
+		content.Fields = append(content.Fields, &fields[i])
+	}
+	return content
+}
+
+func TestBannedWordFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		words      []string
+		action     Action
+		text       string
+		wantAction Action
+		wantText   string
+	}{
+		{"no banned words", []string{"バカ"}, ActionMask, "良い商品でした", ActionAllow, "良い商品でした"},
+		{"masks japanese word", []string{"バカ"}, ActionMask, "これはバカな値段", ActionMask, "これは**な値段"},
+		{"matches half-width katakana", []string{"バカ"}, ActionMask, "ﾊﾞｶだ", ActionMask, "***だ"},
+		{"ignores case and width", []string{"casino"}, ActionHold, "ＣＡＳＩＮＯで稼ぐ", ActionHold, "ＣＡＳＩＮＯで稼ぐ"},
+		{"rejects", []string{"死ね"}, ActionReject, "死ね", ActionReject, "死ね"},
+		{"masks every occurrence", []string{"shit"}, ActionMask, "Shit and shit", ActionMask, "**** and ****"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := newContent(KindComment, 1, tt.text)
+			action, _, err := NewBannedWordFilter(tt.words, tt.action).Apply(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if action != tt.wantAction {
+				t.Errorf("action = %v, want %v", action, tt.wantAction)
+			}
+			if *content.Fields[0] != tt.wantText {
+				t.Errorf("text = %q, want %q", *content.Fields[0], tt.wantText)
+			}
+		})
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  Action
+	}{
+		{"no links", []string{"リンクなし"}, ActionAllow},
+		{"at the limit", []string{"https://a.example http://b.example"}, ActionAllow},
+		{"over the limit across fields", []string{"https://a.example", "www.b.example HTTPS://c.example"}, ActionHold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, _, err := NewLinkFilter(2, ActionHold).Apply(newContent(KindReviewPost, 1, tt.texts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if action != tt.want {
+				t.Errorf("action = %v, want %v", action, tt.want)
+			}
+		})
+	}
+}
+
+type fakeFingerprintStore struct {
+	fingerprints map[string]int
+	err          error
+}
+
+func (fs *fakeFingerprintStore) CountFingerprints(kind string, userId uint, fingerprint string, since time.Time) (int, error) {
+	return fs.fingerprints[fmt.Sprintf("%s/%d/%s", kind, userId, fingerprint)], fs.err
+}
+
+func (fs *fakeFingerprintStore) CreateFingerprint(kind string, userId uint, fingerprint string) error {
+	fs.fingerprints[fmt.Sprintf("%s/%d/%s", kind, userId, fingerprint)]++
+	return nil
+}
+
+func TestRepeatedContentFilter(t *testing.T) {
+	store := &fakeFingerprintStore{fingerprints: map[string]int{}}
+	filter := NewRepeatedContentFilter(store, time.Hour, 2, []string{KindComment}, ActionHold)
+
+	// 空白と全角・半角の違いは同じテキストとして数える
+	texts := []string{"同じ コメント", "同じ　コメント", "同じ  コメント"}
+	want := []Action{ActionAllow, ActionAllow, ActionHold}
+	for i, text := range texts {
+		action, _, err := filter.Apply(newContent(KindComment, 1, text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action != want[i] {
+			t.Errorf("submission %d: action = %v, want %v", i+1, action, want[i])
+		}
+	}
+
+	// 別のユーザーは別に数える
+	if action, _, _ := filter.Apply(newContent(KindComment, 2, "同じ コメント")); action != ActionAllow {
+		t.Errorf("other user: action = %v, want allow", action)
+	}
+	// 対象外の種類と未ログインは検査しない
+	if action, _, _ := filter.Apply(newContent(KindUserName, 1, "同じ コメント")); action != ActionAllow {
+		t.Errorf("other kind: action = %v, want allow", action)
+	}
+	if action, _, _ := filter.Apply(newContent(KindComment, 0, "同じ コメント")); action != ActionAllow {
+		t.Errorf("anonymous: action = %v, want allow", action)
+	}
+
+	store.err = errors.New("db down")
+	if _, _, err := filter.Apply(newContent(KindComment, 1, "別のコメント")); err == nil {
+		t.Error("store error was ignored")
+	}
+}
+
+type stubFilter struct {
+	action  Action
+	reason  string
+	applied *int
+}
+
+func (sf stubFilter) Apply(content *Content) (Action, string, error) {
+	*sf.applied++
+	return sf.action, sf.reason, nil
+}
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		actions     []Action
+		wantAction  Action
+		wantReasons []string
+		wantApplied int
+	}{
+		{"all allow", []Action{ActionAllow, ActionAllow}, ActionAllow, []string{}, 2},
+		{"heaviest action wins", []Action{ActionMask, ActionHold, ActionAllow}, ActionHold, []string{"f0", "f1"}, 3},
+		{"hold does not downgrade to mask", []Action{ActionHold, ActionMask}, ActionHold, []string{"f0", "f1"}, 2},
+		{"reject stops the pipeline", []Action{ActionMask, ActionReject, ActionHold}, ActionReject, []string{"f0", "f1"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := 0
+			filters := []Filter{}
+			for i, a := range tt.actions {
+				filters = append(filters, stubFilter{a, fmt.Sprintf("f%d", i), &applied})
+			}
+
+			verdict, err := NewPipeline(filters...).Moderate(newContent(KindReviewPost, 1, "text"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verdict.Action != tt.wantAction || !reflect.DeepEqual(verdict.Reasons, tt.wantReasons) {
+				t.Errorf("verdict = %v %v, want %v %v", verdict.Action, verdict.Reasons, tt.wantAction, tt.wantReasons)
+			}
+			if applied != tt.wantApplied {
+				t.Errorf("applied %d filters, want %d", applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestLoadWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	data := "# コメント\nmask:バカ\nhold: casino \n死ね\nunknown:word\n\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := loadWords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Action][]string{
+		ActionMask:   {"バカ"},
+		ActionHold:   {"casino"},
+		ActionReject: {"死ね", "unknown:word"},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("words = %v, want %v", words, want)
+	}
+}
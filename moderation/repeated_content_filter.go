@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FingerprintStore はユーザーが送信したテキストの指紋を保存する
+type FingerprintStore interface {
+	CountFingerprints(kind string, userId uint, fingerprint string, since time.Time) (int, error)
+	CreateFingerprint(kind string, userId uint, fingerprint string) error
+}
+
+type repeatedContentFilter struct {
+	store  FingerprintStore
+	window time.Duration
+	max    int
+	kinds  map[string]bool
+	action Action
+}
+
+// NewRepeatedContentFilter は同じユーザーがwindow内に同じテキストをmax回より多く送信した場合にactionを返すフィルターを返す
+// kindsに含まれる種類のテキストのみ検査する
+func NewRepeatedContentFilter(store FingerprintStore, window time.Duration, max int, kinds []string, action Action) Filter {
+	kindSet := map[string]bool{}
+	for _, k := range kinds {
+		kindSet[k] = true
+	}
+	return &repeatedContentFilter{store, window, max, kindSet, action}
+}
+
+func (rf *repeatedContentFilter) Apply(content *Content) (Action, string, error) {
+	if !rf.kinds[content.Kind] || content.UserId == 0 {
+		return ActionAllow, "", nil
+	}
+
+	fingerprint := Fingerprint(content)
+	count, err := rf.store.CountFingerprints(content.Kind, content.UserId, fingerprint, time.Now().Add(-rf.window))
+	if err != nil {
+		return ActionAllow, "", err
+	}
+	if err := rf.store.CreateFingerprint(content.Kind, content.UserId, fingerprint); err != nil {
+		return ActionAllow, "", err
+	}
+
+	if count < rf.max {
+		return ActionAllow, "", nil
+	}
+	return rf.action, "repeated content", nil
+}
+
+// Fingerprint は空白と全角・半角の違いを無視したテキストのハッシュを返す
+func Fingerprint(content *Content) string {
+	parts := []string{}
+	for _, field := range content.Fields {
+		text := strings.ToLower(norm.NFKC.String(*field))
+		parts = append(parts, strings.Join(strings.Fields(text), " "))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
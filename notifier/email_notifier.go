@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type emailNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewEmailNotifier(cfg SMTPConfig) Notifier {
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &emailNotifier{cfg}
+}
+
+func (en *emailNotifier) Channel() string {
+	return model.NotificationChannelEmail
+}
+
+func (en *emailNotifier) Notify(to Recipient, msg Message) error {
+	if to.Email == "" {
+		return errors.New("email is empty")
+	}
+
+	body := msg.Body
+	if msg.Link != "" {
+		body += "\r\n\r\n" + msg.Link
+	}
+	headers := []string{
+		"From: " + en.cfg.From,
+		"To: " + to.Email,
+		"Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	data := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if en.cfg.Username != "" {
+		auth = smtp.PlainAuth("", en.cfg.Username, en.cfg.Password, en.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%s", en.cfg.Host, en.cfg.Port)
+	return smtp.SendMail(addr, auth, en.cfg.From, []string{to.Email}, []byte(data))
+}
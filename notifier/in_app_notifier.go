@@ -0,0 +1,29 @@
+package notifier
+
+import "merchandise-review-list-backend/model"
+
+// InAppStore はアプリ内通知を保存する
+type InAppStore interface {
+	CreateNotification(notification *model.Notification) error
+}
+
+type inAppNotifier struct {
+	store InAppStore
+}
+
+func NewInAppNotifier(store InAppStore) Notifier {
+	return &inAppNotifier{store}
+}
+
+func (in *inAppNotifier) Channel() string {
+	return model.NotificationChannelInApp
+}
+
+func (in *inAppNotifier) Notify(to Recipient, msg Message) error {
+	return in.store.CreateNotification(&model.Notification{
+		Title:  msg.Title,
+		Body:   msg.Body,
+		Link:   msg.Link,
+		UserId: to.UserId,
+	})
+}
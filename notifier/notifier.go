@@ -0,0 +1,44 @@
+// Package notifier はメール・Webhook・アプリ内通知の送信を行う
+package notifier
+
+import (
+	"merchandise-review-list-backend/model"
+	"os"
+)
+
+type Recipient struct {
+	UserId     uint
+	Email      string
+	WebhookUrl string
+}
+
+type Message struct {
+	Title string
+	Body  string
+	Link  string
+}
+
+// Notifier は1つの送信方法で通知を送る
+type Notifier interface {
+	Channel() string
+	Notify(to Recipient, msg Message) error
+}
+
+// NewNotifiers は利用できる送信方法ごとのNotifierを返す
+// メールは環境変数SMTP_HOSTが設定されている場合のみ利用できる
+func NewNotifiers(store InAppStore) map[string]Notifier {
+	notifiers := map[string]Notifier{
+		model.NotificationChannelWebhook: NewWebhookNotifier(),
+		model.NotificationChannelInApp:   NewInAppNotifier(store),
+	}
+	if os.Getenv("SMTP_HOST") != "" {
+		notifiers[model.NotificationChannelEmail] = NewEmailNotifier(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+	}
+	return notifiers
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrUnsafeWebhookURL はWebhookの送信先として許可しないURLの場合のエラー
+var ErrUnsafeWebhookURL = errors.New("webhook url must be https to a public address")
+
+type webhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier() Notifier {
+	return &webhookNotifier{newWebhookClient(isPublicIP)}
+}
+
+// newWebhookClient は接続先のIPアドレスをallowIPで確認するクライアントを返す
+// 名前解決後の実際の接続先を確認するため、DNSの応答を書き換えられても内部のネットワークには接続しない
+// リダイレクト先は確認できないため、リダイレクトには従わない
+func newWebhookClient(allowIP func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowIP(ip) {
+				return fmt.Errorf("%w: %s", ErrUnsafeWebhookURL, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// プロキシを経由すると接続先を確認できないため使用しない
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicIP はループバック・プライベート・リンクローカルなどの内部向けのアドレスでない場合にtrueを返す
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+func (wn *webhookNotifier) Channel() string {
+	return model.NotificationChannelWebhook
+}
+
+// Notify はユーザーが設定したURLに通知内容をJSONでPOSTする
+func (wn *webhookNotifier) Notify(to Recipient, msg Message) error {
+	if to.WebhookUrl == "" {
+		return errors.New("webhook url is empty")
+	}
+	u, err := url.Parse(to.WebhookUrl)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrUnsafeWebhookURL
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"title": msg.Title,
+		"body":  msg.Body,
+		"link":  msg.Link,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := wn.client.Post(u.String(), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false}, // クラウドのメタデータ
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("isPublicIP(%s) = %t, want %t", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierRejectsUnsafeURLs(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached %s", r.URL)
+	}))
+	defer srv.Close()
+
+	wn := NewWebhookNotifier()
+	for _, webhookUrl := range []string{
+		"http://example.com/hook",
+		"ftp://example.com/hook",
+		"https:///hook",
+		srv.URL + "/hook", // 127.0.0.1
+		strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + "/hook",
+	} {
+		t.Run(webhookUrl, func(t *testing.T) {
+			err := wn.Notify(Recipient{WebhookUrl: webhookUrl}, Message{Title: "t"})
+			if !errors.Is(err, ErrUnsafeWebhookURL) {
+				t.Errorf("Notify() error = %v, want ErrUnsafeWebhookURL", err)
+			}
+		})
+	}
+}
+
+// newTestWebhookNotifier はテスト用のサーバー（127.0.0.1）への接続を許可する
+func newTestWebhookNotifier(srv *httptest.Server) *webhookNotifier {
+	client := newWebhookClient(func(ip net.IP) bool { return true })
+	client.Transport.(*http.Transport).TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	return &webhookNotifier{client}
+}
+
+func TestWebhookNotifierPostsJSON(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	msg := Message{Title: "期限", Body: "本文", Link: "https://example.com/products/1"}
+	if err := newTestWebhookNotifier(srv).Notify(Recipient{WebhookUrl: srv.URL + "/hook"}, msg); err != nil {
+		t.Fatal(err)
+	}
+	if got["title"] != msg.Title || got["body"] != msg.Body || got["link"] != msg.Link {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestWebhookNotifierDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	err := newTestWebhookNotifier(srv).Notify(Recipient{WebhookUrl: srv.URL + "/hook"}, Message{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "307") {
+		t.Errorf("Notify() error = %v, want status 307", err)
+	}
+	if redirected {
+		t.Error("redirect was followed")
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// 使われていないバケットを削除する間隔
+const memoryStoreCleanupInterval = 10 * time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type memoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+// NewMemoryStore はプロセス内にバケットを保持するStoreを返す（単一インスタンス向け）
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: map[string]*bucket{}, lastCleanup: time.Now()}
+}
+
+func (ms *memoryStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.cleanup(now)
+
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		ms.buckets[key] = b
+	}
+
+	tokens, allowed, wait := refill(b.tokens, b.updatedAt, limit, now)
+	b.tokens, b.updatedAt = tokens, now
+	return allowed, wait, nil
+}
+
+func (ms *memoryStore) Cleanup(now time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.cleanup(now)
+	return nil
+}
+
+// cleanup は一定時間使われていないバケットを削除する（満タンに戻っているため削除しても結果は変わらない）
+func (ms *memoryStore) cleanup(now time.Time) {
+	if now.Sub(ms.lastCleanup) < memoryStoreCleanupInterval {
+		return
+	}
+	for key, b := range ms.buckets {
+		if now.Sub(b.updatedAt) > memoryStoreCleanupInterval {
+			delete(ms.buckets, key)
+		}
+	}
+	ms.lastCleanup = now
+}
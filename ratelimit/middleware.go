@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware はnameごとのlimitでリクエスト数を制限するミドルウェアを返す
+// JWTのuser_idがあればユーザーごとに、なければIPアドレスごとに制限する
+func Middleware(store Store, name string, limit Limit) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := name + ":" + clientKey(c)
+			allowed, wait, err := store.Take(key, limit, time.Now())
+			if err != nil {
+				// 制限の確認に失敗した場合はリクエストを止めない
+				log.Printf("rate limit %s failed: %v", key, err)
+				return next(c)
+			}
+			if !allowed {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				return c.JSON(http.StatusTooManyRequests, "too many requests")
+			}
+			return next(c)
+		}
+	}
+}
+
+// WriteMiddleware は書き込み系のリクエスト（GET・HEAD・OPTIONS以外）だけをMiddlewareと同じ方法で制限する
+// グループ全体に適用し、新しく追加した書き込み系のエンドポイントが制限から漏れないようにする
+func WriteMiddleware(store Store, name string, limit Limit) echo.MiddlewareFunc {
+	limited := Middleware(store, name, limit)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		limitedNext := limited(next)
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			return limitedNext(c)
+		}
+	}
+}
+
+func clientKey(c echo.Context) string {
+	if user, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := user.Claims.(jwt.MapClaims); ok {
+			if userId, ok := claims["user_id"].(float64); ok {
+				return fmt.Sprintf("user:%d", uint(userId))
+			}
+		}
+	}
+	return "ip:" + c.RealIP()
+}
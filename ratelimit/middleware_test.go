@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+func serve(e *echo.Echo, h echo.HandlerFunc, method string, remoteAddr string, userId uint) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userId != 0 {
+		c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": float64(userId)}})
+	}
+	if err := h(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+	return rec
+}
+
+func okHandler(c echo.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func TestWriteMiddleware(t *testing.T) {
+	e := echo.New()
+	h := WriteMiddleware(NewMemoryStore(), "write/test", PerMinute(2))(okHandler)
+
+	// 読み込み系のリクエストは制限しない
+	for i := 0; i < 5; i++ {
+		if rec := serve(e, h, http.MethodGet, "192.0.2.1:1234", 1); rec.Code != http.StatusOK {
+			t.Fatalf("GET %d: status = %d", i, rec.Code)
+		}
+	}
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		if rec := serve(e, h, method, "192.0.2.1:1234", 1); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", method, rec.Code)
+		}
+	}
+	rec := serve(e, h, http.MethodDelete, "192.0.2.1:1234", 1)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("DELETE over the limit: status = %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("Retry-After = %q, want 30", rec.Header().Get("Retry-After"))
+	}
+
+	// ユーザーごとに別のバケットを使う
+	if rec := serve(e, h, http.MethodPost, "192.0.2.1:1234", 2); rec.Code != http.StatusOK {
+		t.Errorf("other user: status = %d", rec.Code)
+	}
+}
+
+func TestMiddlewareKeysAnonymousRequestsByIP(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	h := Middleware(NewMemoryStore(), "login", PerMinute(1))(okHandler)
+
+	if rec := serve(e, h, http.MethodPost, "192.0.2.1:1234", 0); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d", rec.Code)
+	}
+	// 接続元のポートが変わっても同じIPアドレスとして数える
+	if rec := serve(e, h, http.MethodPost, "192.0.2.1:5678", 0); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("same IP: status = %d", rec.Code)
+	}
+	if rec := serve(e, h, http.MethodPost, "192.0.2.2:1234", 0); rec.Code != http.StatusOK {
+		t.Errorf("other IP: status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postgresStoreBucketTTL はバケットを削除するまでの時間
+// 全ての上限は1分あたりの回数のため、これだけ使われていなければ満タンに戻っており、削除しても結果は変わらない
+const postgresStoreBucketTTL = time.Hour
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore はrate_limit_bucketsテーブルにバケットを保持するStoreを返す（複数インスタンス向け）
+func NewPostgresStore(db *gorm.DB) Store {
+	return &postgresStore{db}
+}
+
+func (ps *postgresStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	var allowed bool
+	var wait time.Duration
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		initial := model.RateLimitBucket{Key: key, Tokens: float64(limit.Burst), UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&initial).Error; err != nil {
+			return err
+		}
+
+		// 同じキーへの同時リクエストで二重に消費しないよう行をロックする
+		b := model.RateLimitBucket{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key=?", key).First(&b).Error; err != nil {
+			return err
+		}
+
+		var tokens float64
+		tokens, allowed, wait = refill(b.Tokens, b.UpdatedAt, limit, now)
+		return tx.Model(&b).Where("key=?", key).Updates(map[string]interface{}{
+			"tokens":     tokens,
+			"updated_at": now,
+		}).Error
+	})
+	return allowed, wait, err
+}
+
+// Cleanup はpostgresStoreBucketTTLより長く使われていないバケットを削除する
+func (ps *postgresStore) Cleanup(now time.Time) error {
+	return ps.db.Where("updated_at < ?", now.Add(-postgresStoreBucketTTL)).Delete(&model.RateLimitBucket{}).Error
+}
@@ -0,0 +1,54 @@
+// Package ratelimit はトークンバケットによるリクエスト数の制限を行う
+package ratelimit
+
+import (
+	"math"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Limit はバケットの容量（Burst）と1秒あたりに補充されるトークン数（Rate）
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute は1分あたりn回まで、連続してn回まで許可するLimitを返す
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Store はキーごとのバケットを保持する
+// Takeはトークンを1つ消費できればtrueを、できなければ次に消費できるまでの時間を返す
+// Cleanupは使われなくなったバケットを削除する（定期的に実行する）
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (bool, time.Duration, error)
+	Cleanup(now time.Time) error
+}
+
+// NewStore は環境変数RATE_LIMIT_STOREに応じたStoreを返す
+// postgresの場合は複数のインスタンスでバケットを共有する。未設定の場合はメモリに保持する
+func NewStore(db *gorm.DB) Store {
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		return NewPostgresStore(db)
+	}
+	return NewMemoryStore()
+}
+
+// refill は前回からの経過時間に応じてトークンを補充し、1つ消費した結果を返す
+func refill(tokens float64, updatedAt time.Time, limit Limit, now time.Time) (float64, bool, time.Duration) {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+	}
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	if limit.Rate <= 0 {
+		return tokens, false, time.Hour
+	}
+	wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	return tokens, false, wait
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	ms := NewMemoryStore().(*memoryStore)
+	now := time.Now()
+	limit := PerMinute(1)
+
+	if _, _, err := ms.Take("old", limit, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ms.Take("recent", limit, now.Add(5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ms.Cleanup(now.Add(memoryStoreCleanupInterval + time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ms.buckets["old"]; ok {
+		t.Error("stale bucket was not removed")
+	}
+	if _, ok := ms.buckets["recent"]; !ok {
+		t.Error("recent bucket was removed")
+	}
+}
+
+func TestPostgresStoreCleanup(t *testing.T) {
+	// DryRunで実行されるSQLだけを確認する
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sql string
+	var vars []interface{}
+	db.Callback().Delete().After("gorm:delete").Register("test:capture", func(tx *gorm.DB) {
+		sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	})
+
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := (&postgresStore{db}).Cleanup(now); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sql, `DELETE FROM "rate_limit_buckets" WHERE updated_at < `) {
+		t.Errorf("sql = %s", sql)
+	}
+	if len(vars) != 1 || !vars[0].(time.Time).Equal(now.Add(-postgresStoreBucketTTL)) {
+		t.Errorf("vars = %v", vars)
+	}
+}
@@ -14,6 +14,7 @@ type IBudgetRepository interface {
 	UpdateBudget(budget *model.Budget, userId uint, id uint) error
 	SameYearMonth(userId uint, year string, month string) (*model.Budget, error) //既に設定年月が存在しているか
 	GetBudgetByUserId(budget *model.Budget, userId uint, year string, month string) error
+	GetBudgetsByYear(budgets *[]model.Budget, userId uint, year string) error
 }
 
 type budgetRepository struct {
@@ -25,14 +26,25 @@ func NewBudgetRepository(db *gorm.DB) IBudgetRepository {
 }
 
 func (br *budgetRepository) CreateBudget(budget *model.Budget) error {
-	if err := br.db.Create(budget).Error; err != nil {
-		return err
-	}
-	return nil
+	return br.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(budget).Error; err != nil {
+			return err
+		}
+		return replaceBudgetAmounts(tx, budget.ID, budget.Amounts)
+	})
 }
 
 func (br *budgetRepository) UpdateBudget(budget *model.Budget, userId uint, id uint) error {
-	result := br.db.Model(budget).Clauses(clause.Returning{}).Where("id=? AND user_id=?", id, userId).Updates(map[string]interface{}{
+	return br.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateBudget(tx, budget, userId, id); err != nil {
+			return err
+		}
+		return replaceBudgetAmounts(tx, id, budget.Amounts)
+	})
+}
+
+func updateBudget(tx *gorm.DB, budget *model.Budget, userId uint, id uint) error {
+	result := tx.Model(budget).Clauses(clause.Returning{}).Where("id=? AND user_id=?", id, userId).Updates(map[string]interface{}{
 		"month":           budget.Month,
 		"year":            budget.Year,
 		"total_price":     budget.TotalPrice,
@@ -55,6 +67,40 @@ func (br *budgetRepository) UpdateBudget(budget *model.Budget, userId uint, id u
 	return nil
 }
 
+// replaceBudgetAmounts はカテゴリーごとの予算を入れ替える
+func replaceBudgetAmounts(tx *gorm.DB, budgetId uint, amounts map[string]uint) error {
+	if err := tx.Where("budget_id=?", budgetId).Delete(&model.BudgetAmount{}).Error; err != nil {
+		return err
+	}
+	if len(amounts) == 0 {
+		return nil
+	}
+
+	budgetAmounts := []model.BudgetAmount{}
+	for key, amount := range amounts {
+		budgetAmounts = append(budgetAmounts, model.BudgetAmount{
+			CategoryKey: key,
+			Amount:      amount,
+			BudgetId:    budgetId,
+		})
+	}
+	return tx.Create(&budgetAmounts).Error
+}
+
+// getBudgetAmounts は指定した予算のカテゴリーごとの金額を合計して返す
+func (br *budgetRepository) getBudgetAmounts(budgetIds []uint) (map[string]uint, error) {
+	budgetAmounts := []model.BudgetAmount{}
+	if err := br.db.Where("budget_id IN ?", budgetIds).Find(&budgetAmounts).Error; err != nil {
+		return nil, err
+	}
+
+	amounts := map[string]uint{}
+	for _, v := range budgetAmounts {
+		amounts[v.CategoryKey] += v.Amount
+	}
+	return amounts, nil
+}
+
 func (br *budgetRepository) SameYearMonth(userId uint, year string, month string) (*model.Budget, error) {
 	budget := &model.Budget{}
 
@@ -81,7 +127,9 @@ func (br *budgetRepository) GetBudgetByUserId(budget *model.Budget, userId uint,
 
 		// 全てのレコードの各フィールドの合計を計算
 		var totalBudget model.Budget
+		budgetIds := []uint{}
 		for _, b := range budgets {
+			budgetIds = append(budgetIds, b.ID)
 			totalBudget.TotalPrice += b.TotalPrice
 			totalBudget.Food += b.Food
 			totalBudget.Drink += b.Drink
@@ -94,6 +142,12 @@ func (br *budgetRepository) GetBudgetByUserId(budget *model.Budget, userId uint,
 			totalBudget.Other += b.Other
 		}
 
+		amounts, err := br.getBudgetAmounts(budgetIds)
+		if err != nil {
+			return err
+		}
+		totalBudget.Amounts = amounts
+
 		*budget = totalBudget
 		return nil
 	} else {
@@ -103,6 +157,28 @@ func (br *budgetRepository) GetBudgetByUserId(budget *model.Budget, userId uint,
 			}
 			return err
 		}
+
+		amounts, err := br.getBudgetAmounts([]uint{budget.ID})
+		if err != nil {
+			return err
+		}
+		budget.Amounts = amounts
 		return nil
 	}
 }
+
+// GetBudgetsByYear は指定した年の月ごとの予算を、カテゴリーごとの予算と合わせて取得する
+func (br *budgetRepository) GetBudgetsByYear(budgets *[]model.Budget, userId uint, year string) error {
+	if err := br.db.Where("user_id = ? AND year = ? AND month <> ?", userId, year, "all").Find(budgets).Error; err != nil {
+		return err
+	}
+
+	for i := range *budgets {
+		amounts, err := br.getBudgetAmounts([]uint{(*budgets)[i].ID})
+		if err != nil {
+			return err
+		}
+		(*budgets)[i].Amounts = amounts
+	}
+	return nil
+}
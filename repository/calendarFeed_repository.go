@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICalendarFeedRepository interface {
+	GetFeedByUserId(feed *model.CalendarFeed, userId uint) error
+	GetFeedByToken(feed *model.CalendarFeed, token string) error
+	UpsertFeed(feed *model.CalendarFeed) error
+	GetProductsWithTimeLimit(products *[]model.Product, userId uint) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) ICalendarFeedRepository {
+	return &calendarFeedRepository{db}
+}
+
+// GetFeedByUserId はカレンダーのトークンを取得する。未作成の場合はfeedを変更しない
+func (cfr *calendarFeedRepository) GetFeedByUserId(feed *model.CalendarFeed, userId uint) error {
+	if err := cfr.db.Where("user_id=?", userId).Limit(1).Find(feed).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfr *calendarFeedRepository) GetFeedByToken(feed *model.CalendarFeed, token string) error {
+	if err := cfr.db.Where("token=?", token).First(feed).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpsertFeed はトークンを作成する。既にある場合は新しいトークンに置き換え、古いURLを無効にする
+func (cfr *calendarFeedRepository) UpsertFeed(feed *model.CalendarFeed) error {
+	if err := cfr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(feed).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfr *calendarFeedRepository) GetProductsWithTimeLimit(products *[]model.Product, userId uint) error {
+	if err := cfr.db.Where("user_id=? AND time_limit IS NOT NULL AND archived_at IS NULL", userId).Order("time_limit ASC").Find(products).Error; err != nil {
+		return err
+	}
+	return nil
+}
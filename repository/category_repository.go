@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type ICategoryRepository interface {
+	GetCategories(categories *[]model.Category) error
+	GetCategoryKeys() ([]string, error)
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) ICategoryRepository {
+	return &categoryRepository{db}
+}
+
+func (cr *categoryRepository) GetCategories(categories *[]model.Category) error {
+	return cr.db.Order("position ASC, id ASC").Find(categories).Error
+}
+
+func (cr *categoryRepository) GetCategoryKeys() ([]string, error) {
+	keys := []string{}
+	if err := cr.db.Model(&model.Category{}).Order("position ASC, id ASC").Pluck("key", &keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
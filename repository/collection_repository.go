@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICollectionRepository interface {
+	CreateCollection(collection *model.Collection) error
+	UpdateCollection(collection *model.Collection, userId uint, id uint) error
+	DeleteCollection(userId uint, id uint) error
+	GetMyCollections(collections *[]model.Collection, userId uint) error
+	GetCollectionById(collection *model.Collection, userId uint, id uint) error
+	GetCollectionBySlug(collection *model.Collection, slug string) error
+	CountItems(collectionId uint) (int, error)
+	AddItem(item *model.CollectionItem) error
+	RemoveItem(collectionId uint, postId uint) error
+	GetItemPostIds(collectionId uint) ([]uint, error)
+	UpdateItemPositions(collectionId uint, postIds []uint) error
+}
+
+type collectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) ICollectionRepository {
+	return &collectionRepository{db}
+}
+
+func (cr *collectionRepository) CreateCollection(collection *model.Collection) error {
+	if err := cr.db.Create(collection).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *collectionRepository) UpdateCollection(collection *model.Collection, userId uint, id uint) error {
+	result := cr.db.Model(collection).Clauses(clause.Returning{}).Where("id=? AND user_id=?", id, userId).Updates(map[string]interface{}{
+		"name":        collection.Name,
+		"description": collection.Description,
+		"visibility":  collection.Visibility,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (cr *collectionRepository) DeleteCollection(userId uint, id uint) error {
+	result := cr.db.Where("id=? AND user_id=?", id, userId).Delete(&model.Collection{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (cr *collectionRepository) GetMyCollections(collections *[]model.Collection, userId uint) error {
+	return cr.db.Where("user_id=?", userId).Order("created_at DESC").Find(collections).Error
+}
+
+func (cr *collectionRepository) GetCollectionById(collection *model.Collection, userId uint, id uint) error {
+	if err := cr.db.Where("id=? AND user_id=?", id, userId).First(collection).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *collectionRepository) GetCollectionBySlug(collection *model.Collection, slug string) error {
+	if err := cr.db.Where("share_slug=? AND visibility=?", slug, model.CollectionVisibilityShared).First(collection).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *collectionRepository) CountItems(collectionId uint) (int, error) {
+	var count int64
+	if err := cr.db.Model(&model.CollectionItem{}).Where("collection_id=?", collectionId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// AddItem はコレクションの末尾に投稿を追加する（追加済みの場合は何もしない）
+func (cr *collectionRepository) AddItem(item *model.CollectionItem) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		var maxPosition int
+		if err := tx.Model(&model.CollectionItem{}).Where("collection_id=?", item.CollectionId).
+			Select("COALESCE(MAX(position), -1)").Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		item.Position = maxPosition + 1
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+	})
+}
+
+func (cr *collectionRepository) RemoveItem(collectionId uint, postId uint) error {
+	result := cr.db.Where("collection_id=? AND post_id=?", collectionId, postId).Delete(&model.CollectionItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (cr *collectionRepository) GetItemPostIds(collectionId uint) ([]uint, error) {
+	postIds := []uint{}
+	if err := cr.db.Model(&model.CollectionItem{}).Where("collection_id=?", collectionId).
+		Order("position ASC, id ASC").Pluck("post_id", &postIds).Error; err != nil {
+		return nil, err
+	}
+	return postIds, nil
+}
+
+func (cr *collectionRepository) UpdateItemPositions(collectionId uint, postIds []uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		for i, postId := range postIds {
+			result := tx.Model(&model.CollectionItem{}).Where("collection_id=? AND post_id=?", collectionId, postId).Update("position", i)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected < 1 {
+				return fmt.Errorf("object does not exist")
+			}
+		}
+		return nil
+	})
+}
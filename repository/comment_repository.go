@@ -11,6 +11,7 @@ type ICommentRepository interface {
 	CreateComment(comment *model.Comment) error
 	DeleteComment(userId uint, id uint) error
 	GetCommentsByPostId(comments *[]model.Comment, postId uint, page int, pageSize int) (int, error)
+	GetCommentById(comment *model.Comment, id uint) error
 }
 
 type commentRepository struct {
@@ -43,13 +44,20 @@ func (cr *commentRepository) GetCommentsByPostId(comments *[]model.Comment, post
 	offset := (page - 1) * pageSize
 	var totalCount int64
 
-	if err := cr.db.Where("post_id=?", postId).Model(&model.Comment{}).Count(&totalCount).Error; err != nil {
+	if err := cr.db.Where("post_id=? AND status=?", postId, model.CommentStatusVisible).Model(&model.Comment{}).Count(&totalCount).Error; err != nil {
 		return 0, err
 	}
 
-	if err := cr.db.Where("post_id=?", postId).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(comments).Error; err != nil {
+	if err := cr.db.Where("post_id=? AND status=?", postId, model.CommentStatusVisible).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(comments).Error; err != nil {
 		return 0, err
 	}
 
 	return int(totalCount), nil
 }
+
+func (cr *commentRepository) GetCommentById(comment *model.Comment, id uint) error {
+	if err := cr.db.Where("id=?", id).First(comment).Error; err != nil {
+		return err
+	}
+	return nil
+}
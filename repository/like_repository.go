@@ -57,7 +57,7 @@ func (lr *likeRepository) GetLikeByPostAndUser(postId uint, userId uint) (*model
 func (lr *likeRepository) GetMyLikeCount(userId uint) (int, error) {
 	var totalLikeCount int64
 
-	if err := lr.db.Model(&model.Like{}).Where("user_id=?", userId).Count(&totalLikeCount).Error; err != nil {
+	if err := lr.db.Model(&model.Like{}).Where("user_id=? AND post_id IN (?)", userId, publishedPostIds(lr.db)).Count(&totalLikeCount).Error; err != nil {
 		return 0, err
 	}
 
@@ -67,7 +67,7 @@ func (lr *likeRepository) GetMyLikeCount(userId uint) (int, error) {
 func (lr *likeRepository) GetMyLikePostIdsByUserId(userId uint, page int, pageSize int) ([]uint, error) {
 	likes := []model.Like{}
 	offset := (page - 1) * pageSize
-	if err := lr.db.Where("user_id = ?", userId).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&likes).Error; err != nil {
+	if err := lr.db.Where("user_id = ? AND post_id IN (?)", userId, publishedPostIds(lr.db)).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&likes).Error; err != nil {
 		return nil, err
 	}
 
@@ -79,3 +79,8 @@ func (lr *likeRepository) GetMyLikePostIdsByUserId(userId uint, page int, pageSi
 
 	return postIds, nil
 }
+
+// publishedPostIds は公開済みの投稿IDのサブクエリ（下書き・予約投稿・審査中の投稿は含めない）
+func publishedPostIds(db *gorm.DB) *gorm.DB {
+	return db.Model(&model.ReviewPost{}).Select("id").Where("status=?", model.ReviewPostStatusPublished)
+}
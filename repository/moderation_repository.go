@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IModerationRepository interface {
+	CreateHold(hold *model.ModerationHold) error
+	GetPendingHolds(holds *[]model.ModerationHold, page int, pageSize int) (int, error)
+	GetHoldById(hold *model.ModerationHold, id uint) error
+	ResolveHold(hold *model.ModerationHold, status string, resolvedBy uint) error
+	CountFingerprints(kind string, userId uint, fingerprint string, since time.Time) (int, error)
+	CreateFingerprint(kind string, userId uint, fingerprint string) error
+}
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) IModerationRepository {
+	return &moderationRepository{db}
+}
+
+func (mr *moderationRepository) CreateHold(hold *model.ModerationHold) error {
+	if err := mr.db.Create(hold).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mr *moderationRepository) GetPendingHolds(holds *[]model.ModerationHold, page int, pageSize int) (int, error) {
+	offset := (page - 1) * pageSize
+	var totalCount int64
+
+	if err := mr.db.Model(&model.ModerationHold{}).Where("status=?", model.ModerationHoldStatusPending).Count(&totalCount).Error; err != nil {
+		return 0, err
+	}
+
+	if err := mr.db.Where("status=?", model.ModerationHoldStatusPending).Order("created_at ASC").Offset(offset).Limit(pageSize).Find(holds).Error; err != nil {
+		return 0, err
+	}
+
+	return int(totalCount), nil
+}
+
+func (mr *moderationRepository) GetHoldById(hold *model.ModerationHold, id uint) error {
+	if err := mr.db.Where("id=?", id).First(hold).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResolveHold は確認待ちを解決済みにする。承認した場合は対象の投稿・コメントを公開する
+func (mr *moderationRepository) ResolveHold(hold *model.ModerationHold, status string, resolvedBy uint) error {
+	return mr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(hold).Where("id=? AND status=?", hold.ID, model.ModerationHoldStatusPending).Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": resolvedBy,
+			"resolved_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+
+		if status != model.ModerationHoldStatusApproved {
+			return nil
+		}
+		switch hold.Kind {
+		case moderation.KindReviewPost:
+			// 予約投稿だった場合も承認した時点で公開する
+			return tx.Model(&model.ReviewPost{}).Where("id=? AND status=?", hold.TargetId, model.ReviewPostStatusPending).Updates(map[string]interface{}{
+				"status":       model.ReviewPostStatusPublished,
+				"published_at": gorm.Expr("CASE WHEN published_at IS NULL OR published_at > ? THEN ? ELSE published_at END", now, now),
+			}).Error
+		case moderation.KindComment:
+			return tx.Model(&model.Comment{}).Where("id=?", hold.TargetId).Update("status", model.CommentStatusVisible).Error
+		}
+		return nil
+	})
+}
+
+func (mr *moderationRepository) CountFingerprints(kind string, userId uint, fingerprint string, since time.Time) (int, error) {
+	var count int64
+	if err := mr.db.Model(&model.ContentFingerprint{}).
+		Where("kind=? AND user_id=? AND fingerprint=? AND created_at >= ?", kind, userId, fingerprint, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (mr *moderationRepository) CreateFingerprint(kind string, userId uint, fingerprint string) error {
+	return mr.db.Create(&model.ContentFingerprint{Kind: kind, Fingerprint: fingerprint, UserId: userId}).Error
+}
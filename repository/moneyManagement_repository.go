@@ -11,9 +11,12 @@ import (
 
 type IMoneyManagementRepository interface {
 	CreateMoneyManagement(moneyManagement *model.MoneyManagement) error
+	CreateMoneyManagements(moneyManagements *[]model.MoneyManagement) error
 	UpdateMoneyManagement(moneyManagement *model.MoneyManagement, userId uint, id uint) error
 	DeleteMoneyManagement(userId uint, id uint) error
-	GetMyMoneyManagements(moneyManagement *[]model.MoneyManagement, userId uint, yearMonth time.Time, yearFlag bool) error
+	GetMyMoneyManagements(moneyManagement *[]model.MoneyManagement, userId uint, from time.Time, to time.Time) error
+	EachMyMoneyManagement(userId uint, from time.Time, to time.Time, fn func(moneyManagement model.MoneyManagement) error) error
+	GetMonthlyCategoryTotals(totals *[]model.MoneyManagementMonthlyTotal, userId uint, from time.Time, to time.Time) error
 }
 
 type moneyManagementRepository struct {
@@ -31,14 +34,21 @@ func (mr *moneyManagementRepository) CreateMoneyManagement(moneyManagement *mode
 	return nil
 }
 
+// CreateMoneyManagements は一括登録で、1件でも失敗した場合は全て登録しない
+func (mr *moneyManagementRepository) CreateMoneyManagements(moneyManagements *[]model.MoneyManagement) error {
+	return mr.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(moneyManagements, 100).Error
+	})
+}
+
 func (mr *moneyManagementRepository) UpdateMoneyManagement(moneyManagement *model.MoneyManagement, userId uint, id uint) error {
 	result := mr.db.Model(moneyManagement).Clauses(clause.Returning{}).Where("id=? AND user_id=?", id, userId).Updates(map[string]interface{}{
-		"title":       moneyManagement.Title,
-		"category":    moneyManagement.Category,
-		"unit_price":  moneyManagement.UnitPrice,
-		"quantity":    moneyManagement.Quantity,
-		"total_price": moneyManagement.TotalPrice,
-		"updated_at":  moneyManagement.UpdatedAt,
+		"title":        moneyManagement.Title,
+		"category":     moneyManagement.Category,
+		"unit_price":   moneyManagement.UnitPrice,
+		"quantity":     moneyManagement.Quantity,
+		"total_price":  moneyManagement.TotalPrice,
+		"purchased_at": moneyManagement.PurchasedAt,
 	})
 	if result.Error != nil {
 		return result.Error
@@ -60,23 +70,45 @@ func (mr *moneyManagementRepository) DeleteMoneyManagement(userId uint, id uint)
 	return nil
 }
 
-func (mr *moneyManagementRepository) GetMyMoneyManagements(moneyManagement *[]model.MoneyManagement, userId uint, yearMonth time.Time, yearFlag bool) error {
-	startOfMonth := time.Date(yearMonth.Year(), yearMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+// GetMyMoneyManagements は購入日がfrom以上to未満の家計簿を取得する
+func (mr *moneyManagementRepository) GetMyMoneyManagements(moneyManagement *[]model.MoneyManagement, userId uint, from time.Time, to time.Time) error {
+	if err := mr.db.Where("user_id = ? AND purchased_at >= ? AND purchased_at < ?", userId, from, to).
+		Find(moneyManagement).Error; err != nil {
+		return err
+	}
 
-	if yearFlag {
-		startOfYear := time.Date(yearMonth.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-		endOfYear := startOfYear.AddDate(1, 0, 0)
-		if err := mr.db.Where("user_id = ? AND updated_at >= ? AND updated_at < ?", userId, startOfYear, endOfYear).
-			Find(moneyManagement).Error; err != nil {
+	return nil
+}
+
+// EachMyMoneyManagement は購入日がfrom以上to未満の家計簿を購入日順に1件ずつ読み込んでfnに渡す（全件をメモリに載せない）
+func (mr *moneyManagementRepository) EachMyMoneyManagement(userId uint, from time.Time, to time.Time, fn func(moneyManagement model.MoneyManagement) error) error {
+	rows, err := mr.db.Model(&model.MoneyManagement{}).
+		Where("user_id = ? AND purchased_at >= ? AND purchased_at < ?", userId, from, to).
+		Order("purchased_at ASC, id ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		moneyManagement := model.MoneyManagement{}
+		if err := mr.db.ScanRows(rows, &moneyManagement); err != nil {
 			return err
 		}
-	} else {
-		if err := mr.db.Where("user_id = ? AND updated_at >= ? AND updated_at < ?", userId, startOfMonth, endOfMonth).
-			Find(moneyManagement).Error; err != nil {
+		if err := fn(moneyManagement); err != nil {
 			return err
 		}
 	}
+	return rows.Err()
+}
 
-	return nil
+// GetMonthlyCategoryTotals は購入日がfrom以上to未満の家計簿の、購入月・カテゴリーごとの合計を取得する
+func (mr *moneyManagementRepository) GetMonthlyCategoryTotals(totals *[]model.MoneyManagementMonthlyTotal, userId uint, from time.Time, to time.Time) error {
+	return mr.db.Model(&model.MoneyManagement{}).
+		Select("to_char(purchased_at, 'YYYY-MM') AS month, category, SUM(total_price) AS total_price").
+		Where("user_id = ? AND purchased_at >= ? AND purchased_at < ?", userId, from, to).
+		Group("month, category").
+		Order("month ASC, category ASC").
+		Scan(totals).Error
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	GetSetting(setting *model.NotificationSetting, userId uint) error
+	UpsertSetting(setting *model.NotificationSetting) error
+	GetRecipient(recipient *model.NotificationRecipient, userId uint) error
+	GetReminderTargets(targets *[]model.ProductReminderTarget, from time.Time, to time.Time) error
+	ClaimDelivery(delivery *model.ReminderDelivery) (bool, error)
+	ReleaseDelivery(delivery *model.ReminderDelivery) error
+	CreateNotification(notification *model.Notification) error
+	GetNotifications(notifications *[]model.Notification, userId uint, page int, pageSize int) (int, error)
+	MarkAsRead(userId uint, id uint) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) INotificationRepository {
+	return &notificationRepository{db}
+}
+
+// GetSetting は通知設定を取得する。未設定の場合はsettingを変更しない
+func (nr *notificationRepository) GetSetting(setting *model.NotificationSetting, userId uint) error {
+	if err := nr.db.Where("user_id=?", userId).Limit(1).Find(setting).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) UpsertSetting(setting *model.NotificationSetting) error {
+	if err := nr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_enabled", "in_app_enabled", "webhook_url", "lead_minutes", "updated_at"}),
+	}).Create(setting).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRecipient はユーザーの通知の送信先を通知設定と合わせて取得する
+func (nr *notificationRepository) GetRecipient(recipient *model.NotificationRecipient, userId uint) error {
+	if err := nr.db.Table("users").
+		Select("users.id AS user_id, users.email, users.time_zone, notification_settings.email_enabled, notification_settings.in_app_enabled, notification_settings.webhook_url").
+		Joins("LEFT JOIN notification_settings ON notification_settings.user_id = users.id").
+		Where("users.id = ?", userId).
+		Take(recipient).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetReminderTargets は期限がfromより後、to以前の商品を通知設定と合わせて取得する
+func (nr *notificationRepository) GetReminderTargets(targets *[]model.ProductReminderTarget, from time.Time, to time.Time) error {
+	if err := nr.db.Table("products").
+		Select("products.id AS product_id, products.name AS product_name, products.time_limit, products.user_id, users.email, users.time_zone, notification_settings.email_enabled, notification_settings.in_app_enabled, notification_settings.webhook_url, notification_settings.lead_minutes").
+		Joins("JOIN users ON users.id = products.user_id").
+		Joins("LEFT JOIN notification_settings ON notification_settings.user_id = products.user_id").
+		Where("products.time_limit > ? AND products.time_limit <= ? AND products.archived_at IS NULL", from, to).
+		Order("products.time_limit ASC").
+		Scan(targets).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimDelivery は送信記録を作成する。既に同じ送信記録がある場合はfalseを返す
+func (nr *notificationRepository) ClaimDelivery(delivery *model.ReminderDelivery) (bool, error) {
+	result := nr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseDelivery は送信に失敗した場合に送信記録を削除し、次回再送できるようにする
+func (nr *notificationRepository) ReleaseDelivery(delivery *model.ReminderDelivery) error {
+	if err := nr.db.Where("id=?", delivery.ID).Delete(&model.ReminderDelivery{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) CreateNotification(notification *model.Notification) error {
+	if err := nr.db.Create(notification).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) GetNotifications(notifications *[]model.Notification, userId uint, page int, pageSize int) (int, error) {
+	offset := (page - 1) * pageSize
+	var totalCount int64
+
+	if err := nr.db.Model(&model.Notification{}).Where("user_id=?", userId).Count(&totalCount).Error; err != nil {
+		return 0, err
+	}
+
+	if err := nr.db.Where("user_id=?", userId).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(notifications).Error; err != nil {
+		return 0, err
+	}
+
+	return int(totalCount), nil
+}
+
+func (nr *notificationRepository) MarkAsRead(userId uint, id uint) error {
+	result := nr.db.Model(&model.Notification{}).Where("id=? AND user_id=? AND read_at IS NULL", id, userId).Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
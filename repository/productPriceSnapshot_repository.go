@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IProductPriceSnapshotRepository interface {
+	RecordSnapshot(snapshot *model.ProductPriceSnapshot) (bool, error)
+	GetSnapshots(snapshots *[]model.ProductPriceSnapshot, userId uint, productId uint, from *time.Time, to *time.Time) error
+	UpdateTargetPrice(product *model.Product, userId uint, productId uint) error
+}
+
+type productPriceSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewProductPriceSnapshotRepository(db *gorm.DB) IProductPriceSnapshotRepository {
+	return &productPriceSnapshotRepository{db}
+}
+
+// RecordSnapshot は価格の記録を追加し、最新の記録の価格・在庫・評価を商品に反映する
+// 過去の日時の記録を追加した場合は商品の値は変更せず、falseを返す
+func (psr *productPriceSnapshotRepository) RecordSnapshot(snapshot *model.ProductPriceSnapshot) (bool, error) {
+	applied := false
+	err := psr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(snapshot).Error; err != nil {
+			return err
+		}
+
+		latest := model.ProductPriceSnapshot{}
+		if err := tx.Where("product_id=?", snapshot.ProductId).Order("observed_at DESC, id DESC").First(&latest).Error; err != nil {
+			return err
+		}
+		if latest.ID != snapshot.ID {
+			return nil
+		}
+
+		result := tx.Model(&model.Product{}).Where("id=? AND user_id=?", snapshot.ProductId, snapshot.UserId).Updates(map[string]interface{}{
+			"price":  snapshot.Price,
+			"stock":  snapshot.Stock,
+			"review": snapshot.Review,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+func (psr *productPriceSnapshotRepository) GetSnapshots(snapshots *[]model.ProductPriceSnapshot, userId uint, productId uint, from *time.Time, to *time.Time) error {
+	query := psr.db.Where("product_id=? AND user_id=?", productId, userId)
+	if from != nil {
+		query = query.Where("observed_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("observed_at < ?", *to)
+	}
+	if err := query.Order("observed_at ASC, id ASC").Find(snapshots).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (psr *productPriceSnapshotRepository) UpdateTargetPrice(product *model.Product, userId uint, productId uint) error {
+	result := psr.db.Model(product).Where("id=? AND user_id=?", productId, userId).Update("target_price", product.TargetPrice)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
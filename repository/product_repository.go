@@ -11,12 +11,24 @@ import (
 
 type IProductRepository interface {
 	CreateProduct(product *model.Product) error
-	UpdateTimeLimit(product *model.Product, userId uint, productId uint) error
+	UpdateProduct(product *model.Product, userId uint, productId uint) error
+	UpdateTimeLimit(product *model.Product, userId uint, productId uint, history *model.ProductHistory) error
+	GetProductById(product *model.Product, userId uint, productId uint) error
+	PurchaseProduct(product *model.Product, moneyManagement *model.MoneyManagement, after string) error
+	GetReviewCategory(provider string, code string) (string, error)
+	GetHistories(histories *[]model.ProductHistory, userId uint, productId uint) error
 	DeleteProduct(userId uint, productId uint) error
-	GetMyProducts(product *[]model.Product, userId uint, page int, pageSize int) (int, error)
+	DeleteProducts(userId uint, productIds []uint) (int, error)
+	ExistsProduct(userId uint, provider string, code string, excludeId uint) (bool, error)
+	GetMyProducts(product *[]model.Product, userId uint, page int, pageSize int, filter model.ProductDeadlineFilter, archived bool) (int, error)
 	GetMyProductsTimeLimitAll(product *[]model.Product, userId uint, page int, pageSize int, sort bool) (int, error)
-	GetMyProductsTimeLimitYearMonth(product *[]model.Product, userId uint, yearMonth time.Time) error
-	GetMyProductsTimeLimitDate(product *[]model.Product, userId uint, page int, pageSize int, date time.Time) (int, error)
+	GetMyProductsTimeLimitYearMonth(product *[]model.Product, userId uint, from time.Time, to time.Time) error
+	GetMyProductsTimeLimitDate(product *[]model.Product, userId uint, page int, pageSize int, from time.Time, to time.Time) (int, error)
+	GetWishlistItems(items *[]model.WishlistItem, userId uint, wishlistId uint) error
+	AddWishlistItem(item *model.WishlistItem) error
+	RemoveWishlistItem(userId uint, wishlistId uint, productId uint) error
+	MarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error
+	UnmarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error
 }
 
 type productRepository struct {
@@ -27,19 +39,38 @@ func NewProductRepository(db *gorm.DB) IProductRepository {
 	return &productRepository{db}
 }
 
+// CreateProduct は商品と、保存時の価格を最初の記録として同じトランザクションで追加する
 func (pr *productRepository) CreateProduct(product *model.Product) error {
-	if err := pr.db.Create(product).Error; err != nil {
-		return err
-	}
-	return nil
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		snapshot := model.ProductPriceSnapshot{
+			Price:      product.Price,
+			Stock:      product.Stock,
+			Review:     product.Review,
+			Source:     model.ProductPriceSourceInitial,
+			ObservedAt: product.CreatedAt,
+			ProductId:  product.ID,
+			UserId:     product.UserId,
+		}
+		return tx.Create(&snapshot).Error
+	})
 }
 
-func (pr *productRepository) UpdateTimeLimit(product *model.Product, userId uint, productId uint) error {
-	// 1日後に更新
-	product.TimeLimit = product.TimeLimit.Add(24 * time.Hour)
-
+func (pr *productRepository) UpdateProduct(product *model.Product, userId uint, productId uint) error {
 	result := pr.db.Model(product).Clauses(clause.Returning{}).Where("id=? AND user_id=?", productId, userId).Updates(map[string]interface{}{
-		"time_limit": product.TimeLimit,
+		"name":        product.Name,
+		"description": product.Description,
+		"stock":       product.Stock,
+		"price":       product.Price,
+		"review":      product.Review,
+		"url":         product.Url,
+		"image":       product.Image,
+		"code":        product.Code,
+		"provider":    product.Provider,
+		"notes":       product.Notes,
+		"priority":    product.Priority,
 	})
 	if result.Error != nil {
 		return result.Error
@@ -50,6 +81,81 @@ func (pr *productRepository) UpdateTimeLimit(product *model.Product, userId uint
 	return nil
 }
 
+// UpdateTimeLimit は期限を更新し、変更履歴を記録する
+func (pr *productRepository) UpdateTimeLimit(product *model.Product, userId uint, productId uint, history *model.ProductHistory) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(product).Clauses(clause.Returning{}).Where("id=? AND user_id=?", productId, userId).Updates(map[string]interface{}{
+			"time_limit": product.TimeLimit,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+		return tx.Create(history).Error
+	})
+}
+
+func (pr *productRepository) GetProductById(product *model.Product, userId uint, productId uint) error {
+	if err := pr.db.Where("id=? AND user_id=?", productId, userId).First(product).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurchaseProduct は商品から家計簿を登録し、afterに応じて商品を残す・アーカイブする・削除する
+func (pr *productRepository) PurchaseProduct(product *model.Product, moneyManagement *model.MoneyManagement, after string) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(moneyManagement).Error; err != nil {
+			return err
+		}
+
+		var result *gorm.DB
+		switch after {
+		case model.ProductPurchaseRemove:
+			result = tx.Where("id=? AND user_id=?", product.ID, product.UserId).Delete(&model.Product{})
+		case model.ProductPurchaseArchive:
+			result = tx.Model(product).Clauses(clause.Returning{}).Where("id=? AND user_id=?", product.ID, product.UserId).Updates(map[string]interface{}{
+				"purchased_at": product.PurchasedAt,
+				"archived_at":  time.Now(),
+			})
+		default:
+			result = tx.Model(product).Clauses(clause.Returning{}).Where("id=? AND user_id=?", product.ID, product.UserId).Updates(map[string]interface{}{
+				"purchased_at": product.PurchasedAt,
+			})
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+		return nil
+	})
+}
+
+// GetReviewCategory は同じ商品（provider, code）のレビューで最も多いカテゴリーを返す（レビューがない場合は空文字）
+func (pr *productRepository) GetReviewCategory(provider string, code string) (string, error) {
+	var categories []string
+	if err := pr.db.Model(&model.ReviewPost{}).
+		Where("product_provider=? AND product_code=? AND status=?", provider, code, model.ReviewPostStatusPublished).
+		Group("category").
+		Order("COUNT(*) DESC, category ASC").
+		Limit(1).
+		Pluck("category", &categories).Error; err != nil {
+		return "", err
+	}
+	if len(categories) == 0 {
+		return "", nil
+	}
+	return categories[0], nil
+}
+
+func (pr *productRepository) GetHistories(histories *[]model.ProductHistory, userId uint, productId uint) error {
+	return pr.db.Where("product_id=? AND user_id=?", productId, userId).Order("created_at DESC, id DESC").Find(histories).Error
+}
+
 func (pr *productRepository) DeleteProduct(userId uint, productId uint) error {
 	result := pr.db.Where("id=? AND user_id=?", productId, userId).Delete(&model.Product{})
 	if result.Error != nil {
@@ -61,31 +167,76 @@ func (pr *productRepository) DeleteProduct(userId uint, productId uint) error {
 	return nil
 }
 
-func (pr *productRepository) GetMyProducts(product *[]model.Product, userId uint, page int, pageSize int) (int, error) {
+// DeleteProducts は指定した商品のうち自分の商品を削除し、削除した件数を返す
+func (pr *productRepository) DeleteProducts(userId uint, productIds []uint) (int, error) {
+	result := pr.db.Where("id IN ? AND user_id=?", productIds, userId).Delete(&model.Product{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ExistsProduct は同じ商品（provider, code）が既に保存されているかを返す（excludeIdの商品は除く）
+func (pr *productRepository) ExistsProduct(userId uint, provider string, code string, excludeId uint) (bool, error) {
+	var count int64
+	if err := pr.db.Model(&model.Product{}).
+		Where("user_id=? AND provider=? AND code=? AND id<>?", userId, provider, code, excludeId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetMyProducts は保存した商品を取得する。archivedがtrueの場合はアーカイブした商品のみ取得する
+func (pr *productRepository) GetMyProducts(product *[]model.Product, userId uint, page int, pageSize int, filter model.ProductDeadlineFilter, archived bool) (int, error) {
 	offset := (page - 1) * pageSize
 	var totalCount int64
 
-	if err := pr.db.Model(&model.Product{}).Where("user_id=?", userId).Count(&totalCount).Error; err != nil {
+	if err := pr.db.Model(&model.Product{}).Where("user_id=?", userId).Scopes(deadlineScope(filter), archivedScope(archived)).Count(&totalCount).Error; err != nil {
 		return 0, err
 	}
 
-	if err := pr.db.Joins("User").Where("user_id=?", userId).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(product).Error; err != nil {
+	if err := pr.db.Joins("User").Where("user_id=?", userId).Scopes(deadlineScope(filter), archivedScope(archived)).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(product).Error; err != nil {
 		return 0, err
 	}
 	return int(totalCount), nil
 }
 
+// deadlineScope は期限による絞り込みの条件を追加する
+func deadlineScope(filter model.ProductDeadlineFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch filter.Deadline {
+		case model.ProductDeadlineWith:
+			return db.Where("products.time_limit IS NOT NULL")
+		case model.ProductDeadlineWithout:
+			return db.Where("products.time_limit IS NULL")
+		case model.ProductDeadlineOverdue:
+			return db.Where("products.time_limit < ?", filter.Now)
+		case model.ProductDeadlineDueWithin:
+			return db.Where("products.time_limit >= ? AND products.time_limit < ?", filter.Now, filter.Until)
+		}
+		return db
+	}
+}
+
+func archivedScope(archived bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if archived {
+			return db.Where("products.archived_at IS NOT NULL")
+		}
+		return db.Where("products.archived_at IS NULL")
+	}
+}
+
 func (pr *productRepository) GetMyProductsTimeLimitAll(product *[]model.Product, userId uint, page int, pageSize int, sort bool) (int, error) {
 	offset := (page - 1) * pageSize
 	var totalCount int64
 
-	minimumTime := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
-
-	if err := pr.db.Model(&model.Product{}).Where("user_id=? AND time_limit >= ?", userId, minimumTime).Count(&totalCount).Error; err != nil {
+	if err := pr.db.Model(&model.Product{}).Where("user_id=? AND time_limit IS NOT NULL AND archived_at IS NULL", userId).Count(&totalCount).Error; err != nil {
 		return 0, err
 	}
 
-	query := pr.db.Where("user_id=? AND time_limit >= ?", userId, minimumTime)
+	query := pr.db.Where("user_id=? AND time_limit IS NOT NULL AND archived_at IS NULL", userId)
 
 	if sort {
 		query = query.Order("time_limit ASC")
@@ -100,16 +251,11 @@ func (pr *productRepository) GetMyProductsTimeLimitAll(product *[]model.Product,
 	return int(totalCount), nil
 }
 
-func (pr *productRepository) GetMyProductsTimeLimitYearMonth(product *[]model.Product, userId uint, yearMonth time.Time) error {
-
-	startOfMonth := yearMonth.UTC()
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
-
-	// 年月が一致しているものを取得（同じ日にちは、統一している）
-	if err := pr.db.Table("products").
-		Select("DISTINCT ON (DATE_TRUNC('day', time_limit)) *").
-		Where("user_id = ? AND time_limit >= ? AND time_limit < ?", userId, startOfMonth, endOfMonth).
-		Order("DATE_TRUNC('day', time_limit), created_at DESC").
+// GetMyProductsTimeLimitYearMonth は期限がfrom以上to未満の商品を取得する
+// 月の境界はユーザーのタイムゾーンで計算したものを受け取る
+func (pr *productRepository) GetMyProductsTimeLimitYearMonth(product *[]model.Product, userId uint, from time.Time, to time.Time) error {
+	if err := pr.db.Where("user_id = ? AND time_limit >= ? AND time_limit < ? AND archived_at IS NULL", userId, from, to).
+		Order("time_limit ASC, created_at DESC").
 		Find(product).Error; err != nil {
 		return err
 	}
@@ -117,19 +263,106 @@ func (pr *productRepository) GetMyProductsTimeLimitYearMonth(product *[]model.Pr
 	return nil
 }
 
-func (pr *productRepository) GetMyProductsTimeLimitDate(product *[]model.Product, userId uint, page int, pageSize int, date time.Time) (int, error) {
+// GetMyProductsTimeLimitDate は期限がfrom以上to未満の商品を取得する
+// 日の境界はユーザーのタイムゾーンで計算したものを受け取る
+func (pr *productRepository) GetMyProductsTimeLimitDate(product *[]model.Product, userId uint, page int, pageSize int, from time.Time, to time.Time) (int, error) {
 	offset := (page - 1) * pageSize
 	var totalCount int64
 
-	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-
-	if err := pr.db.Model(&model.Product{}).Where("user_id=? AND DATE(time_limit)=?", userId, date).Count(&totalCount).Error; err != nil {
+	if err := pr.db.Model(&model.Product{}).Where("user_id=? AND time_limit >= ? AND time_limit < ? AND archived_at IS NULL", userId, from, to).Count(&totalCount).Error; err != nil {
 		return 0, err
 	}
 
-	if err := pr.db.Where("user_id=? AND DATE(time_limit)=?", userId, date).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(product).Error; err != nil {
+	if err := pr.db.Where("user_id=? AND time_limit >= ? AND time_limit < ? AND archived_at IS NULL", userId, from, to).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(product).Error; err != nil {
 		return 0, err
 	}
 
 	return int(totalCount), nil
 }
+
+// wishlistIdsOf は共有リストの権限の確認に使う、ユーザーが指定した権限で参加しているリストのIDのサブクエリを返す
+// 承認前の招待では閲覧も含めて何もできない
+func (pr *productRepository) wishlistIdsOf(userId uint, roles ...string) *gorm.DB {
+	return pr.db.Model(&model.WishlistMember{}).Select("wishlist_id").
+		Where("user_id=? AND accepted_at IS NOT NULL AND role IN ?", userId, roles)
+}
+
+// GetWishlistItems は共有リストの商品を取得する（リストのメンバーのみ）
+func (pr *productRepository) GetWishlistItems(items *[]model.WishlistItem, userId uint, wishlistId uint) error {
+	return pr.db.Preload("Product").Preload("Buyer").
+		Where("wishlist_id=? AND wishlist_id IN (?)", wishlistId, pr.wishlistIdsOf(userId, model.WishlistRoleOwner, model.WishlistRoleEditor, model.WishlistRoleViewer)).
+		Order("created_at ASC, id ASC").
+		Find(items).Error
+}
+
+// AddWishlistItem は自分の保存した商品を共有リストに追加する（ownerとeditorのみ、追加済みの場合は何もしない）
+func (pr *productRepository) AddWishlistItem(item *model.WishlistItem) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Product{}).
+			Where("id=? AND user_id=?", item.ProductId, item.AddedBy).
+			Where("EXISTS (?)", pr.wishlistIdsOf(item.AddedBy, model.WishlistRoleOwner, model.WishlistRoleEditor).Where("wishlist_id=?", item.WishlistId)).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+	})
+}
+
+// RemoveWishlistItem は共有リストから商品を外す（ownerとeditorのみ）
+func (pr *productRepository) RemoveWishlistItem(userId uint, wishlistId uint, productId uint) error {
+	result := pr.db.
+		Where("wishlist_id=? AND product_id=? AND wishlist_id IN (?)", wishlistId, productId, pr.wishlistIdsOf(userId, model.WishlistRoleOwner, model.WishlistRoleEditor)).
+		Delete(&model.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+// MarkWishlistItemBought は商品を「自分が購入」にする（リストのメンバーのみ）
+// 他のメンバーが既に購入済みにしている場合は上書きしない
+func (pr *productRepository) MarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error {
+	result := pr.db.Model(&model.WishlistItem{}).
+		Where("wishlist_id=? AND product_id=? AND wishlist_id IN (?)", wishlistId, productId, pr.wishlistIdsOf(userId, model.WishlistRoleOwner, model.WishlistRoleEditor, model.WishlistRoleViewer)).
+		Where("(bought_by IS NULL OR bought_by=?)", userId).
+		Updates(map[string]interface{}{
+			"bought_by": userId,
+			"bought_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist or already bought")
+	}
+	return nil
+}
+
+// UnmarkWishlistItemBought は購入済みを取り消す（購入したメンバー本人とownerのみ）
+func (pr *productRepository) UnmarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error {
+	result := pr.db.Model(&model.WishlistItem{}).
+		Where("wishlist_id=? AND product_id=? AND bought_by IS NOT NULL", wishlistId, productId).
+		Where("((bought_by=? AND wishlist_id IN (?)) OR wishlist_id IN (?))",
+			userId,
+			pr.wishlistIdsOf(userId, model.WishlistRoleOwner, model.WishlistRoleEditor, model.WishlistRoleViewer),
+			pr.wishlistIdsOf(userId, model.WishlistRoleOwner),
+		).
+		Updates(map[string]interface{}{
+			"bought_by": nil,
+			"bought_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
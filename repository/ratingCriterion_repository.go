@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type IRatingCriterionRepository interface {
+	GetCriteriaByCategory(criteria *[]model.RatingCriterion, category string) error
+}
+
+type ratingCriterionRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingCriterionRepository(db *gorm.DB) IRatingCriterionRepository {
+	return &ratingCriterionRepository{db}
+}
+
+func (cr *ratingCriterionRepository) GetCriteriaByCategory(criteria *[]model.RatingCriterion, category string) error {
+	return cr.db.Where("category=?", category).Order("position ASC, id ASC").Find(criteria).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type IReviewPostImageRepository interface {
+	CreateImages(images *[]model.ReviewPostImage) error
+	DeleteImage(postId uint, id uint) error
+	GetImageById(image *model.ReviewPostImage, postId uint, id uint) error
+	GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error
+	GetMaxPosition(postId uint) (int, error)
+	UpdatePositions(postId uint, imageIds []uint) error
+}
+
+type reviewPostImageRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewPostImageRepository(db *gorm.DB) IReviewPostImageRepository {
+	return &reviewPostImageRepository{db}
+}
+
+// CreateImages は複数の画像をまとめて登録する（1件でも失敗した場合は全て登録しない）
+func (ir *reviewPostImageRepository) CreateImages(images *[]model.ReviewPostImage) error {
+	if len(*images) == 0 {
+		return nil
+	}
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(images).Error
+	})
+}
+
+func (ir *reviewPostImageRepository) DeleteImage(postId uint, id uint) error {
+	result := ir.db.Where("id=? AND post_id=?", id, postId).Delete(&model.ReviewPostImage{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (ir *reviewPostImageRepository) GetImageById(image *model.ReviewPostImage, postId uint, id uint) error {
+	if err := ir.db.Where("id=? AND post_id=?", id, postId).First(image).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *reviewPostImageRepository) GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error {
+	return ir.db.Where("post_id=?", postId).Order("position ASC, id ASC").Find(images).Error
+}
+
+func (ir *reviewPostImageRepository) GetMaxPosition(postId uint) (int, error) {
+	var maxPosition int
+	if err := ir.db.Model(&model.ReviewPostImage{}).Where("post_id=?", postId).
+		Select("COALESCE(MAX(position), -1)").Scan(&maxPosition).Error; err != nil {
+		return 0, err
+	}
+	return maxPosition, nil
+}
+
+func (ir *reviewPostImageRepository) UpdatePositions(postId uint, imageIds []uint) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range imageIds {
+			result := tx.Model(&model.ReviewPostImage{}).Where("id=? AND post_id=?", id, postId).Update("position", i)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected < 1 {
+				return fmt.Errorf("object does not exist")
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IReviewPostScoreRepository interface {
+	GetActivities(activities *[]model.ReviewPostActivity, since time.Time) error
+	ReplaceScores(period string, scores []model.ReviewPostScore) error
+	GetRankedPostIds(period string, category string, page int, pageSize int) ([]uint, int, error)
+}
+
+type reviewPostScoreRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewPostScoreRepository(db *gorm.DB) IReviewPostScoreRepository {
+	return &reviewPostScoreRepository{db}
+}
+
+// GetActivities はsince以降に公開された投稿ごとに、since以降のいいね数とコメント数を返す
+func (rsr *reviewPostScoreRepository) GetActivities(activities *[]model.ReviewPostActivity, since time.Time) error {
+	likes := rsr.db.Model(&model.Like{}).Select("post_id, COUNT(*) AS count").Where("created_at >= ?", since).Group("post_id")
+	comments := rsr.db.Model(&model.Comment{}).Select("post_id, COUNT(*) AS count").Where("created_at >= ? AND status=?", since, model.CommentStatusVisible).Group("post_id")
+
+	return rsr.db.Table("review_posts").
+		Select("review_posts.id AS post_id, review_posts.category, review_posts.published_at, COALESCE(l.count, 0) AS likes, COALESCE(c.count, 0) AS comments").
+		Joins("LEFT JOIN (?) AS l ON l.post_id = review_posts.id", likes).
+		Joins("LEFT JOIN (?) AS c ON c.post_id = review_posts.id", comments).
+		Where("review_posts.status=? AND review_posts.published_at >= ?", model.ReviewPostStatusPublished, since).
+		Scan(activities).Error
+}
+
+// ReplaceScores は集計期間のスコアを全て入れ替える
+func (rsr *reviewPostScoreRepository) ReplaceScores(period string, scores []model.ReviewPostScore) error {
+	return rsr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("period=?", period).Delete(&model.ReviewPostScore{}).Error; err != nil {
+			return err
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&scores, 500).Error
+	})
+}
+
+// GetRankedPostIds はスコアの高い順に投稿IDを返す（categoryがallの場合は全カテゴリー）
+func (rsr *reviewPostScoreRepository) GetRankedPostIds(period string, category string, page int, pageSize int) ([]uint, int, error) {
+	offset := (page - 1) * pageSize
+	var totalCount int64
+
+	query := func() *gorm.DB {
+		q := rsr.db.Model(&model.ReviewPostScore{}).
+			Joins("JOIN review_posts ON review_posts.id = review_post_scores.post_id").
+			Where("review_post_scores.period=? AND review_posts.status=?", period, model.ReviewPostStatusPublished)
+		if category != "all" {
+			q = q.Where("review_post_scores.category=?", category)
+		}
+		return q
+	}
+
+	if err := query().Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	postIds := []uint{}
+	if err := query().Order("review_post_scores.score DESC, review_posts.published_at DESC").
+		Offset(offset).Limit(pageSize).Pluck("review_post_scores.post_id", &postIds).Error; err != nil {
+		return nil, 0, err
+	}
+	return postIds, int(totalCount), nil
+}
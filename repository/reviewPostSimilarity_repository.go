@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type IReviewPostSimilarityRepository interface {
+	GetSimilarities(similarities *[]model.ReviewPostSimilarity, postId uint) error
+	ReplaceSimilarities(postId uint, similarities []model.ReviewPostSimilarity) error
+	GetCandidates(reviewPosts *[]model.ReviewPost, limit int) error
+	GetTagsByPostIds(postIds []uint) (map[uint][]string, error)
+}
+
+type reviewPostSimilarityRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewPostSimilarityRepository(db *gorm.DB) IReviewPostSimilarityRepository {
+	return &reviewPostSimilarityRepository{db}
+}
+
+func (rsr *reviewPostSimilarityRepository) GetSimilarities(similarities *[]model.ReviewPostSimilarity, postId uint) error {
+	return rsr.db.Where("post_id=?", postId).Order("score DESC").Find(similarities).Error
+}
+
+func (rsr *reviewPostSimilarityRepository) ReplaceSimilarities(postId uint, similarities []model.ReviewPostSimilarity) error {
+	return rsr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id=?", postId).Delete(&model.ReviewPostSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.Create(&similarities).Error
+	})
+}
+
+// GetCandidates は類似度を計算する対象として、新しい順に公開済みの投稿を返す
+func (rsr *reviewPostSimilarityRepository) GetCandidates(reviewPosts *[]model.ReviewPost, limit int) error {
+	return rsr.db.Where("status=?", model.ReviewPostStatusPublished).Order("published_at DESC").Limit(limit).Find(reviewPosts).Error
+}
+
+func (rsr *reviewPostSimilarityRepository) GetTagsByPostIds(postIds []uint) (map[uint][]string, error) {
+	rows := []struct {
+		PostId uint
+		Name   string
+	}{}
+	if err := rsr.db.Table("review_post_tags").
+		Select("review_post_tags.post_id, tags.name").
+		Joins("JOIN tags ON tags.id = review_post_tags.tag_id").
+		Where("review_post_tags.post_id IN ?", postIds).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	tags := map[uint][]string{}
+	for _, v := range rows {
+		tags[v.PostId] = append(tags[v.PostId], v.Name)
+	}
+	return tags, nil
+}
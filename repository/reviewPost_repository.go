@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"merchandise-review-list-backend/model"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -18,6 +20,14 @@ type IReviewPostRepository interface {
 	GetReviewPostLists(reviewPost *[]model.ReviewPost, category string, page int, pageSize int) (int, error)
 	GetLikesByPostId(likes *[]model.Like, postId uint) error
 	GetCommentsByPostId(comments *[]model.Comment, postId uint) error
+	GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error
+	GetReviewPostsByProduct(reviewPost *[]model.ReviewPost, provider string, code string, page int, pageSize int) (int, error)
+	GetProductReviewAggregate(aggregate *model.ProductReviewAggregateResponse, provider string, code string) error
+	GetProductReviewAggregates(keys []model.ProductKey) (map[model.ProductKey]model.ProductReviewAggregateResponse, error)
+	GetRevisionsByPostId(revisions *[]model.ReviewPostRevision, postId uint) error
+	PublishScheduledReviewPosts(now time.Time) (int, error)
+	GetRatingsByPostId(ratings *[]model.ReviewPostRatingResponse, postId uint) error
+	GetTagsByPostId(postId uint) ([]string, error)
 }
 
 type reviewPostRepository struct {
@@ -29,27 +39,139 @@ func NewPostRepository(db *gorm.DB) IReviewPostRepository {
 }
 
 func (rr *reviewPostRepository) CreateReviewPost(reviewPost *model.ReviewPost) error {
-	if err := rr.db.Create(reviewPost).Error; err != nil {
-		return err
-	}
-	return nil
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reviewPost).Error; err != nil {
+			return err
+		}
+		if err := replaceReviewPostRatings(tx, reviewPost.ID, reviewPost.Ratings); err != nil {
+			return err
+		}
+		return replaceReviewPostTags(tx, reviewPost.ID, reviewPost.Tags)
+	})
 }
 
 func (rr *reviewPostRepository) UpdateReviewPost(reviewPost *model.ReviewPost, userId uint, postId uint) error {
-	result := rr.db.Model(reviewPost).Clauses(clause.Returning{}).Where("id=? AND user_id=?", postId, userId).Updates(map[string]interface{}{
-		"title":    reviewPost.Title,
-		"text":     reviewPost.Text,
-		"image":    reviewPost.Image,
-		"review":   reviewPost.Review,
-		"category": reviewPost.Category,
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		current := model.ReviewPost{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id=? AND user_id=?", postId, userId).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("object does not exist")
+			}
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"title":            reviewPost.Title,
+			"text":             reviewPost.Text,
+			"image":            reviewPost.Image,
+			"review":           reviewPost.Review,
+			"category":         reviewPost.Category,
+			"product_provider": reviewPost.ProductProvider,
+			"product_code":     reviewPost.ProductCode,
+			"status":           reviewPost.Status,
+			"published_at":     reviewPost.PublishedAt,
+		}
+
+		// 公開済みの投稿の内容が変わる場合のみ更新前の内容を履歴として残す
+		if current.Status == model.ReviewPostStatusPublished && reviewPostContentChanged(current, *reviewPost) {
+			revision := model.ReviewPostRevision{
+				Title:           current.Title,
+				Text:            current.Text,
+				Image:           current.Image,
+				Review:          current.Review,
+				Category:        current.Category,
+				ProductProvider: current.ProductProvider,
+				ProductCode:     current.ProductCode,
+				PostId:          current.ID,
+			}
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+			updates["edited_at"] = revision.CreatedAt
+		}
+
+		ratings := reviewPost.Ratings
+		tags := reviewPost.Tags
+		result := tx.Model(reviewPost).Clauses(clause.Returning{}).Where("id=? AND user_id=?", postId, userId).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+
+		// 評価項目・タグが送られてこなかった場合は既存のものを残す
+		if ratings != nil {
+			if err := replaceReviewPostRatings(tx, postId, ratings); err != nil {
+				return err
+			}
+		}
+		if tags != nil {
+			if err := replaceReviewPostTags(tx, postId, tags); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
-	if result.Error != nil {
-		return result.Error
+}
+
+// replaceReviewPostTags は投稿のタグを置き換える（tagsは正規化済みであること）
+func replaceReviewPostTags(tx *gorm.DB, postId uint, tags []string) error {
+	if len(tags) == 0 {
+		return tx.Where("post_id=?", postId).Delete(&model.ReviewPostTag{}).Error
 	}
-	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+
+	newTags := []model.Tag{}
+	for _, name := range tags {
+		newTags = append(newTags, model.Tag{Name: name})
 	}
-	return nil
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return err
+	}
+
+	tagIds := []uint{}
+	if err := tx.Model(&model.Tag{}).Where("name IN ?", tags).Pluck("id", &tagIds).Error; err != nil {
+		return err
+	}
+
+	// 付け直したタグの作成日時が変わらないよう、外れたタグのみ削除して新しいタグのみ追加する
+	if err := tx.Where("post_id=? AND tag_id NOT IN ?", postId, tagIds).Delete(&model.ReviewPostTag{}).Error; err != nil {
+		return err
+	}
+	rows := []model.ReviewPostTag{}
+	for _, id := range tagIds {
+		rows = append(rows, model.ReviewPostTag{PostId: postId, TagId: id})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func replaceReviewPostRatings(tx *gorm.DB, postId uint, ratings []model.ReviewPostRating) error {
+	if err := tx.Where("post_id=?", postId).Delete(&model.ReviewPostRating{}).Error; err != nil {
+		return err
+	}
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	rows := []model.ReviewPostRating{}
+	for _, r := range ratings {
+		rows = append(rows, model.ReviewPostRating{
+			CriterionKey: r.CriterionKey,
+			Score:        r.Score,
+			PostId:       postId,
+		})
+	}
+	return tx.Create(&rows).Error
+}
+
+func reviewPostContentChanged(before model.ReviewPost, after model.ReviewPost) bool {
+	return before.Title != after.Title ||
+		before.Text != after.Text ||
+		before.Image != after.Image ||
+		before.Review != after.Review ||
+		before.Category != after.Category ||
+		before.ProductProvider != after.ProductProvider ||
+		before.ProductCode != after.ProductCode
 }
 
 func (rr *reviewPostRepository) GetMyReviewPosts(reviewPost *[]model.ReviewPost, userId uint, page int, pageSize int) (int, error) {
@@ -99,24 +221,24 @@ func (rr *reviewPostRepository) GetReviewPostLists(reviewPost *[]model.ReviewPos
 
 	// categoryがallの場合は条件を無視して全てのレコードをカウント
 	if category == "all" {
-		if err := rr.db.Model(&model.ReviewPost{}).Count(&totalCount).Error; err != nil {
+		if err := rr.db.Model(&model.ReviewPost{}).Where("status=?", model.ReviewPostStatusPublished).Count(&totalCount).Error; err != nil {
 			return 0, err
 		}
 	} else {
-		// 部分一致でカテゴリーを検索し、結果の数をカウント
-		if err := rr.db.Model(&model.ReviewPost{}).Where("category LIKE ?", "%"+category+"%").Count(&totalCount).Error; err != nil {
+		// カテゴリーのキーで検索し、結果の数をカウント
+		if err := rr.db.Model(&model.ReviewPost{}).Where("status=? AND category=?", model.ReviewPostStatusPublished, category).Count(&totalCount).Error; err != nil {
 			return 0, err
 		}
 	}
 
 	// categoryがallの場合は条件を無視して全てのレコードを取得
 	if category == "all" {
-		if err := rr.db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(reviewPost).Error; err != nil {
+		if err := rr.db.Where("status=?", model.ReviewPostStatusPublished).Order("published_at DESC").Offset(offset).Limit(pageSize).Find(reviewPost).Error; err != nil {
 			return 0, err
 		}
 	} else {
-		// 部分一致でカテゴリーを検索し、指定されたページとページサイズで結果を取得
-		if err := rr.db.Where("category LIKE ?", "%"+category+"%").Order("created_at DESC").Offset(offset).Limit(pageSize).Find(reviewPost).Error; err != nil {
+		// カテゴリーのキーで検索し、指定されたページとページサイズで結果を取得
+		if err := rr.db.Where("status=? AND category=?", model.ReviewPostStatusPublished, category).Order("published_at DESC").Offset(offset).Limit(pageSize).Find(reviewPost).Error; err != nil {
 			return 0, err
 		}
 	}
@@ -129,5 +251,165 @@ func (rr *reviewPostRepository) GetLikesByPostId(likes *[]model.Like, postId uin
 }
 
 func (rr *reviewPostRepository) GetCommentsByPostId(comments *[]model.Comment, postId uint) error {
-	return rr.db.Where("post_id=?", postId).Find(comments).Error
+	return rr.db.Where("post_id=? AND status=?", postId, model.CommentStatusVisible).Find(comments).Error
+}
+
+func (rr *reviewPostRepository) GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error {
+	return rr.db.Where("post_id=?", postId).Order("position ASC, id ASC").Find(images).Error
+}
+
+func (rr *reviewPostRepository) GetReviewPostsByProduct(reviewPost *[]model.ReviewPost, provider string, code string, page int, pageSize int) (int, error) {
+	offset := (page - 1) * pageSize
+	var totalCount int64
+
+	if err := rr.db.Model(&model.ReviewPost{}).Where("status=? AND product_provider=? AND product_code=?", model.ReviewPostStatusPublished, provider, code).Count(&totalCount).Error; err != nil {
+		return 0, err
+	}
+
+	if err := rr.db.Where("status=? AND product_provider=? AND product_code=?", model.ReviewPostStatusPublished, provider, code).Order("published_at DESC").Offset(offset).Limit(pageSize).Find(reviewPost).Error; err != nil {
+		return 0, err
+	}
+	return int(totalCount), nil
+}
+
+func (rr *reviewPostRepository) GetProductReviewAggregate(aggregate *model.ProductReviewAggregateResponse, provider string, code string) error {
+	key := model.ProductKey{Provider: provider, Code: code}
+	aggregates, err := rr.GetProductReviewAggregates([]model.ProductKey{key})
+	if err != nil {
+		return err
+	}
+	*aggregate = aggregates[key]
+	return nil
+}
+
+// GetProductReviewAggregates は複数の商品（provider, code）のレビュー集計をまとめて取得する。
+// 指定した全ての商品について、レビューがない場合も空の集計を返す
+func (rr *reviewPostRepository) GetProductReviewAggregates(keys []model.ProductKey) (map[model.ProductKey]model.ProductReviewAggregateResponse, error) {
+	aggregates := map[model.ProductKey]model.ProductReviewAggregateResponse{}
+	pairs := [][]interface{}{}
+	for _, key := range keys {
+		if _, ok := aggregates[key]; ok {
+			continue
+		}
+		aggregates[key] = model.ProductReviewAggregateResponse{Criteria: []model.CriterionAggregateResponse{}}
+		pairs = append(pairs, []interface{}{key.Provider, key.Code})
+	}
+	if len(pairs) == 0 {
+		return aggregates, nil
+	}
+
+	var summaries []struct {
+		ProductProvider string
+		ProductCode     string
+		AverageRating   float64
+		ReviewCount     uint
+	}
+	if err := rr.db.Model(&model.ReviewPost{}).
+		Select("product_provider, product_code, COALESCE(AVG(review), 0) AS average_rating, COUNT(*) AS review_count").
+		Where("status=? AND (product_provider, product_code) IN ?", model.ReviewPostStatusPublished, pairs).
+		Group("product_provider, product_code").
+		Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+
+	// 評価を1〜5に丸めて件数を数える
+	var rows []struct {
+		ProductProvider string
+		ProductCode     string
+		Rating          int
+		Count           uint
+	}
+	if err := rr.db.Model(&model.ReviewPost{}).
+		Select("product_provider, product_code, LEAST(GREATEST(ROUND(review), 1), 5) AS rating, COUNT(*) AS count").
+		Where("status=? AND (product_provider, product_code) IN ?", model.ReviewPostStatusPublished, pairs).
+		Group("1, 2, 3").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var criteria []struct {
+		ProductProvider string
+		ProductCode     string
+		model.CriterionAggregateResponse
+	}
+	if err := rr.db.Table("review_post_ratings").
+		Select("review_posts.product_provider, review_posts.product_code, review_post_ratings.criterion_key AS key, MAX(rating_criteria.name) AS name, AVG(review_post_ratings.score) AS average_score, COUNT(*) AS count").
+		Joins("JOIN review_posts ON review_posts.id = review_post_ratings.post_id").
+		Joins("LEFT JOIN rating_criteria ON rating_criteria.key = review_post_ratings.criterion_key AND rating_criteria.category = review_posts.category").
+		Where("review_posts.status=? AND (review_posts.product_provider, review_posts.product_code) IN ?", model.ReviewPostStatusPublished, pairs).
+		Group("review_posts.product_provider, review_posts.product_code, review_post_ratings.criterion_key").
+		Order("MIN(rating_criteria.position), review_post_ratings.criterion_key").
+		Scan(&criteria).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[model.ProductKey]map[int]uint{}
+	for _, row := range rows {
+		key := model.ProductKey{Provider: row.ProductProvider, Code: row.ProductCode}
+		if counts[key] == nil {
+			counts[key] = map[int]uint{}
+		}
+		counts[key][row.Rating] = row.Count
+	}
+
+	for _, summary := range summaries {
+		key := model.ProductKey{Provider: summary.ProductProvider, Code: summary.ProductCode}
+		aggregate := aggregates[key]
+		aggregate.AverageRating = summary.AverageRating
+		aggregate.ReviewCount = summary.ReviewCount
+		aggregates[key] = aggregate
+	}
+	for _, c := range criteria {
+		key := model.ProductKey{Provider: c.ProductProvider, Code: c.ProductCode}
+		aggregate := aggregates[key]
+		aggregate.Criteria = append(aggregate.Criteria, c.CriterionAggregateResponse)
+		aggregates[key] = aggregate
+	}
+	for key, aggregate := range aggregates {
+		aggregate.Histogram = []model.ProductReviewHistogramResponse{}
+		for rating := 1; rating <= 5; rating++ {
+			aggregate.Histogram = append(aggregate.Histogram, model.ProductReviewHistogramResponse{
+				Rating: rating,
+				Count:  counts[key][rating],
+			})
+		}
+		aggregates[key] = aggregate
+	}
+	return aggregates, nil
+}
+
+func (rr *reviewPostRepository) GetRevisionsByPostId(revisions *[]model.ReviewPostRevision, postId uint) error {
+	return rr.db.Where("post_id=?", postId).Order("created_at ASC, id ASC").Find(revisions).Error
+}
+
+func (rr *reviewPostRepository) PublishScheduledReviewPosts(now time.Time) (int, error) {
+	result := rr.db.Model(&model.ReviewPost{}).
+		Where("status=? AND published_at <= ?", model.ReviewPostStatusScheduled, now).
+		Update("status", model.ReviewPostStatusPublished)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (rr *reviewPostRepository) GetRatingsByPostId(ratings *[]model.ReviewPostRatingResponse, postId uint) error {
+	return rr.db.Table("review_post_ratings").
+		Select("review_post_ratings.criterion_key AS key, COALESCE(rating_criteria.name, '') AS name, review_post_ratings.score").
+		Joins("JOIN review_posts ON review_posts.id = review_post_ratings.post_id").
+		Joins("LEFT JOIN rating_criteria ON rating_criteria.key = review_post_ratings.criterion_key AND rating_criteria.category = review_posts.category").
+		Where("review_post_ratings.post_id=?", postId).
+		Order("rating_criteria.position, review_post_ratings.id").
+		Scan(ratings).Error
+}
+
+func (rr *reviewPostRepository) GetTagsByPostId(postId uint) ([]string, error) {
+	tags := []string{}
+	if err := rr.db.Table("review_post_tags").
+		Joins("JOIN tags ON tags.id = review_post_tags.tag_id").
+		Where("review_post_tags.post_id=?", postId).
+		Order("review_post_tags.id ASC").
+		Pluck("tags.name", &tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
 }
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"merchandise-review-list-backend/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ITagRepository interface {
+	GetPostIdsByTag(tag string, page int, pageSize int) ([]uint, int, error)
+	SearchTagsByPrefix(tags *[]model.TagResponse, prefix string, limit int) error
+	GetTrendingTags(tags *[]model.TagResponse, since time.Time, limit int) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) ITagRepository {
+	return &tagRepository{db}
+}
+
+// publishedPostTags は公開済みの投稿に付いたタグに絞り込んだクエリを返す
+func (tr *tagRepository) publishedPostTags() *gorm.DB {
+	return tr.db.Table("review_post_tags").
+		Joins("JOIN tags ON tags.id = review_post_tags.tag_id").
+		Joins("JOIN review_posts ON review_posts.id = review_post_tags.post_id").
+		Where("review_posts.status=?", model.ReviewPostStatusPublished)
+}
+
+func (tr *tagRepository) GetPostIdsByTag(tag string, page int, pageSize int) ([]uint, int, error) {
+	offset := (page - 1) * pageSize
+	var totalCount int64
+
+	if err := tr.publishedPostTags().Where("tags.name=?", tag).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	postIds := []uint{}
+	if err := tr.publishedPostTags().Where("tags.name=?", tag).
+		Order("review_posts.published_at DESC").Offset(offset).Limit(pageSize).
+		Pluck("review_posts.id", &postIds).Error; err != nil {
+		return nil, 0, err
+	}
+	return postIds, int(totalCount), nil
+}
+
+// SearchTagsByPrefix は前方一致するタグを使用回数の多い順に返す
+func (tr *tagRepository) SearchTagsByPrefix(tags *[]model.TagResponse, prefix string, limit int) error {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+	return tr.publishedPostTags().
+		Select("tags.name AS name, COUNT(*) AS count").
+		Where("tags.name LIKE ?", escaped+"%").
+		Group("tags.name").
+		Order("count DESC, tags.name ASC").
+		Limit(limit).
+		Scan(tags).Error
+}
+
+// GetTrendingTags はsince以降に付けられた回数の多いタグを返す
+func (tr *tagRepository) GetTrendingTags(tags *[]model.TagResponse, since time.Time, limit int) error {
+	return tr.publishedPostTags().
+		Select("tags.name AS name, COUNT(*) AS count").
+		Where("review_post_tags.created_at >= ?", since).
+		Group("tags.name").
+		Order("count DESC, MAX(review_post_tags.created_at) DESC").
+		Limit(limit).
+		Scan(tags).Error
+}
@@ -46,11 +46,16 @@ func (ur *userRepository) GetUserByID(user *model.User, id uint) error {
 }
 
 func (ur *userRepository) UpdateUser(user *model.User, id uint) error {
-	result := ur.db.Model(user).Clauses(clause.Returning{}).Where("id=?", id).Updates(map[string]interface{}{
+	values := map[string]interface{}{
 		"email": user.Email,
 		"name":  user.Name,
 		"image": user.Image,
-	})
+	}
+	// タイムゾーンは指定された場合のみ変更する
+	if user.TimeZone != "" {
+		values["time_zone"] = user.TimeZone
+	}
+	result := ur.db.Model(user).Clauses(clause.Returning{}).Where("id=?", id).Updates(values)
 	if result.Error != nil {
 		return result.Error
 	}
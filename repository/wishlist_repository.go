@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWishlistRepository interface {
+	CreateWishlist(wishlist *model.Wishlist) error
+	UpdateWishlist(wishlist *model.Wishlist, userId uint, id uint) error
+	DeleteWishlist(userId uint, id uint) error
+	GetMyWishlists(wishlists *[]model.Wishlist, userId uint) error
+	GetWishlistById(wishlist *model.Wishlist, userId uint, id uint) error
+	GetMembers(members *[]model.WishlistMember, wishlistId uint) error
+	AddMember(member *model.WishlistMember, ownerId uint) error
+	UpdateMemberRole(ownerId uint, wishlistId uint, memberId uint, role string) error
+	DeleteMember(userId uint, wishlistId uint, memberId uint) error
+	AcceptInvite(userId uint, wishlistId uint) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) IWishlistRepository {
+	return &wishlistRepository{db}
+}
+
+// CreateWishlist はリストを作成し、作成者をownerとして登録する
+func (wr *wishlistRepository) CreateWishlist(wishlist *model.Wishlist) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wishlist).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		owner := model.WishlistMember{
+			Role:       model.WishlistRoleOwner,
+			AcceptedAt: &now,
+			WishlistId: wishlist.ID,
+			UserId:     wishlist.UserId,
+		}
+		return tx.Create(&owner).Error
+	})
+}
+
+func (wr *wishlistRepository) UpdateWishlist(wishlist *model.Wishlist, userId uint, id uint) error {
+	result := wr.db.Model(wishlist).Clauses(clause.Returning{}).Where("id=? AND user_id=?", id, userId).Updates(map[string]interface{}{
+		"name": wishlist.Name,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (wr *wishlistRepository) DeleteWishlist(userId uint, id uint) error {
+	result := wr.db.Where("id=? AND user_id=?", id, userId).Delete(&model.Wishlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+// GetMyWishlists は自分がメンバーのリストを取得する（承認前の招待を含む）
+func (wr *wishlistRepository) GetMyWishlists(wishlists *[]model.Wishlist, userId uint) error {
+	return wr.db.Where("id IN (?)", wr.db.Model(&model.WishlistMember{}).Select("wishlist_id").Where("user_id=?", userId)).
+		Order("created_at DESC").Find(wishlists).Error
+}
+
+// GetWishlistById は自分がメンバーのリストを取得する（承認前の招待を含む）
+func (wr *wishlistRepository) GetWishlistById(wishlist *model.Wishlist, userId uint, id uint) error {
+	if err := wr.db.Where("id=? AND id IN (?)", id, wr.db.Model(&model.WishlistMember{}).Select("wishlist_id").Where("user_id=?", userId)).
+		First(wishlist).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *wishlistRepository) GetMembers(members *[]model.WishlistMember, wishlistId uint) error {
+	return wr.db.Joins("User").Where("wishlist_id=?", wishlistId).Order("wishlist_members.created_at ASC, wishlist_members.id ASC").Find(members).Error
+}
+
+// AddMember はリストの作成者のみメンバーを招待できる（既にメンバーの場合はエラー）
+func (wr *wishlistRepository) AddMember(member *model.WishlistMember, ownerId uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Wishlist{}).Where("id=? AND user_id=?", member.WishlistId, ownerId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count < 1 {
+			return fmt.Errorf("object does not exist")
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("user is already a member")
+		}
+		return nil
+	})
+}
+
+// UpdateMemberRole はリストの作成者のみ変更できる（作成者自身の権限は変更できない）
+func (wr *wishlistRepository) UpdateMemberRole(ownerId uint, wishlistId uint, memberId uint, role string) error {
+	result := wr.db.Model(&model.WishlistMember{}).
+		Where("id=? AND wishlist_id=? AND role<>?", memberId, wishlistId, model.WishlistRoleOwner).
+		Where("wishlist_id IN (?)", wr.db.Model(&model.Wishlist{}).Select("id").Where("user_id=?", ownerId)).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+// DeleteMember は作成者によるメンバーの削除と、メンバー自身の退出（招待の辞退を含む）を行う
+func (wr *wishlistRepository) DeleteMember(userId uint, wishlistId uint, memberId uint) error {
+	result := wr.db.
+		Where("id=? AND wishlist_id=? AND role<>?", memberId, wishlistId, model.WishlistRoleOwner).
+		Where("(user_id=? OR wishlist_id IN (?))", userId, wr.db.Model(&model.Wishlist{}).Select("id").Where("user_id=?", userId)).
+		Delete(&model.WishlistMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
+
+func (wr *wishlistRepository) AcceptInvite(userId uint, wishlistId uint) error {
+	result := wr.db.Model(&model.WishlistMember{}).
+		Where("wishlist_id=? AND user_id=? AND accepted_at IS NULL", wishlistId, userId).
+		Update("accepted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("object does not exist")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"fmt"
+	"merchandise-review-list-backend/ratelimit"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// writeRateLimit はJWTが必須なグループの書き込み系リクエストにグループごとに適用する上限
+// 個別の上限（rateLimits）がある場合は両方を満たす必要がある
+var writeRateLimit = ratelimit.PerMinute(60)
+
+// rateLimits は書き込み系エンドポイントごとのリクエスト数の上限
+var rateLimits = map[string]ratelimit.Limit{
+	"signup":                 ratelimit.PerMinute(5),
+	"login":                  ratelimit.PerMinute(10),
+	"updateUser":             ratelimit.PerMinute(10),
+	"createProduct":          ratelimit.PerMinute(30),
+	"refreshProduct":         ratelimit.PerMinute(10),
+	"lookupProduct":          ratelimit.PerMinute(20),
+	"createReviewPost":       ratelimit.PerMinute(5),
+	"updateReviewPost":       ratelimit.PerMinute(20),
+	"uploadReviewPostImage":  ratelimit.PerMinute(10),
+	"createLike":             ratelimit.PerMinute(30),
+	"createComment":          ratelimit.PerMinute(10),
+	"createMoneyManagement":  ratelimit.PerMinute(60),
+	"importMoneyManagement":  ratelimit.PerMinute(5),
+	"createCollection":       ratelimit.PerMinute(10),
+	"addCollectionItem":      ratelimit.PerMinute(30),
+	"createWishlist":         ratelimit.PerMinute(10),
+	"inviteWishlistMember":   ratelimit.PerMinute(10),
+	"addWishlistItem":        ratelimit.PerMinute(30),
+	"regenerateCalendarFeed": ratelimit.PerMinute(5),
+}
+
+// newIPExtractor はIPアドレスごとの制限に使うクライアントのIPアドレスの取得方法を返す
+// trustedProxies（カンマ区切りのCIDR）に含まれるプロキシから届いたX-Forwarded-Forのみを信頼する。
+// 未設定の場合はヘッダーを無視して接続元のアドレスを使う（ヘッダーの偽装で制限を回避できないようにする）
+func newIPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range strings.Split(trustedProxies, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	if len(options) == 3 {
+		return echo.ExtractIPDirect(), nil
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
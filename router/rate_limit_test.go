@@ -0,0 +1,71 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{
+			name:         "ignores X-Forwarded-For without trusted proxies",
+			remoteAddr:   "203.0.113.10:1234",
+			forwardedFor: "198.51.100.1",
+			want:         "203.0.113.10",
+		},
+		{
+			name:         "ignores X-Forwarded-For from a private address that is not configured",
+			remoteAddr:   "10.0.0.5:1234",
+			forwardedFor: "198.51.100.1",
+			want:         "10.0.0.5",
+		},
+		{
+			name:           "uses X-Forwarded-For from a trusted proxy",
+			trustedProxies: "10.0.0.0/8",
+			remoteAddr:     "10.0.0.5:1234",
+			forwardedFor:   "198.51.100.1",
+			want:           "198.51.100.1",
+		},
+		{
+			// クライアントが先頭に付け足した値は使わず、信頼できるプロキシが追加した値を使う
+			name:           "ignores addresses the client prepended",
+			trustedProxies: "10.0.0.0/8, 172.16.0.0/12",
+			remoteAddr:     "10.0.0.5:1234",
+			forwardedFor:   "1.2.3.4, 198.51.100.1, 172.16.0.2",
+			want:           "198.51.100.1",
+		},
+		{
+			name:           "ignores X-Forwarded-For from an untrusted client",
+			trustedProxies: "10.0.0.0/8",
+			remoteAddr:     "203.0.113.10:1234",
+			forwardedFor:   "198.51.100.1",
+			want:           "203.0.113.10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extract, err := newIPExtractor(tt.trustedProxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest("POST", "/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			if got := extract(req); got != tt.want {
+				t.Errorf("ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPExtractorRejectsInvalidCIDR(t *testing.T) {
+	if _, err := newIPExtractor("10.0.0.0/8,not-a-cidr"); err == nil {
+		t.Fatal("newIPExtractor() accepted an invalid CIDR")
+	}
+}
@@ -1,7 +1,10 @@
 package router
 
 import (
+	"log"
 	"merchandise-review-list-backend/controller"
+	"merchandise-review-list-backend/ratelimit"
+	"merchandise-review-list-backend/storage"
 	"net/http"
 
 	"os"
@@ -19,8 +22,35 @@ func NewRouter(
 	cc controller.ICommentController,
 	mc controller.IMoneyManagementController,
 	bc controller.IBudgetController,
+	ic controller.IReviewPostImageController,
+	rcc controller.IRatingCriterionController,
+	clc controller.ICollectionController,
+	tc controller.ITagController,
+	cgc controller.ICategoryController,
+	rsc controller.IReviewPostScoreController,
+	rsmc controller.IReviewPostSimilarityController,
+	mdc controller.IModerationController,
+	nc controller.INotificationController,
+	cfc controller.ICalendarFeedController,
+	wc controller.IWishlistController,
+	rls ratelimit.Store,
 ) *echo.Echo {
+	// 書き込み系エンドポイントのリクエスト数の制限（JWTのミドルウェアの後に実行する）
+	// 上限が定義されていない名前は全てのリクエストを拒否してしまうため、起動時に停止する
+	limit := func(name string) echo.MiddlewareFunc {
+		l, ok := rateLimits[name]
+		if !ok {
+			log.Fatalln("rate limit is not defined:", name)
+		}
+		return ratelimit.Middleware(rls, name, l)
+	}
+
 	e := echo.New()
+	ipExtractor, err := newIPExtractor(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	e.IPExtractor = ipExtractor
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:3000", os.Getenv("FE_URL")},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept,
@@ -37,93 +67,155 @@ func NewRouter(
 		// CookieMaxAge: 60,
 	}))
 
-	e.POST("/signup", uc.SignUp)
-	e.POST("/login", uc.LogIn)
+	// JWTが必須なグループ。書き込み系のリクエストはグループごとにwriteRateLimitで制限する
+	authGroup := func(prefix string) *echo.Group {
+		g := e.Group(prefix)
+		g.Use(echojwt.WithConfig(echojwt.Config{
+			SigningKey:  []byte(os.Getenv("SECRET")),
+			TokenLookup: "cookie:token",
+		}))
+		g.Use(ratelimit.WriteMiddleware(rls, "write"+prefix, writeRateLimit))
+		return g
+	}
+
+	// ローカル保存時のアップロード画像の配信
+	e.Static("/uploads", storage.UploadDir())
+
+	e.POST("/signup", uc.SignUp, limit("signup"))
+	e.POST("/login", uc.LogIn, limit("login"))
 	e.POST("/logout", uc.LogOut)
 	e.GET("/csrf", uc.CsrfToken)
 
-	u := e.Group("/user")
-	u.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
+	u := authGroup("/user")
 
 	// JWTが必須なエンドポイント
 	u.GET("", uc.GetLoggedInUser)
-	u.PUT("", uc.UpdateUser)
+	u.PUT("", uc.UpdateUser, limit("updateUser"))
 	u.DELETE("/:userId", uc.DeleteUser)
+	u.GET("/notificationSettings", nc.GetSetting)
+	u.PUT("/notificationSettings", nc.UpdateSetting)
 
-	p := e.Group("/product")
-
-	p.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
+	p := authGroup("/product")
 	// JWTが必須なエンドポイント
-	p.POST("", pc.CreateProduct)
-	p.PUT("/:productId", pc.UpdateTimeLimit)
+	p.POST("", pc.CreateProduct, limit("createProduct"))
+	p.PUT("/:productId", pc.UpdateProduct)
+	p.PUT("/:productId/timeLimit", pc.UpdateTimeLimit)
+	p.GET("/:productId/history", pc.GetProductHistories)
+	p.POST("/:productId/prices", pc.RecordPriceSnapshot)
+	p.GET("/:productId/prices", pc.GetPriceTimeline)
+	p.PUT("/:productId/targetPrice", pc.UpdateTargetPrice)
+	p.POST("/refresh/:productId", pc.RefreshProduct, limit("refreshProduct"))
+	p.GET("/lookup", pc.LookupProduct, limit("lookupProduct"))
+	p.POST("/:productId/purchase", pc.PurchaseProduct, limit("createMoneyManagement"))
+	p.POST("/bulkDelete", pc.DeleteProducts)
 	p.GET("/userProducts", pc.GetMyProducts)
 	p.GET("/timeLimitAll", pc.GetMyProductsTimeLimitAll)
 	p.DELETE("/:productId", pc.DeleteProduct)
 	p.GET("/timeLimitYearMonth", pc.GetMyProductsTimeLimitYearMonth)
 	p.GET("/timeLimitDate", pc.GetMyProductsTimeLimitDate)
 
-	r := e.Group("/reviewPosts")
+	r := authGroup("/reviewPosts")
 	// JWTが必須なエンドポイント
-	r.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
-	r.POST("", rc.CreateReviewPost)
-	r.PUT("/:postId", rc.UpdateReviewPost)
+	r.POST("", rc.CreateReviewPost, limit("createReviewPost"))
+	r.PUT("/:postId", rc.UpdateReviewPost, limit("updateReviewPost"))
 	r.GET("/userReviewPosts", rc.GetMyReviewPosts)
 	r.DELETE("/:postId", rc.DeleteReviewPost)
 	r.GET("/likes", rc.GetMyLikes)
+	r.POST("/:postId/images", ic.UploadImages, limit("uploadReviewPostImage"))
+	r.PUT("/:postId/images/order", ic.ReorderImages)
+	r.DELETE("/:postId/images/:imageId", ic.DeleteImage)
 	// JWTが必須でないエンドポイント
 	e.GET("/reviewPosts/postId/:postId", rc.GetReviewPostById)
 	e.GET("/reviewPosts/lists/:category", rc.GetReviewPostLists)
+	e.GET("/reviewPosts/trending", rsc.GetRanking)
+	e.GET("/reviewPosts/:postId/revisions", rc.GetReviewPostRevisions)
+	e.GET("/reviewPosts/:postId/similar", rsmc.GetSimilarReviewPosts)
+	e.GET("/products/:provider/:code/reviews", rc.GetProductReviews)
+	e.GET("/ratingCriteria", rcc.GetCriteriaByCategory)
+
+	// JWTが必須でないエンドポイント
+	e.GET("/tags/autocomplete", tc.AutocompleteTags)
+	e.GET("/tags/trending", tc.GetTrendingTags)
+	e.GET("/tags/:tag/reviewPosts", tc.GetReviewPostsByTag)
+
+	// JWTが必須でないエンドポイント
+	e.GET("/categories", cgc.GetCategories)
 
-	l := e.Group("/like")
+	l := authGroup("/like")
 	// JWTが必須なエンドポイント
-	l.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
-	l.POST("", lc.CreateLike)
+	l.POST("", lc.CreateLike, limit("createLike"))
 	l.DELETE("/:postUserId", lc.DeleteLike)
 
-	c := e.Group("/comment")
+	c := authGroup("/comment")
 	// JWTが必須なエンドポイント
-	c.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
-	c.POST("", cc.CreateComment)
+	c.POST("", cc.CreateComment, limit("createComment"))
 	c.DELETE("/:id", cc.DeleteComment)
 
 	// JWTが必須でないエンドポイント
 	e.GET("/comment", cc.GetCommentsByPostId)
 
-	m := e.Group("/moneyManagement")
+	m := authGroup("/moneyManagement")
 	// JWTが必須なエンドポイント
-	m.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
-	m.POST("", mc.CreateMoneyManagement)
+	m.POST("", mc.CreateMoneyManagement, limit("createMoneyManagement"))
 	m.GET("", mc.GetMyMoneyManagements)
+	m.POST("/import", mc.ImportMoneyManagements, limit("importMoneyManagement"))
+	m.GET("/export", mc.ExportMoneyManagements)
 	m.PUT("/:id", mc.UpdateMoneyManagement)
 	m.DELETE("/:id", mc.DeleteMoneyManagement)
 
-	b := e.Group("/budget")
-	b.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:token",
-	}))
+	b := authGroup("/budget")
 	// JWTが必須なエンドポイント
 	b.POST("", bc.CreateBudget)
 	b.GET("/budgetByUserId", bc.GetBudgetByUserId)
+	b.GET("/export", bc.ExportBudgetActuals)
 	b.PUT("/:id", bc.UpdateBudget)
 
+	col := authGroup("/collections")
+	// JWTが必須なエンドポイント
+	col.POST("", clc.CreateCollection, limit("createCollection"))
+	col.GET("", clc.GetMyCollections)
+	col.GET("/:id", clc.GetMyCollectionById)
+	col.PUT("/:id", clc.UpdateCollection)
+	col.DELETE("/:id", clc.DeleteCollection)
+	col.POST("/:id/items", clc.AddItem, limit("addCollectionItem"))
+	col.PUT("/:id/items/order", clc.ReorderItems)
+	col.DELETE("/:id/items/:postId", clc.RemoveItem)
+	// JWTが必須でないエンドポイント
+	e.GET("/collections/shared/:slug", clc.GetSharedCollection)
+
+	md := authGroup("/moderation")
+	// JWTが必須なエンドポイント（管理者のみ）
+	md.GET("/holds", mdc.GetPendingHolds)
+	md.PUT("/holds/:id/approve", mdc.ApproveHold)
+	md.PUT("/holds/:id/reject", mdc.RejectHold)
+
+	n := authGroup("/notifications")
+	// JWTが必須なエンドポイント
+	n.GET("", nc.GetNotifications)
+	n.PUT("/:id/read", nc.MarkAsRead)
+
+	cf := authGroup("/calendarFeed")
+	// JWTが必須なエンドポイント
+	cf.GET("", cfc.GetFeed)
+	cf.POST("/regenerate", cfc.RegenerateFeed, limit("regenerateCalendarFeed"))
+	// JWTが必須でないエンドポイント（URLのトークンで認証する）
+	e.GET("/calendar/:token", cfc.GetICS)
+
+	w := authGroup("/wishlists")
+	// JWTが必須なエンドポイント
+	w.POST("", wc.CreateWishlist, limit("createWishlist"))
+	w.GET("", wc.GetMyWishlists)
+	w.GET("/:id", wc.GetWishlistById)
+	w.PUT("/:id", wc.UpdateWishlist)
+	w.DELETE("/:id", wc.DeleteWishlist)
+	w.POST("/:id/members", wc.InviteMember, limit("inviteWishlistMember"))
+	w.PUT("/:id/members/:memberId", wc.UpdateMemberRole)
+	w.DELETE("/:id/members/:memberId", wc.RemoveMember)
+	w.PUT("/:id/accept", wc.AcceptInvite)
+	w.POST("/:id/items", wc.AddItem, limit("addWishlistItem"))
+	w.DELETE("/:id/items/:productId", wc.RemoveItem)
+	w.PUT("/:id/items/:productId/bought", wc.MarkBought)
+	w.DELETE("/:id/items/:productId/bought", wc.UnmarkBought)
+
 	return e
 }
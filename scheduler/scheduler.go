@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// Every はjobをinterval毎にバックグラウンドで実行する。エラーはログに出力して次回も実行を続ける
+func Every(interval time.Duration, name string, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(name, job)
+			<-ticker.C
+		}
+	}()
+}
+
+func run(name string, job func() error) {
+	// ジョブ内のpanicでサーバーが停止しないようにする
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", name, r)
+		}
+	}()
+
+	if err := job(); err != nil {
+		log.Printf("job %s failed: %v", name, err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package similarity は投稿本文のTF-IDFとカテゴリー・タグの一致から類似度を計算する
+package similarity
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 類似度の各要素の重み（合計1）
+const (
+	textWeight     = 0.7
+	categoryWeight = 0.15
+	tagWeight      = 0.15
+)
+
+type Document struct {
+	ID       uint
+	Text     string
+	Category string
+	Tags     []string
+}
+
+type Result struct {
+	ID    uint
+	Score float64
+}
+
+// Tokenize は文字列をトークンに分割する
+// 日本語は形態素解析を使わずに文字bigramにし、英数字は単語単位にする
+func Tokenize(text string) []string {
+	text = strings.ToLower(norm.NFKC.String(text))
+
+	tokens := []string{}
+	word := []rune{}
+	flushWord := func() {
+		if len(word) > 1 {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+
+	var prev rune
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word = append(word, r)
+			prev = 0
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushWord()
+			if prev != 0 {
+				tokens = append(tokens, string([]rune{prev, r}))
+			}
+			prev = r
+		default:
+			flushWord()
+			prev = 0
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// Rank はcorpusの中からtargetに似ている文書を類似度の高い順にlimit件返す（類似度0の文書は除く）
+func Rank(target Document, corpus []Document, limit int) []Result {
+	documents := append([]Document{target}, corpus...)
+	termFrequencies := make([]map[string]float64, len(documents))
+	documentFrequency := map[string]int{}
+	for i, d := range documents {
+		termFrequencies[i] = termFrequency(Tokenize(d.Text))
+		for term := range termFrequencies[i] {
+			documentFrequency[term]++
+		}
+	}
+
+	vectors := make([]map[string]float64, len(documents))
+	for i, tf := range termFrequencies {
+		vectors[i] = tfidf(tf, documentFrequency, len(documents))
+	}
+
+	results := []Result{}
+	for i, d := range corpus {
+		if d.ID == target.ID {
+			continue
+		}
+		score := textWeight*cosine(vectors[0], vectors[i+1]) +
+			categoryWeight*categoryMatch(target.Category, d.Category) +
+			tagWeight*jaccard(target.Tags, d.Tags)
+		if score <= 0 {
+			continue
+		}
+		results = append(results, Result{ID: d.ID, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func termFrequency(tokens []string) map[string]float64 {
+	tf := map[string]float64{}
+	for _, t := range tokens {
+		tf[t]++
+	}
+	for t := range tf {
+		tf[t] /= float64(len(tokens))
+	}
+	return tf
+}
+
+// tfidf は平滑化したIDFで重み付けしたベクトルを返す
+func tfidf(tf map[string]float64, documentFrequency map[string]int, documentCount int) map[string]float64 {
+	vector := map[string]float64{}
+	for term, f := range tf {
+		idf := math.Log(float64(1+documentCount)/float64(1+documentFrequency[term])) + 1
+		vector[term] = f * idf
+	}
+	return vector
+}
+
+func cosine(a map[string]float64, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, v := range a {
+		dot += v * b[term]
+		normA += v * v
+	}
+	for _, v := range b {
+		normB += v * v
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func categoryMatch(a string, b string) float64 {
+	if a != "" && a == b {
+		return 1
+	}
+	return 0
+}
+
+func jaccard(a []string, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := map[string]bool{}
+	for _, t := range a {
+		set[t] = true
+	}
+	union := len(set)
+	intersection := 0
+	seen := map[string]bool{}
+	for _, t := range b {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	return float64(intersection) / float64(union)
+}
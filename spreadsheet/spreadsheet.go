@@ -0,0 +1,126 @@
+// Package spreadsheet は表計算ソフト（主にExcel）とやり取りするCSVの文字コードを扱う
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	textencoding "golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// 対応する文字コード
+const (
+	EncodingUTF8     = "utf-8"
+	EncodingShiftJIS = "shift_jis"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// NormalizeEncoding は文字コードの表記ゆれを吸収する（空文字の場合は自動判定）
+func NormalizeEncoding(encoding string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "":
+		return "", nil
+	case "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "shift_jis", "shift-jis", "sjis", "cp932", "windows-31j":
+		return EncodingShiftJIS, nil
+	}
+	return "", fmt.Errorf("unsupported encoding: %s", encoding)
+}
+
+// Decode はdataをUTF-8に変換する。encodingが空の場合、BOMがあるか正しいUTF-8ならUTF-8、それ以外はShift_JISとみなす
+// 日本語版のExcelはCSVをShift_JIS（CP932）かBOM付きUTF-8で保存する
+func Decode(data []byte, encoding string) (string, error) {
+	encoding, err := NormalizeEncoding(encoding)
+	if err != nil {
+		return "", err
+	}
+	if encoding == "" {
+		if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+			encoding = EncodingUTF8
+		} else {
+			encoding = EncodingShiftJIS
+		}
+	}
+
+	if encoding == EncodingShiftJIS {
+		decoded, _, err := transform.Bytes(japanese.ShiftJIS.NewDecoder(), data)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("invalid utf-8 text")
+	}
+	return string(data), nil
+}
+
+// ReadRecords はヘッダー行のあるCSVを読み込み、列名をキーにした行の一覧を返す
+func ReadRecords(data []byte, encoding string) ([]map[string]string, error) {
+	text, err := Decode(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(strings.NewReader(text))
+	// 行ごとに列数が異なっても読み込み、足りない列は空とする
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return []map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	records := []map[string]string{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		record := map[string]string{}
+		for i, name := range header {
+			if i < len(row) {
+				record[name] = strings.TrimSpace(row[i])
+			} else {
+				record[name] = ""
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// NewWriter はencodingで書き出すCSVのWriterを返す
+// UTF-8の場合はExcelで文字化けしないよう先頭にBOMを書き出す
+// Shift_JISで表せない文字（絵文字など）は代替文字に置き換える
+func NewWriter(w io.Writer, encoding string) (*csv.Writer, error) {
+	encoding, err := NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if encoding == EncodingShiftJIS {
+		return csv.NewWriter(transform.NewWriter(w, textencoding.ReplaceUnsupported(japanese.ShiftJIS.NewEncoder()))), nil
+	}
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+	return csv.NewWriter(w), nil
+}
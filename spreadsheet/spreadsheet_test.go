@@ -0,0 +1,227 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func shiftJIS(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{" UTF-8 ", EncodingUTF8, false},
+		{"utf8", EncodingUTF8, false},
+		{"Shift_JIS", EncodingShiftJIS, false},
+		{"sjis", EncodingShiftJIS, false},
+		{"CP932", EncodingShiftJIS, false},
+		{"windows-31j", EncodingShiftJIS, false},
+		{"euc-jp", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeEncoding(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("NormalizeEncoding(%q) = %q, %v, want %q, wantErr %t", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	const text = "日付,品名\r\n2024-01-01,①ﾊﾞﾅﾅ～\r\n"
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...), "", text, false},
+		{"UTF-8 without BOM", []byte(text), "", text, false},
+		{"explicit UTF-8 strips BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...), "utf-8", text, false},
+		// ①や～はCP932の文字
+		{"CP932", shiftJIS(t, text), "", text, false},
+		{"explicit Shift_JIS", shiftJIS(t, text), "sjis", text, false},
+		{"Shift_JIS declared as UTF-8", shiftJIS(t, text), "utf-8", "", true},
+		{"ASCII", []byte("date,title\n"), "", "date,title\n", false},
+		{"ASCII declared as Shift_JIS", []byte("date,title\n"), "shift_jis", "date,title\n", false},
+		{"empty", []byte{}, "", "", false},
+		{"BOM only", []byte{0xEF, 0xBB, 0xBF}, "", "", false},
+		{"unsupported encoding", []byte(text), "euc-jp", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// 短いShift_JISの値は正しいUTF-8にもなる場合があり、自動判定ではUTF-8とみなす
+// その場合は文字コードを指定すれば正しく読み込める
+func TestDecodeShortShiftJISMisdetection(t *testing.T) {
+	data := shiftJIS(t, "ﾃｩ") // 0xC3 0xA9 はUTF-8の「é」
+	if !bytes.Equal(data, []byte{0xC3, 0xA9}) {
+		t.Fatalf("data = % x", data)
+	}
+
+	got, err := Decode(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "é" {
+		t.Errorf("auto-detected = %q, want é", got)
+	}
+	got, err = Decode(data, "shift_jis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ﾃｩ" {
+		t.Errorf("explicit Shift_JIS = %q, want ﾃｩ", got)
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     []map[string]string
+	}{
+		{
+			name: "maps columns by header",
+			data: []byte(" date , title ,price\n2024-01-01, りんご ,100\n"),
+			want: []map[string]string{{"date": "2024-01-01", "title": "りんご", "price": "100"}},
+		},
+		{
+			name: "missing columns are empty and extra columns are ignored",
+			data: []byte("date,title,price\n2024-01-01\n2024-01-02,本,200,extra\n"),
+			want: []map[string]string{
+				{"date": "2024-01-01", "title": "", "price": ""},
+				{"date": "2024-01-02", "title": "本", "price": "200"},
+			},
+		},
+		{
+			name: "quoted fields",
+			data: []byte("title,memo\n\"a,b\",\"1行目\n2行目\"\n"),
+			want: []map[string]string{{"title": "a,b", "memo": "1行目\n2行目"}},
+		},
+		{
+			name: "Shift_JIS with CRLF",
+			data: shiftJIS(t, "日付,品名\r\n2024-01-01,ﾊﾞﾅﾅ\r\n"),
+			want: []map[string]string{{"日付": "2024-01-01", "品名": "ﾊﾞﾅﾅ"}},
+		},
+		{
+			name: "BOM is not part of the first column name",
+			data: append([]byte{0xEF, 0xBB, 0xBF}, "date,title\n2024-01-01,本\n"...),
+			want: []map[string]string{{"date": "2024-01-01", "title": "本"}},
+		},
+		{name: "header only", data: []byte("date,title\n"), want: []map[string]string{}},
+		{name: "empty", data: []byte{}, want: []map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadRecords(tt.data, tt.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRecordsInvalidCSV(t *testing.T) {
+	if _, err := ReadRecords([]byte("title\n\"unterminated\n"), ""); err == nil {
+		t.Error("ReadRecords() error = nil")
+	}
+}
+
+func TestWriteThenRead(t *testing.T) {
+	rows := [][]string{
+		{"date", "title", "memo"},
+		{"2024-01-01", "りんご", "a,b"},
+		{"2024-12-31", "ﾊﾞﾅﾅ①", "\"引用\"\n2行目"},
+	}
+	want := []map[string]string{
+		{"date": "2024-01-01", "title": "りんご", "memo": "a,b"},
+		{"date": "2024-12-31", "title": "ﾊﾞﾅﾅ①", "memo": "\"引用\"\n2行目"},
+	}
+
+	for _, encoding := range []string{"utf-8", "shift_jis"} {
+		t.Run(encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.WriteAll(rows); err != nil {
+				t.Fatal(err)
+			}
+
+			// UTF-8はBOM付き、Shift_JISはBOMなしで書き出す
+			if hasBOM := bytes.HasPrefix(buf.Bytes(), utf8BOM); hasBOM != (encoding == "utf-8") {
+				t.Errorf("BOM = %t", hasBOM)
+			}
+			// 文字コードを指定しなくても自動判定で読み込める
+			got, err := ReadRecords(buf.Bytes(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadRecords() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestShiftJISWriterReplacesUnsupportedCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "shift_jis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteAll([][]string{{"title"}, {"ケーキ🎂"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadRecords(buf.Bytes(), "shift_jis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 代替文字はShift_JISの置換文字（SUB）
+	if want := []map[string]string{{"title": "ケーキ\x1a"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadRecords() = %v", got)
+	}
+}
+
+func TestNewWriterUnsupportedEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf, "euc-jp"); err == nil {
+		t.Error("NewWriter() error = nil")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes", buf.Len())
+	}
+}
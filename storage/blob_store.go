@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"os"
+)
+
+// BlobStore はアップロードされたファイルの保存先を抽象化する
+type BlobStore interface {
+	// Put はkeyにdataを保存し、公開URLを返す
+	Put(key string, data []byte, contentType string) (string, error)
+	Delete(key string) error
+}
+
+// NewBlobStore は環境変数STORAGE_DRIVERに応じてBlobStoreを生成する（未指定の場合はローカル）
+func NewBlobStore() BlobStore {
+	if os.Getenv("STORAGE_DRIVER") == "s3" {
+		return NewS3BlobStore(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		})
+	}
+	return NewLocalBlobStore(UploadDir(), os.Getenv("UPLOAD_BASE_URL"))
+}
+
+// UploadDir はローカル保存先のディレクトリを返す
+func UploadDir() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return "uploads"
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localBlobStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalBlobStore はdir配下にファイルを保存し、baseURL + "/uploads/" + keyを公開URLとする
+func NewLocalBlobStore(dir string, baseURL string) BlobStore {
+	return &localBlobStore{dir, strings.TrimRight(baseURL, "/")}
+}
+
+func (ls *localBlobStore) Put(key string, data []byte, contentType string) (string, error) {
+	path, err := ls.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return ls.baseURL + "/uploads/" + key, nil
+}
+
+func (ls *localBlobStore) Delete(key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (ls *localBlobStore) path(key string) (string, error) {
+	// dirの外に書き込めないようにする
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", errors.New("invalid key")
+	}
+	return filepath.Join(ls.dir, clean), nil
+}
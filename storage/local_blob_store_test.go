@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalBlobStorePutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	bs := NewLocalBlobStore(dir, "http://localhost:8080/")
+
+	url, err := bs.Put("reviewPosts/1/abc_original.png", []byte("data"), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://localhost:8080/uploads/reviewPosts/1/abc_original.png"; url != want {
+		t.Errorf("url = %q, want %q", url, want)
+	}
+
+	path := filepath.Join(dir, "reviewPosts", "1", "abc_original.png")
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "data" {
+		t.Errorf("stored %q, want %q", got, "data")
+	}
+
+	if err := bs.Delete("reviewPosts/1/abc_original.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+
+	// 存在しないファイルの削除はエラーにしない
+	if err := bs.Delete("reviewPosts/1/abc_original.png"); err != nil {
+		t.Errorf("Delete() of missing file = %v", err)
+	}
+}
+
+func TestLocalBlobStoreStaysInsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	bs := NewLocalBlobStore(dir, "")
+
+	if _, err := bs.Put("../../escape.txt", []byte("x"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); !os.IsNotExist(err) {
+		t.Error("Put() wrote outside the upload directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); err != nil {
+		t.Errorf("Put() did not write inside the upload directory: %v", err)
+	}
+
+	for _, key := range []string{"", "/", ".."} {
+		if _, err := bs.Put(key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) accepted an invalid key", key)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Config はS3互換ストレージ（AWS S3, MinIOなど）への接続設定
+type S3Config struct {
+	Endpoint        string // 例: https://s3.ap-northeast-1.amazonaws.com, http://localhost:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PublicURL       string // 未指定の場合は Endpoint/Bucket を使用
+}
+
+type s3BlobStore struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3BlobStore はパス形式（Endpoint/Bucket/Key）でアクセスするS3互換のBlobStoreを生成する
+func NewS3BlobStore(cfg S3Config) BlobStore {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &s3BlobStore{cfg, &http.Client{Timeout: 30 * time.Second}, time.Now}
+}
+
+func (ss *s3BlobStore) Put(key string, data []byte, contentType string) (string, error) {
+	req, err := ss.newRequest(http.MethodPut, key, data)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := ss.do(req); err != nil {
+		return "", err
+	}
+
+	if ss.cfg.PublicURL != "" {
+		return ss.cfg.PublicURL + "/" + escapeKey(key), nil
+	}
+	return ss.objectURL(key), nil
+}
+
+func (ss *s3BlobStore) Delete(key string) error {
+	req, err := ss.newRequest(http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	return ss.do(req)
+}
+
+func (ss *s3BlobStore) objectURL(key string) string {
+	return ss.cfg.Endpoint + "/" + ss.cfg.Bucket + "/" + escapeKey(key)
+}
+
+func (ss *s3BlobStore) newRequest(method string, key string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, ss.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	ss.sign(req, body)
+	return req, nil
+}
+
+func (ss *s3BlobStore) do(req *http.Request) error {
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign はAWS Signature Version 4でリクエストに署名する
+func (ss *s3BlobStore) sign(req *http.Request, body []byte) {
+	now := ss.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + ss.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+ss.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, ss.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		ss.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
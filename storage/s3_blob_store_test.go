@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type s3Request struct {
+	method      string
+	path        string
+	body        string
+	contentType string
+	auth        string
+	amzDate     string
+	payloadHash string
+}
+
+func newTestS3Server(t *testing.T, status int) (*httptest.Server, *[]s3Request) {
+	t.Helper()
+	requests := []s3Request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, s3Request{
+			method:      r.Method,
+			path:        r.URL.EscapedPath(),
+			body:        string(body),
+			contentType: r.Header.Get("Content-Type"),
+			auth:        r.Header.Get("Authorization"),
+			amzDate:     r.Header.Get("X-Amz-Date"),
+			payloadHash: r.Header.Get("X-Amz-Content-Sha256"),
+		})
+		w.WriteHeader(status)
+		if status >= 300 {
+			io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestS3BlobStore(endpoint string, publicURL string) *s3BlobStore {
+	bs := NewS3BlobStore(S3Config{
+		Endpoint:        endpoint,
+		Region:          "ap-northeast-1",
+		Bucket:          "bucket",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		PublicURL:       publicURL,
+	}).(*s3BlobStore)
+	bs.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return bs
+}
+
+func TestS3BlobStorePut(t *testing.T) {
+	srv, requests := newTestS3Server(t, http.StatusOK)
+	bs := newTestS3BlobStore(srv.URL+"/", "")
+
+	url, err := bs.Put("reviewPosts/1/a b.png", []byte("data"), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := srv.URL + "/bucket/reviewPosts/1/a%20b.png"; url != want {
+		t.Errorf("url = %q, want %q", url, want)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	r := (*requests)[0]
+	if r.method != http.MethodPut || r.path != "/bucket/reviewPosts/1/a%20b.png" || r.body != "data" || r.contentType != "image/png" {
+		t.Errorf("request = %+v", r)
+	}
+	if r.amzDate != "20240301T120000Z" {
+		t.Errorf("X-Amz-Date = %q", r.amzDate)
+	}
+	if r.payloadHash != sha256Hex([]byte("data")) {
+		t.Errorf("X-Amz-Content-Sha256 = %q", r.payloadHash)
+	}
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKID/20240301/ap-northeast-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(r.auth, wantPrefix) || len(r.auth) != len(wantPrefix)+64 {
+		t.Errorf("Authorization = %q", r.auth)
+	}
+}
+
+func TestS3BlobStorePutUsesPublicURL(t *testing.T) {
+	srv, _ := newTestS3Server(t, http.StatusOK)
+	bs := newTestS3BlobStore(srv.URL, "https://cdn.example.com/")
+
+	url, err := bs.Put("reviewPosts/1/a.png", []byte("data"), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://cdn.example.com/reviewPosts/1/a.png"; url != want {
+		t.Errorf("url = %q, want %q", url, want)
+	}
+}
+
+func TestS3BlobStoreDelete(t *testing.T) {
+	srv, requests := newTestS3Server(t, http.StatusNoContent)
+	bs := newTestS3BlobStore(srv.URL, "")
+
+	if err := bs.Delete("reviewPosts/1/a.png"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 || (*requests)[0].method != http.MethodDelete || (*requests)[0].path != "/bucket/reviewPosts/1/a.png" {
+		t.Errorf("requests = %+v", *requests)
+	}
+}
+
+func TestS3BlobStoreErrorStatus(t *testing.T) {
+	srv, _ := newTestS3Server(t, http.StatusForbidden)
+	bs := newTestS3BlobStore(srv.URL, "")
+
+	_, err := bs.Put("reviewPosts/1/a.png", []byte("data"), "image/png")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Put() = %v, want an error with status and body", err)
+	}
+	if err := bs.Delete("reviewPosts/1/a.png"); err == nil {
+		t.Error("Delete() = nil, want an error")
+	}
+}
+
+func TestS3BlobStoreSignatureIsDeterministic(t *testing.T) {
+	bs := newTestS3BlobStore("http://localhost:9000", "")
+
+	sign := func(body string) string {
+		req, err := bs.newRequest(http.MethodPut, "a.png", []byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req.Header.Get("Authorization")
+	}
+	if sign("data") != sign("data") {
+		t.Error("same request produced different signatures")
+	}
+	if sign("data") == sign("other") {
+		t.Error("different payloads produced the same signature")
+	}
+}
@@ -1,25 +1,36 @@
 package usecase
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"merchandise-review-list-backend/model"
 	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/spreadsheet"
 	"merchandise-review-list-backend/validator"
+	"strconv"
+	"time"
 )
 
 type IBudgetUsecase interface {
 	CreateProduct(budget model.Budget) (model.BudgetResponse, error)
 	UpdateBudget(budget model.Budget, userId uint, id uint) (model.BudgetResponse, error)
 	GetBudgetByUserId(userId uint, year string, month string) (model.BudgetResponse, error)
+	ExportBudgetActuals(w io.Writer, req model.BudgetExportRequest, userId uint) error
 }
 
 type budgetUsecase struct {
-	br repository.IBudgetRepository
-	bv validator.IBudgetValidator
+	br  repository.IBudgetRepository
+	bv  validator.IBudgetValidator
+	cgr repository.ICategoryRepository
+	ur  repository.IUserRepository
+	mr  repository.IMoneyManagementRepository
 }
 
-func NweBudgetUsecase(br repository.IBudgetRepository, bv validator.IBudgetValidator) IBudgetUsecase {
-	return &budgetUsecase{br, bv}
+func NweBudgetUsecase(br repository.IBudgetRepository, bv validator.IBudgetValidator, cgr repository.ICategoryRepository, ur repository.IUserRepository, mr repository.IMoneyManagementRepository) IBudgetUsecase {
+	return &budgetUsecase{br, bv, cgr, ur, mr}
 }
 
 func (bu *budgetUsecase) CreateProduct(budget model.Budget) (model.BudgetResponse, error) {
@@ -33,7 +44,7 @@ func (bu *budgetUsecase) CreateProduct(budget model.Budget) (model.BudgetRespons
 		return model.BudgetResponse{}, errors.New("duplicate budget")
 	}
 
-	if err := bu.bv.BudgetValidator(budget); err != nil {
+	if err := bu.validateBudget(&budget); err != nil {
 		return model.BudgetResponse{}, err
 	}
 
@@ -41,28 +52,11 @@ func (bu *budgetUsecase) CreateProduct(budget model.Budget) (model.BudgetRespons
 		return model.BudgetResponse{}, err
 	}
 
-	resBudget := model.BudgetResponse{
-		ID:            budget.ID,
-		Month:         budget.Month,
-		Year:          budget.Year,
-		TotalPrice:    budget.TotalPrice,
-		Food:          budget.Food,
-		Drink:         budget.Drink,
-		Book:          budget.Book,
-		Fashion:       budget.Fashion,
-		Furniture:     budget.Furniture,
-		GamesToys:     budget.GamesToys,
-		Beauty:        budget.Beauty,
-		EveryDayItems: budget.EveryDayItems,
-		Other:         budget.Other,
-		Notice:        budget.Notice,
-		CreatedAt:     budget.CreatedAt,
-	}
-	return resBudget, nil
+	return toBudgetResponse(budget), nil
 }
 
 func (bu *budgetUsecase) UpdateBudget(budget model.Budget, userId uint, id uint) (model.BudgetResponse, error) {
-	if err := bu.bv.BudgetValidator(budget); err != nil {
+	if err := bu.validateBudget(&budget); err != nil {
 		return model.BudgetResponse{}, err
 	}
 
@@ -70,35 +64,166 @@ func (bu *budgetUsecase) UpdateBudget(budget model.Budget, userId uint, id uint)
 		return model.BudgetResponse{}, err
 	}
 
-	resBudget := model.BudgetResponse{
-		ID:            budget.ID,
-		Month:         budget.Month,
-		Year:          budget.Year,
-		TotalPrice:    budget.TotalPrice,
-		Food:          budget.Food,
-		Drink:         budget.Drink,
-		Book:          budget.Book,
-		Fashion:       budget.Fashion,
-		Furniture:     budget.Furniture,
-		GamesToys:     budget.GamesToys,
-		Beauty:        budget.Beauty,
-		EveryDayItems: budget.EveryDayItems,
-		Other:         budget.Other,
-		Notice:        budget.Notice,
-		CreatedAt:     budget.CreatedAt,
-	}
-
-	return resBudget, nil
+	return toBudgetResponse(budget), nil
 }
 
+// GetBudgetByUserId は指定した年月の予算を返す
+// 年・月が指定されない場合は、ユーザーのタイムゾーンでの現在の年・月とする
 func (bu *budgetUsecase) GetBudgetByUserId(userId uint, year string, month string) (model.BudgetResponse, error) {
+	if year == "" || month == "" {
+		loc, err := userLocation(bu.ur, userId)
+		if err != nil {
+			return model.BudgetResponse{}, err
+		}
+		now := time.Now().In(loc)
+		if year == "" {
+			year = strconv.Itoa(now.Year())
+		}
+		if month == "" {
+			month = strconv.Itoa(int(now.Month()))
+		}
+	}
+
 	budget := model.Budget{}
 	err := bu.br.GetBudgetByUserId(&budget, userId, year, month)
 	if err != nil {
 		return model.BudgetResponse{}, err
 	}
 
-	resBudget := model.BudgetResponse{
+	return toBudgetResponse(budget), nil
+}
+
+// ExportBudgetActuals は指定した年の月・カテゴリーごとの予算と家計簿の実績（購入日で集計）を書き出す
+func (bu *budgetUsecase) ExportBudgetActuals(w io.Writer, req model.BudgetExportRequest, userId uint) error {
+	if err := bu.bv.BudgetExportValidator(req); err != nil {
+		return err
+	}
+
+	year, _ := strconv.Atoi(req.Year)
+	from, to := yearRange(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+
+	budgets := []model.Budget{}
+	if err := bu.br.GetBudgetsByYear(&budgets, userId, req.Year); err != nil {
+		return err
+	}
+	totals := []model.MoneyManagementMonthlyTotal{}
+	if err := bu.mr.GetMonthlyCategoryTotals(&totals, userId, from, to); err != nil {
+		return err
+	}
+	categories := []model.Category{}
+	if err := bu.cgr.GetCategories(&categories); err != nil {
+		return err
+	}
+
+	rows := toBudgetActualRows(year, budgets, totals, categories)
+
+	if req.Format == model.ExportFormatJSON {
+		bw := bufio.NewWriter(w)
+		if err := json.NewEncoder(bw).Encode(map[string]interface{}{"year": req.Year, "rows": rows}); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+
+	cw, err := spreadsheet.NewWriter(w, req.Encoding)
+	if err != nil {
+		return err
+	}
+	records := [][]string{{"month", "category", "category_name", "budget", "actual", "difference"}}
+	for _, r := range rows {
+		records = append(records, []string{
+			r.Month,
+			r.Category,
+			r.Name,
+			strconv.FormatUint(uint64(r.Budget), 10),
+			strconv.FormatUint(uint64(r.Actual), 10),
+			strconv.Itoa(r.Difference),
+		})
+	}
+	return cw.WriteAll(records)
+}
+
+// toBudgetActualRows は1月から12月まで、カテゴリーごとの行と月の合計の行を作成する
+func toBudgetActualRows(year int, budgets []model.Budget, totals []model.MoneyManagementMonthlyTotal, categories []model.Category) []model.BudgetActualResponse {
+	budgetByMonth := map[string]model.Budget{}
+	for _, b := range budgets {
+		month, err := strconv.Atoi(b.Month)
+		if err != nil {
+			continue
+		}
+		budgetByMonth[fmt.Sprintf("%04d-%02d", year, month)] = b
+	}
+	actuals := map[string]map[string]uint{}
+	for _, t := range totals {
+		if actuals[t.Month] == nil {
+			actuals[t.Month] = map[string]uint{}
+		}
+		actuals[t.Month][t.Category] += t.TotalPrice
+	}
+
+	rows := []model.BudgetActualResponse{}
+	for m := 1; m <= 12; m++ {
+		month := fmt.Sprintf("%04d-%02d", year, m)
+		budget := budgetByMonth[month]
+		columns := budget.LegacyCategoryColumns()
+
+		var actualTotal uint
+		for _, actual := range actuals[month] {
+			actualTotal += actual
+		}
+		for _, c := range categories {
+			amount, ok := budget.Amounts[c.Key]
+			// カテゴリーごとの予算がない以前の予算は固定カラムの値を使う
+			if !ok {
+				if column, ok := columns[c.Key]; ok {
+					amount = *column
+				}
+			}
+			actual := actuals[month][c.Key]
+			rows = append(rows, model.BudgetActualResponse{
+				Month:      month,
+				Category:   c.Key,
+				Name:       c.NameJa,
+				Budget:     amount,
+				Actual:     actual,
+				Difference: int(amount) - int(actual),
+			})
+		}
+		rows = append(rows, model.BudgetActualResponse{
+			Month:      month,
+			Name:       "合計",
+			Budget:     budget.TotalPrice,
+			Actual:     actualTotal,
+			Difference: int(budget.TotalPrice) - int(actualTotal),
+		})
+	}
+	return rows
+}
+
+// validateBudget はカテゴリーごとの予算と固定カラムを揃えたうえで検証する
+// amountsが送られない場合は固定カラムの値から作成する
+func (bu *budgetUsecase) validateBudget(budget *model.Budget) error {
+	columns := budget.LegacyCategoryColumns()
+	if budget.Amounts == nil {
+		budget.Amounts = map[string]uint{}
+		for key, column := range columns {
+			budget.Amounts[key] = *column
+		}
+	} else {
+		for key, column := range columns {
+			*column = budget.Amounts[key]
+		}
+	}
+
+	categoryKeys, err := bu.cgr.GetCategoryKeys()
+	if err != nil {
+		return err
+	}
+	return bu.bv.BudgetValidator(*budget, categoryKeys)
+}
+
+func toBudgetResponse(budget model.Budget) model.BudgetResponse {
+	return model.BudgetResponse{
 		ID:            budget.ID,
 		Month:         budget.Month,
 		Year:          budget.Year,
@@ -113,8 +238,7 @@ func (bu *budgetUsecase) GetBudgetByUserId(userId uint, year string, month strin
 		EveryDayItems: budget.EveryDayItems,
 		Other:         budget.Other,
 		Notice:        budget.Notice,
+		Amounts:       budget.Amounts,
 		CreatedAt:     budget.CreatedAt,
 	}
-
-	return resBudget, nil
 }
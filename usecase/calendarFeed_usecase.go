@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"merchandise-review-list-backend/ical"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"strings"
+	"time"
+)
+
+type ICalendarFeedUsecase interface {
+	GetFeed(userId uint, baseUrl string) (model.CalendarFeedResponse, error)
+	RegenerateFeed(userId uint, baseUrl string) (model.CalendarFeedResponse, error)
+	GetICS(token string) ([]byte, error)
+}
+
+type calendarFeedUsecase struct {
+	cfr repository.ICalendarFeedRepository
+	ur  repository.IUserRepository
+	nr  repository.INotificationRepository
+}
+
+func NewCalendarFeedUsecase(cfr repository.ICalendarFeedRepository, ur repository.IUserRepository, nr repository.INotificationRepository) ICalendarFeedUsecase {
+	return &calendarFeedUsecase{cfr, ur, nr}
+}
+
+// GetFeed はカレンダーのURLを返す。未作成の場合は作成する
+func (cfu *calendarFeedUsecase) GetFeed(userId uint, baseUrl string) (model.CalendarFeedResponse, error) {
+	feed := model.CalendarFeed{}
+	if err := cfu.cfr.GetFeedByUserId(&feed, userId); err != nil {
+		return model.CalendarFeedResponse{}, err
+	}
+	if feed.ID == 0 {
+		return cfu.RegenerateFeed(userId, baseUrl)
+	}
+	return toCalendarFeedResponse(feed, baseUrl), nil
+}
+
+// RegenerateFeed は新しいトークンを発行する。古いURLは使用できなくなる
+func (cfu *calendarFeedUsecase) RegenerateFeed(userId uint, baseUrl string) (model.CalendarFeedResponse, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return model.CalendarFeedResponse{}, err
+	}
+
+	feed := model.CalendarFeed{Token: token, UserId: userId}
+	if err := cfu.cfr.UpsertFeed(&feed); err != nil {
+		return model.CalendarFeedResponse{}, err
+	}
+	return toCalendarFeedResponse(feed, baseUrl), nil
+}
+
+// GetICS は期限がある商品ごとに予定を作成したiCalendarを返す
+// 通知（VALARM）は期限の通知設定のタイミングを使用する
+func (cfu *calendarFeedUsecase) GetICS(token string) ([]byte, error) {
+	feed := model.CalendarFeed{}
+	if err := cfu.cfr.GetFeedByToken(&feed, token); err != nil {
+		return nil, err
+	}
+
+	user := model.User{}
+	if err := cfu.ur.GetUserByID(&user, feed.UserId); err != nil {
+		return nil, err
+	}
+	setting := model.NotificationSetting{}
+	if err := cfu.nr.GetSetting(&setting, feed.UserId); err != nil {
+		return nil, err
+	}
+	if setting.ID == 0 {
+		setting = defaultNotificationSetting(feed.UserId)
+	}
+	alarms := parseLeadMinutes(setting.LeadMinutes)
+
+	products := []model.Product{}
+	if err := cfu.cfr.GetProductsWithTimeLimit(&products, feed.UserId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	calendar := ical.Calendar{
+		ProdId:   "-//merchandise-review-list//product deadlines//JA",
+		Name:     "商品の期限",
+		TimeZone: user.Location().String(),
+	}
+	for _, p := range products {
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("product-%d@merchandise-review-list", p.ID),
+			Summary:     fmt.Sprintf("期限: %s", p.Name),
+			Description: productEventDescription(p),
+			URL:         p.Url,
+			Start:       *p.TimeLimit,
+			Stamp:       now,
+			Alarms:      alarms,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func productEventDescription(p model.Product) string {
+	lines := []string{fmt.Sprintf("価格: %d円", p.Price)}
+	if p.Notes != "" {
+		lines = append(lines, p.Notes)
+	}
+	if p.Url != "" {
+		lines = append(lines, p.Url)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func toCalendarFeedResponse(feed model.CalendarFeed, baseUrl string) model.CalendarFeedResponse {
+	url := strings.TrimRight(baseUrl, "/") + "/calendar/" + feed.Token + ".ics"
+	webcalUrl := url
+	if i := strings.Index(url, "://"); i >= 0 {
+		webcalUrl = "webcal" + url[i:]
+	}
+	return model.CalendarFeedResponse{
+		Url:       url,
+		WebcalUrl: webcalUrl,
+		UpdatedAt: feed.UpdatedAt,
+	}
+}
+
+func newCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+)
+
+type ICategoryUsecase interface {
+	GetCategories(lang string) ([]model.CategoryResponse, error)
+}
+
+type categoryUsecase struct {
+	cgr repository.ICategoryRepository
+}
+
+func NewCategoryUsecase(cgr repository.ICategoryRepository) ICategoryUsecase {
+	return &categoryUsecase{cgr}
+}
+
+// GetCategories はlangに応じた表示名（ja/en、既定はja）を付けてカテゴリー一覧を返す
+func (cu *categoryUsecase) GetCategories(lang string) ([]model.CategoryResponse, error) {
+	categories := []model.Category{}
+	if err := cu.cgr.GetCategories(&categories); err != nil {
+		return nil, err
+	}
+
+	resCategories := []model.CategoryResponse{}
+	for _, v := range categories {
+		name := v.NameJa
+		if lang == "en" {
+			name = v.NameEn
+		}
+		c := model.CategoryResponse{
+			Key:      v.Key,
+			Name:     name,
+			NameJa:   v.NameJa,
+			NameEn:   v.NameEn,
+			Icon:     v.Icon,
+			Position: v.Position,
+		}
+		resCategories = append(resCategories, c)
+	}
+	return resCategories, nil
+}
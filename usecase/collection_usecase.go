@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+)
+
+type ICollectionUsecase interface {
+	CreateCollection(collection model.Collection) (model.CollectionResponse, error)
+	UpdateCollection(collection model.Collection, userId uint, id uint) (model.CollectionResponse, error)
+	DeleteCollection(userId uint, id uint) error
+	GetMyCollections(userId uint) ([]model.CollectionResponse, error)
+	GetMyCollectionById(userId uint, id uint) (model.CollectionDetailResponse, error)
+	GetSharedCollection(slug string, userId uint) (model.CollectionDetailResponse, error)
+	AddItem(userId uint, id uint, postId uint) (model.CollectionDetailResponse, error)
+	RemoveItem(userId uint, id uint, postId uint) error
+	ReorderItems(userId uint, id uint, postIds []uint) (model.CollectionDetailResponse, error)
+}
+
+type collectionUsecase struct {
+	cr repository.ICollectionRepository
+	cv validator.ICollectionValidator
+	rr repository.IReviewPostRepository
+	ru IReviewPostUsecase
+}
+
+func NewCollectionUsecase(
+	cr repository.ICollectionRepository,
+	cv validator.ICollectionValidator,
+	rr repository.IReviewPostRepository,
+	ru IReviewPostUsecase,
+) ICollectionUsecase {
+	return &collectionUsecase{cr, cv, rr, ru}
+}
+
+func (cu *collectionUsecase) CreateCollection(collection model.Collection) (model.CollectionResponse, error) {
+	if collection.Visibility == "" {
+		collection.Visibility = model.CollectionVisibilityPrivate
+	}
+	if err := cu.cv.CollectionValidator(collection); err != nil {
+		return model.CollectionResponse{}, err
+	}
+
+	slug, err := newShareSlug()
+	if err != nil {
+		return model.CollectionResponse{}, err
+	}
+	collection.ShareSlug = slug
+
+	if err := cu.cr.CreateCollection(&collection); err != nil {
+		return model.CollectionResponse{}, err
+	}
+	return toCollectionResponse(collection, 0), nil
+}
+
+func (cu *collectionUsecase) UpdateCollection(collection model.Collection, userId uint, id uint) (model.CollectionResponse, error) {
+	// 公開範囲が指定されていない場合は現在の公開範囲のままにする
+	if collection.Visibility == "" {
+		current := model.Collection{}
+		if err := cu.cr.GetCollectionById(&current, userId, id); err != nil {
+			return model.CollectionResponse{}, err
+		}
+		collection.Visibility = current.Visibility
+	}
+	if err := cu.cv.CollectionValidator(collection); err != nil {
+		return model.CollectionResponse{}, err
+	}
+	if err := cu.cr.UpdateCollection(&collection, userId, id); err != nil {
+		return model.CollectionResponse{}, err
+	}
+
+	itemCount, err := cu.cr.CountItems(id)
+	if err != nil {
+		return model.CollectionResponse{}, err
+	}
+	return toCollectionResponse(collection, itemCount), nil
+}
+
+func (cu *collectionUsecase) DeleteCollection(userId uint, id uint) error {
+	if err := cu.cr.DeleteCollection(userId, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cu *collectionUsecase) GetMyCollections(userId uint) ([]model.CollectionResponse, error) {
+	collections := []model.Collection{}
+	if err := cu.cr.GetMyCollections(&collections, userId); err != nil {
+		return nil, err
+	}
+
+	resCollections := []model.CollectionResponse{}
+	for _, v := range collections {
+		itemCount, err := cu.cr.CountItems(v.ID)
+		if err != nil {
+			return nil, err
+		}
+		resCollections = append(resCollections, toCollectionResponse(v, itemCount))
+	}
+	return resCollections, nil
+}
+
+func (cu *collectionUsecase) GetMyCollectionById(userId uint, id uint) (model.CollectionDetailResponse, error) {
+	collection := model.Collection{}
+	if err := cu.cr.GetCollectionById(&collection, userId, id); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	return cu.toCollectionDetailResponse(collection, userId)
+}
+
+// GetSharedCollection は共有用URLからの閲覧で、共有設定のコレクションのみ返す
+func (cu *collectionUsecase) GetSharedCollection(slug string, userId uint) (model.CollectionDetailResponse, error) {
+	collection := model.Collection{}
+	if err := cu.cr.GetCollectionBySlug(&collection, slug); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	return cu.toCollectionDetailResponse(collection, userId)
+}
+
+func (cu *collectionUsecase) AddItem(userId uint, id uint, postId uint) (model.CollectionDetailResponse, error) {
+	collection := model.Collection{}
+	if err := cu.cr.GetCollectionById(&collection, userId, id); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+
+	reviewPost := model.ReviewPost{}
+	if err := cu.rr.GetReviewPostById(&reviewPost, postId); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	if reviewPost.Status != model.ReviewPostStatusPublished {
+		return model.CollectionDetailResponse{}, errors.New("object does not exist")
+	}
+
+	item := model.CollectionItem{
+		CollectionId: collection.ID,
+		PostId:       postId,
+	}
+	if err := cu.cr.AddItem(&item); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	return cu.toCollectionDetailResponse(collection, userId)
+}
+
+func (cu *collectionUsecase) RemoveItem(userId uint, id uint, postId uint) error {
+	collection := model.Collection{}
+	if err := cu.cr.GetCollectionById(&collection, userId, id); err != nil {
+		return err
+	}
+	if err := cu.cr.RemoveItem(collection.ID, postId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cu *collectionUsecase) ReorderItems(userId uint, id uint, postIds []uint) (model.CollectionDetailResponse, error) {
+	collection := model.Collection{}
+	if err := cu.cr.GetCollectionById(&collection, userId, id); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+
+	itemCount, err := cu.cr.CountItems(collection.ID)
+	if err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	if itemCount != len(postIds) {
+		return model.CollectionDetailResponse{}, errors.New("post_ids must contain every item of the collection")
+	}
+
+	if err := cu.cr.UpdateItemPositions(collection.ID, postIds); err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+	return cu.toCollectionDetailResponse(collection, userId)
+}
+
+func (cu *collectionUsecase) toCollectionDetailResponse(collection model.Collection, userId uint) (model.CollectionDetailResponse, error) {
+	postIds, err := cu.cr.GetItemPostIds(collection.ID)
+	if err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+
+	items, err := cu.ru.GetReviewPostsByIds(postIds, userId)
+	if err != nil {
+		return model.CollectionDetailResponse{}, err
+	}
+
+	return model.CollectionDetailResponse{
+		CollectionResponse: toCollectionResponse(collection, len(postIds)),
+		Items:              items,
+	}, nil
+}
+
+func toCollectionResponse(collection model.Collection, itemCount int) model.CollectionResponse {
+	return model.CollectionResponse{
+		ID:          collection.ID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Visibility:  collection.Visibility,
+		ShareSlug:   collection.ShareSlug,
+		ItemCount:   uint(itemCount),
+		CreatedAt:   collection.CreatedAt,
+		UpdatedAt:   collection.UpdatedAt,
+	}
+}
+
+func newShareSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
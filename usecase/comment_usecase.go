@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"errors"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
 	"merchandise-review-list-backend/repository"
 	"merchandise-review-list-backend/validator"
 )
@@ -16,10 +18,18 @@ type commentUsecase struct {
 	cr repository.ICommentRepository
 	cv validator.ICommentValidator
 	rr repository.IReviewPostRepository
+	mr repository.IModerationRepository
+	md moderation.Moderator
 }
 
-func NewCommentUsecase(cr repository.ICommentRepository, cv validator.ICommentValidator, rr repository.IReviewPostRepository) ICommentUsecase {
-	return &commentUsecase{cr, cv, rr}
+func NewCommentUsecase(
+	cr repository.ICommentRepository,
+	cv validator.ICommentValidator,
+	rr repository.IReviewPostRepository,
+	mr repository.IModerationRepository,
+	md moderation.Moderator,
+) ICommentUsecase {
+	return &commentUsecase{cr, cv, rr, mr, md}
 }
 
 func (cu *commentUsecase) CreateComment(comment model.Comment) (model.CommentResponse, error) {
@@ -27,12 +37,41 @@ func (cu *commentUsecase) CreateComment(comment model.Comment) (model.CommentRes
 		return model.CommentResponse{}, err
 	}
 
+	// 公開されていない投稿にはコメントできない
+	reviewPost := model.ReviewPost{}
+	if err := cu.rr.GetReviewPostById(&reviewPost, comment.PostId); err != nil {
+		return model.CommentResponse{}, err
+	}
+	if reviewPost.Status != model.ReviewPostStatusPublished {
+		return model.CommentResponse{}, errors.New("object does not exist")
+	}
+
+	verdict, err := moderate(cu.md, &moderation.Content{
+		Kind:   moderation.KindComment,
+		UserId: comment.UserId,
+		Fields: []*string{&comment.Text},
+	})
+	if err != nil {
+		return model.CommentResponse{}, err
+	}
+	comment.Status = model.CommentStatusVisible
+	if verdict.Action == moderation.ActionHold {
+		comment.Status = model.CommentStatusPending
+	}
+
 	if err := cu.cr.CreateComment(&comment); err != nil {
 		return model.CommentResponse{}, err
 	}
+	if verdict.Action == moderation.ActionHold {
+		hold := newModerationHold(moderation.KindComment, comment.ID, comment.UserId, verdict)
+		if err := cu.mr.CreateHold(&hold); err != nil {
+			return model.CommentResponse{}, err
+		}
+	}
 
 	resComment := model.CommentResponse{
 		ID:     comment.ID,
+		Status: comment.Status,
 		UserId: comment.UserId,
 	}
 	return resComment, nil
@@ -62,8 +101,9 @@ func (cu *commentUsecase) GetCommentsByPostId(postId uint, page int, pageSize in
 		}
 
 		c := model.CommentResponse{
-			ID:   v.ID,
-			Text: v.Text,
+			ID:     v.ID,
+			Text:   v.Text,
+			Status: v.Status,
 			User: model.CommentUser{
 				ID:    user.ID,
 				Name:  user.Name,
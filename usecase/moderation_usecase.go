@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"errors"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
+	"merchandise-review-list-backend/repository"
+	"strings"
+)
+
+type IModerationUsecase interface {
+	GetPendingHolds(adminId uint, page int, pageSize int) ([]model.ModerationHoldResponse, int, error)
+	ResolveHold(adminId uint, id uint, approve bool) error
+}
+
+type moderationUsecase struct {
+	mr  repository.IModerationRepository
+	ur  repository.IUserRepository
+	rr  repository.IReviewPostRepository
+	cmr repository.ICommentRepository
+}
+
+func NewModerationUsecase(
+	mr repository.IModerationRepository,
+	ur repository.IUserRepository,
+	rr repository.IReviewPostRepository,
+	cmr repository.ICommentRepository,
+) IModerationUsecase {
+	return &moderationUsecase{mr, ur, rr, cmr}
+}
+
+func (mu *moderationUsecase) GetPendingHolds(adminId uint, page int, pageSize int) ([]model.ModerationHoldResponse, int, error) {
+	if err := mu.checkAdmin(adminId); err != nil {
+		return nil, 0, err
+	}
+
+	holds := []model.ModerationHold{}
+	totalCount, err := mu.mr.GetPendingHolds(&holds, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resHolds := []model.ModerationHoldResponse{}
+	for _, v := range holds {
+		text, err := mu.getTargetText(v)
+		if err != nil {
+			return nil, 0, err
+		}
+		h := model.ModerationHoldResponse{
+			ID:        v.ID,
+			Kind:      v.Kind,
+			TargetId:  v.TargetId,
+			Reasons:   strings.Split(v.Reasons, "\n"),
+			Status:    v.Status,
+			Text:      text,
+			UserId:    v.UserId,
+			CreatedAt: v.CreatedAt,
+		}
+		resHolds = append(resHolds, h)
+	}
+	return resHolds, totalCount, nil
+}
+
+// ResolveHold は確認待ちの投稿・コメントを承認して公開するか、非公開のまま却下する
+func (mu *moderationUsecase) ResolveHold(adminId uint, id uint, approve bool) error {
+	if err := mu.checkAdmin(adminId); err != nil {
+		return err
+	}
+
+	hold := model.ModerationHold{}
+	if err := mu.mr.GetHoldById(&hold, id); err != nil {
+		return err
+	}
+
+	status := model.ModerationHoldStatusRejected
+	if approve {
+		status = model.ModerationHoldStatusApproved
+	}
+	return mu.mr.ResolveHold(&hold, status, adminId)
+}
+
+func (mu *moderationUsecase) checkAdmin(userId uint) error {
+	user := model.User{}
+	if err := mu.ur.GetUserByID(&user, userId); err != nil {
+		return err
+	}
+	if !user.Admin {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+func (mu *moderationUsecase) getTargetText(hold model.ModerationHold) (string, error) {
+	switch hold.Kind {
+	case moderation.KindReviewPost:
+		reviewPost := model.ReviewPost{}
+		if err := mu.rr.GetReviewPostById(&reviewPost, hold.TargetId); err != nil {
+			return "", err
+		}
+		return reviewPost.Title + "\n" + reviewPost.Text, nil
+	case moderation.KindComment:
+		comment := model.Comment{}
+		if err := mu.cmr.GetCommentById(&comment, hold.TargetId); err != nil {
+			return "", err
+		}
+		return comment.Text, nil
+	}
+	return "", nil
+}
+
+// moderate はテキストを検査し、rejectの場合はエラーを返す（maskの場合はcontentのフィールドが書き換えられる）
+func moderate(md moderation.Moderator, content *moderation.Content) (moderation.Verdict, error) {
+	verdict, err := md.Moderate(content)
+	if err != nil {
+		return moderation.Verdict{}, err
+	}
+	if verdict.Action == moderation.ActionReject {
+		return moderation.Verdict{}, errors.New("contains inappropriate content")
+	}
+	return verdict, nil
+}
+
+func newModerationHold(kind string, targetId uint, userId uint, verdict moderation.Verdict) model.ModerationHold {
+	return model.ModerationHold{
+		Kind:     kind,
+		TargetId: targetId,
+		Reasons:  strings.Join(verdict.Reasons, "\n"),
+		Status:   model.ModerationHoldStatusPending,
+		UserId:   userId,
+	}
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"errors"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"testing"
+)
+
+type fakeUserRepository struct {
+	repository.IUserRepository
+	users map[uint]model.User
+}
+
+func (ur *fakeUserRepository) GetUserByID(user *model.User, id uint) error {
+	u, ok := ur.users[id]
+	if !ok {
+		return errors.New("record not found")
+	}
+	*user = u
+	return nil
+}
+
+type fakeCommentRepository struct {
+	repository.ICommentRepository
+	comments []model.Comment
+}
+
+func (cr *fakeCommentRepository) CreateComment(comment *model.Comment) error {
+	comment.ID = uint(len(cr.comments) + 1)
+	cr.comments = append(cr.comments, *comment)
+	return nil
+}
+
+func TestCreateCommentModerationStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		postId     uint
+		text       string
+		wantErr    bool
+		wantStatus string
+		wantHold   bool
+	}{
+		{name: "clean comment is visible", postId: 1, text: "参考になりました", wantStatus: model.CommentStatusVisible},
+		{name: "held comment is pending", postId: 1, text: "casinoはこちら", wantStatus: model.CommentStatusPending, wantHold: true},
+		{name: "rejected comment is not stored", postId: 1, text: "死ね", wantErr: true},
+		{name: "pending post cannot be commented on", postId: 2, text: "参考になりました", wantErr: true},
+		{name: "draft cannot be commented on", postId: 3, text: "参考になりました", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &fakeCommentRepository{}
+			mr := &fakeModerationRepository{}
+			cu := NewCommentUsecase(cr, validator.NewCommentValidator(), newFakeReviewPostRepository(visibilityPosts()...), mr, testModerator())
+
+			res, err := cu.CreateComment(model.Comment{Text: tt.text, PostId: tt.postId, UserId: 1})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(cr.comments) != 0 || len(mr.holds) != 0 {
+					t.Errorf("stored %d comments and %d holds", len(cr.comments), len(mr.holds))
+				}
+				return
+			}
+
+			if res.Status != tt.wantStatus || cr.comments[0].Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", cr.comments[0].Status, tt.wantStatus)
+			}
+			if tt.wantHold {
+				if len(mr.holds) != 1 || mr.holds[0].Kind != moderation.KindComment || mr.holds[0].TargetId != res.ID {
+					t.Errorf("holds = %+v", mr.holds)
+				}
+			} else if len(mr.holds) != 0 {
+				t.Errorf("unexpected holds %+v", mr.holds)
+			}
+		})
+	}
+}
+
+type resolvingModerationRepository struct {
+	fakeModerationRepository
+	resolved map[uint]string
+}
+
+func (mr *resolvingModerationRepository) GetHoldById(hold *model.ModerationHold, id uint) error {
+	for _, h := range mr.holds {
+		if h.ID == id {
+			*hold = h
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func (mr *resolvingModerationRepository) ResolveHold(hold *model.ModerationHold, status string, resolvedBy uint) error {
+	mr.resolved[hold.ID] = status
+	return nil
+}
+
+func TestResolveHold(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminId    uint
+		holdId     uint
+		approve    bool
+		wantErr    bool
+		wantStatus string
+	}{
+		{name: "approve", adminId: 1, holdId: 1, approve: true, wantStatus: model.ModerationHoldStatusApproved},
+		{name: "reject", adminId: 1, holdId: 1, approve: false, wantStatus: model.ModerationHoldStatusRejected},
+		{name: "non-admin cannot resolve", adminId: 2, holdId: 1, approve: true, wantErr: true},
+		{name: "unknown hold", adminId: 1, holdId: 9, approve: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := &resolvingModerationRepository{resolved: map[uint]string{}}
+			mr.CreateHold(&model.ModerationHold{Kind: moderation.KindReviewPost, TargetId: 2, Status: model.ModerationHoldStatusPending})
+			ur := &fakeUserRepository{users: map[uint]model.User{
+				1: {ID: 1, Admin: true},
+				2: {ID: 2},
+			}}
+			mu := NewModerationUsecase(mr, ur, newFakeReviewPostRepository(visibilityPosts()...), &fakeCommentRepository{})
+
+			err := mu.ResolveHold(tt.adminId, tt.holdId, tt.approve)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveHold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(mr.resolved) != 0 {
+					t.Errorf("resolved = %v", mr.resolved)
+				}
+				return
+			}
+			if mr.resolved[tt.holdId] != tt.wantStatus {
+				t.Errorf("resolved status = %q, want %q", mr.resolved[tt.holdId], tt.wantStatus)
+			}
+		})
+	}
+}
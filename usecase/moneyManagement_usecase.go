@@ -1,10 +1,21 @@
 package usecase
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"merchandise-review-list-backend/model"
 	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/spreadsheet"
 	"merchandise-review-list-backend/validator"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 type IMoneyManagementUsecase interface {
@@ -12,61 +23,70 @@ type IMoneyManagementUsecase interface {
 	UpdateMoneyManagement(moneyManagement model.MoneyManagement, userId uint, id uint) (model.MoneyManagementResponse, error)
 	DeleteMoneyManagement(userId uint, id uint) error
 	GetMyMoneyManagements(userId uint, yearMonth time.Time, yearFlag bool) (model.MoneyManagementByCategoryResponse, error)
+	ImportMoneyManagements(req model.MoneyManagementImportRequest, userId uint) (model.MoneyManagementImportResponse, error)
+	ExportMoneyManagements(w io.Writer, req model.MoneyManagementExportRequest, userId uint) error
 }
 
 type moneyManagementUsecase struct {
-	mr repository.IMoneyManagementRepository
-	mv validator.IMoneyManagementValidator
+	mr  repository.IMoneyManagementRepository
+	mv  validator.IMoneyManagementValidator
+	cgr repository.ICategoryRepository
+	ur  repository.IUserRepository
 }
 
 func NewMoneyManagementUsecase(
 	mr repository.IMoneyManagementRepository,
 	mv validator.IMoneyManagementValidator,
+	cgr repository.ICategoryRepository,
+	ur repository.IUserRepository,
 ) IMoneyManagementUsecase {
-	return &moneyManagementUsecase{mr, mv}
+	return &moneyManagementUsecase{mr, mv, cgr, ur}
 }
 
 func (mu *moneyManagementUsecase) CreateMoneyManagement(moneyManagement model.MoneyManagement) (model.MoneyManagementResponse, error) {
-	if err := mu.mv.MoneyManagementValidator(moneyManagement); err != nil {
+	loc, err := userLocation(mu.ur, moneyManagement.UserId)
+	if err != nil {
 		return model.MoneyManagementResponse{}, err
 	}
+	normalizePurchasedAt(&moneyManagement, loc)
 
-	if err := mu.mr.CreateMoneyManagement(&moneyManagement); err != nil {
+	categoryKeys, err := mu.cgr.GetCategoryKeys()
+	if err != nil {
+		return model.MoneyManagementResponse{}, err
+	}
+	if err := mu.mv.MoneyManagementValidator(moneyManagement, categoryKeys); err != nil {
 		return model.MoneyManagementResponse{}, err
 	}
 
-	resMoneyManagement := model.MoneyManagementResponse{
-		ID:         moneyManagement.ID,
-		Title:      moneyManagement.Title,
-		Category:   moneyManagement.Category,
-		UnitPrice:  moneyManagement.UnitPrice,
-		Quantity:   moneyManagement.Quantity,
-		TotalPrice: moneyManagement.TotalPrice,
-		CreatedAt:  moneyManagement.CreatedAt,
-		UpdatedAt:  moneyManagement.UpdatedAt,
+	// 商品との紐付けは商品からの購入登録でのみ行う
+	moneyManagement.ProductId = nil
+	moneyManagement.ProductUrl = ""
+
+	if err := mu.mr.CreateMoneyManagement(&moneyManagement); err != nil {
+		return model.MoneyManagementResponse{}, err
 	}
-	return resMoneyManagement, nil
+	return toMoneyManagementResponse(moneyManagement, loc), nil
 }
 
 func (mu *moneyManagementUsecase) UpdateMoneyManagement(moneyManagement model.MoneyManagement, userId uint, id uint) (model.MoneyManagementResponse, error) {
-	if err := mu.mv.MoneyManagementValidator(moneyManagement); err != nil {
+	loc, err := userLocation(mu.ur, userId)
+	if err != nil {
 		return model.MoneyManagementResponse{}, err
 	}
+	normalizePurchasedAt(&moneyManagement, loc)
 
-	if err := mu.mr.UpdateMoneyManagement(&moneyManagement, userId, id); err != nil {
+	categoryKeys, err := mu.cgr.GetCategoryKeys()
+	if err != nil {
+		return model.MoneyManagementResponse{}, err
+	}
+	if err := mu.mv.MoneyManagementValidator(moneyManagement, categoryKeys); err != nil {
 		return model.MoneyManagementResponse{}, err
 	}
-	resMoneyManagement := model.MoneyManagementResponse{
-		ID:         moneyManagement.ID,
-		Title:      moneyManagement.Title,
-		Category:   moneyManagement.Category,
-		UnitPrice:  moneyManagement.UnitPrice,
-		Quantity:   moneyManagement.Quantity,
-		TotalPrice: moneyManagement.TotalPrice,
-		CreatedAt:  moneyManagement.CreatedAt,
-		UpdatedAt:  moneyManagement.UpdatedAt,
+
+	if err := mu.mr.UpdateMoneyManagement(&moneyManagement, userId, id); err != nil {
+		return model.MoneyManagementResponse{}, err
 	}
-	return resMoneyManagement, nil
+	return toMoneyManagementResponse(moneyManagement, loc), nil
 }
 
 func (mu *moneyManagementUsecase) DeleteMoneyManagement(userId uint, id uint) error {
@@ -76,159 +96,508 @@ func (mu *moneyManagementUsecase) DeleteMoneyManagement(userId uint, id uint) er
 	return nil
 }
 
+// GetMyMoneyManagements は購入日が指定した年月（yearFlagがtrueの場合は年）の家計簿をカテゴリーごとに集計する
 func (mu *moneyManagementUsecase) GetMyMoneyManagements(userId uint, yearMonth time.Time, yearFlag bool) (model.MoneyManagementByCategoryResponse, error) {
+	loc, err := userLocation(mu.ur, userId)
+	if err != nil {
+		return model.MoneyManagementByCategoryResponse{}, err
+	}
+	// 購入日は日付のみのため、月・年の境界はUTCの日付で比較する
+	from, to := monthRange(yearMonth, time.UTC)
+	if yearFlag {
+		from, to = yearRange(yearMonth, time.UTC)
+	}
+
 	moneyManagement := []model.MoneyManagement{}
-	err := mu.mr.GetMyMoneyManagements(&moneyManagement, userId, yearMonth, yearFlag)
+	err = mu.mr.GetMyMoneyManagements(&moneyManagement, userId, from, to)
 	if err != nil {
 		return model.MoneyManagementByCategoryResponse{}, err
 	}
 
-	res := model.MoneyManagementByCategoryResponse{
-		Food:          model.MoneyManagementByCategoryItemResponse{},
-		Drink:         model.MoneyManagementByCategoryItemResponse{},
-		Book:          model.MoneyManagementByCategoryItemResponse{},
-		Fashion:       model.MoneyManagementByCategoryItemResponse{},
-		Furniture:     model.MoneyManagementByCategoryItemResponse{},
-		GamesToys:     model.MoneyManagementByCategoryItemResponse{},
-		Beauty:        model.MoneyManagementByCategoryItemResponse{},
-		EveryDayItems: model.MoneyManagementByCategoryItemResponse{},
-		Other:         model.MoneyManagementByCategoryItemResponse{},
+	categoryKeys, err := mu.cgr.GetCategoryKeys()
+	if err != nil {
+		return model.MoneyManagementByCategoryResponse{}, err
 	}
 
-	totalPrice := uint(0)
+	// 登録されている全カテゴリーを空の状態で用意する
+	res := model.MoneyManagementByCategoryResponse{
+		Categories: map[string]model.MoneyManagementByCategoryItemResponse{},
+	}
+	for _, key := range categoryKeys {
+		res.Categories[key] = model.MoneyManagementByCategoryItemResponse{}
+	}
 
 	for _, mm := range moneyManagement {
-		switch mm.Category {
-		case "food":
-			res.Food.Items = append(res.Food.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Food.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "drink":
-			res.Drink.Items = append(res.Drink.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Drink.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "book":
-			res.Book.Items = append(res.Book.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Book.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "fashion":
-			res.Fashion.Items = append(res.Fashion.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Fashion.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "furniture":
-			res.Furniture.Items = append(res.Furniture.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Furniture.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "gamesToys":
-			res.GamesToys.Items = append(res.GamesToys.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.GamesToys.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "beauty":
-			res.Beauty.Items = append(res.Beauty.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Beauty.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "everyDayItems":
-			res.EveryDayItems.Items = append(res.EveryDayItems.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.EveryDayItems.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		case "other":
-			res.Other.Items = append(res.Other.Items, model.MoneyManagementResponse{
-				ID:         mm.ID,
-				Title:      mm.Title,
-				Category:   mm.Category,
-				UnitPrice:  mm.UnitPrice,
-				Quantity:   mm.Quantity,
-				TotalPrice: mm.TotalPrice,
-				CreatedAt:  mm.CreatedAt,
-				UpdatedAt:  mm.UpdatedAt,
-			})
-
-			res.Other.ItemTotalPrice += mm.TotalPrice
-			totalPrice += mm.TotalPrice
-		}
-
-		res.TotalPrice = totalPrice
+		item, ok := res.Categories[mm.Category]
+		if !ok {
+			continue
+		}
+		item.Items = append(item.Items, toMoneyManagementResponse(mm, loc))
+		item.ItemTotalPrice += mm.TotalPrice
+		res.Categories[mm.Category] = item
+		res.TotalPrice += mm.TotalPrice
+	}
+
+	// 既存のクライアント向けに固定フィールドにも設定する
+	for key, field := range res.LegacyCategoryFields() {
+		*field = res.Categories[key]
+	}
+
+	return res, nil
+}
+
+// ImportMoneyManagements はCSV・JSONの家計簿を一括登録する
+// 行ごとに検証と重複の確認を行い、エラーが1行でもある場合やDryRunの場合は登録せずに結果のみ返す
+func (mu *moneyManagementUsecase) ImportMoneyManagements(req model.MoneyManagementImportRequest, userId uint) (model.MoneyManagementImportResponse, error) {
+	if err := mu.mv.MoneyManagementImportValidator(req); err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+
+	records, firstRow, err := readImportRecords(req)
+	if err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+	if len(records) > validator.MaxMoneyManagementImportRows {
+		return model.MoneyManagementImportResponse{}, fmt.Errorf("too many rows (max %d)", validator.MaxMoneyManagementImportRows)
+	}
+
+	loc, err := userLocation(mu.ur, userId)
+	if err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+	categories := []model.Category{}
+	if err := mu.cgr.GetCategories(&categories); err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+	categoryKeys := []string{}
+	for _, c := range categories {
+		categoryKeys = append(categoryKeys, c.Key)
+	}
+
+	mapping := map[string]string{}
+	for field, column := range model.DefaultMoneyManagementImportMapping {
+		mapping[field] = column
+	}
+	for field, column := range req.Mapping {
+		mapping[field] = column
+	}
+
+	res := model.MoneyManagementImportResponse{
+		DryRun:    req.DryRun,
+		TotalRows: len(records),
+		Rows:      []model.MoneyManagementImportRowResponse{},
+	}
+	moneyManagements := []model.MoneyManagement{}
+	for i, record := range records {
+		row := model.MoneyManagementImportRowResponse{Row: firstRow + i, Status: model.MoneyManagementImportRowOk}
+
+		mm, errs := toImportMoneyManagement(record, mapping, categories, loc)
+		mm.UserId = userId
+		if err := mu.mv.MoneyManagementValidator(mm, categoryKeys); err != nil {
+			for field, msg := range validator.FieldErrors(err) {
+				if _, ok := errs[field]; !ok {
+					errs[field] = msg
+				}
+			}
+		}
+		if len(errs) > 0 {
+			row.Status = model.MoneyManagementImportRowError
+			row.Errors = errs
+			res.ErrorCount++
+		}
+
+		res.Rows = append(res.Rows, row)
+		moneyManagements = append(moneyManagements, mm)
 	}
 
+	if err := mu.markImportDuplicates(&res, moneyManagements, userId, req.AllowDuplicates); err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+
+	targets := []model.MoneyManagement{}
+	targetRows := []int{}
+	for i, row := range res.Rows {
+		if row.Status == model.MoneyManagementImportRowOk {
+			targets = append(targets, moneyManagements[i])
+			targetRows = append(targetRows, i)
+		}
+	}
+	res.ImportCount = len(targets)
+
+	if req.DryRun || res.ErrorCount > 0 || len(targets) == 0 {
+		return res, nil
+	}
+	if err := mu.mr.CreateMoneyManagements(&targets); err != nil {
+		return model.MoneyManagementImportResponse{}, err
+	}
+	res.Committed = true
+	for i, mm := range targets {
+		item := toMoneyManagementResponse(mm, loc)
+		res.Rows[targetRows[i]].Item = &item
+	}
 	return res, nil
 }
+
+// markImportDuplicates は登録済みの家計簿、またはファイル内の前の行と同じ購入日・名前・カテゴリー・合計金額の行を重複とする
+func (mu *moneyManagementUsecase) markImportDuplicates(res *model.MoneyManagementImportResponse, moneyManagements []model.MoneyManagement, userId uint, allowDuplicates bool) error {
+	var from, to time.Time
+	for i, mm := range moneyManagements {
+		if res.Rows[i].Status != model.MoneyManagementImportRowOk {
+			continue
+		}
+		if from.IsZero() || mm.PurchasedAt.Before(from) {
+			from = mm.PurchasedAt
+		}
+		if to.IsZero() || mm.PurchasedAt.After(to) {
+			to = mm.PurchasedAt
+		}
+	}
+	if from.IsZero() || allowDuplicates {
+		return nil
+	}
+
+	to = to.AddDate(0, 0, 1)
+	existing := []model.MoneyManagement{}
+	if err := mu.mr.GetMyMoneyManagements(&existing, userId, from, to); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, mm := range existing {
+		seen[importDuplicateKey(mm)] = true
+	}
+
+	for i, mm := range moneyManagements {
+		if res.Rows[i].Status != model.MoneyManagementImportRowOk {
+			continue
+		}
+		key := importDuplicateKey(mm)
+		if seen[key] {
+			res.Rows[i].Status = model.MoneyManagementImportRowDuplicate
+			res.DuplicateCount++
+			continue
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// readImportRecords はファイルを行の一覧にし、最初の行の番号と合わせて返す
+func readImportRecords(req model.MoneyManagementImportRequest) ([]map[string]string, int, error) {
+	if req.Format == model.MoneyManagementImportFormatCSV {
+		records, err := spreadsheet.ReadRecords(req.Data, req.Encoding)
+		if err != nil {
+			return nil, 0, err
+		}
+		// 1行目はヘッダー
+		return records, 2, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(req.Data, []byte("\xEF\xBB\xBF"))))
+	decoder.UseNumber()
+	values := []map[string]interface{}{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, 0, errors.New("json must be an array of objects")
+	}
+	records := []map[string]string{}
+	for _, v := range values {
+		record := map[string]string{}
+		for key, value := range v {
+			if value != nil {
+				record[key] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+		records = append(records, record)
+	}
+	return records, 1, nil
+}
+
+// toImportMoneyManagement は1行分の値を家計簿にする。数量が空の場合は1、合計金額が空の場合は単価×数量とする
+func toImportMoneyManagement(record map[string]string, mapping map[string]string, categories []model.Category, loc *time.Location) (model.MoneyManagement, map[string]string) {
+	errs := map[string]string{}
+	value := func(field string) string {
+		return record[mapping[field]]
+	}
+
+	mm := model.MoneyManagement{
+		Title:    value("title"),
+		Category: resolveCategoryKey(value("category"), categories),
+		Quantity: 1,
+	}
+	if v := value("unit_price"); v != "" {
+		n, err := parseImportAmount(v)
+		if err != nil {
+			errs["unit_price"] = "unit_price must be a number"
+		}
+		mm.UnitPrice = n
+	}
+	if v := value("quantity"); v != "" {
+		n, err := parseImportAmount(v)
+		if err != nil {
+			errs["quantity"] = "quantity must be a number"
+		}
+		mm.Quantity = n
+	}
+	mm.TotalPrice = mm.UnitPrice * mm.Quantity
+	if v := value("total_price"); v != "" {
+		n, err := parseImportAmount(v)
+		if err != nil {
+			errs["total_price"] = "total_price must be a number"
+		}
+		mm.TotalPrice = n
+	}
+	purchasedAt := value("purchased_at")
+	// 購入日の列がない以前の書き出しファイルはupdated_atを購入日とする
+	if purchasedAt == "" {
+		purchasedAt = record["updated_at"]
+	}
+	if purchasedAt != "" {
+		t, err := parseImportDate(purchasedAt, loc)
+		if err != nil {
+			errs["purchased_at"] = "purchased_at must be a date (e.g. 2024/01/31)"
+		} else {
+			mm.PurchasedAt = purchaseDate(t, loc)
+		}
+	}
+	return mm, errs
+}
+
+// parseImportAmount は「￥1,200」「1200円」や全角数字の金額も数値にする
+func parseImportAmount(value string) (uint, error) {
+	value = norm.NFKC.String(value)
+	value = strings.NewReplacer("¥", "", "\\", "", ",", "", "円", "", " ", "").Replace(value)
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(n), nil
+}
+
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006/1/2 15:04:05",
+	"2006-1-2 15:04:05",
+	"2006/1/2 15:04",
+	"2006-1-2 15:04",
+	"2006/1/2",
+	"2006-1-2",
+	"2006年1月2日",
+}
+
+// parseImportDate は日付をユーザーのタイムゾーンで解釈する（時刻を含まない場合はその日の0時）
+func parseImportDate(value string, loc *time.Location) (time.Time, error) {
+	value = norm.NFKC.String(value)
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+// resolveCategoryKey はカテゴリーのキー・日本語名・英語名のいずれかからキーを返す（見つからない場合はそのまま返す）
+func resolveCategoryKey(value string, categories []model.Category) string {
+	for _, c := range categories {
+		if strings.EqualFold(value, c.Key) || value == c.NameJa || strings.EqualFold(value, c.NameEn) {
+			return c.Key
+		}
+	}
+	return value
+}
+
+func importDuplicateKey(mm model.MoneyManagement) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s", mm.PurchasedAt.Format("2006-01-02"), mm.Title, mm.TotalPrice, mm.Category)
+}
+
+// ExportMoneyManagements は期間内の家計簿を1件ずつwに書き出し、最後にカテゴリーごとの小計を書き出す
+// 検証エラーなどはwに書き出す前に返す
+func (mu *moneyManagementUsecase) ExportMoneyManagements(w io.Writer, req model.MoneyManagementExportRequest, userId uint) error {
+	if err := mu.mv.MoneyManagementExportValidator(req); err != nil {
+		return err
+	}
+
+	loc, err := userLocation(mu.ur, userId)
+	if err != nil {
+		return err
+	}
+	// 購入日は日付のみのため、UTCの日付で比較する
+	from, _ := time.Parse("2006-01-02", req.From)
+	to, _ := time.Parse("2006-01-02", req.To)
+	// Toの日を含める
+	to = to.AddDate(0, 0, 1)
+
+	categories := []model.Category{}
+	if err := mu.cgr.GetCategories(&categories); err != nil {
+		return err
+	}
+	subtotals := newCategorySubtotals(categories)
+
+	if req.Format == model.ExportFormatJSON {
+		bw := bufio.NewWriter(w)
+		if _, err := fmt.Fprintf(bw, `{"from":%q,"to":%q,"items":[`, req.From, req.To); err != nil {
+			return err
+		}
+		first := true
+		err := mu.mr.EachMyMoneyManagement(userId, from, to, func(mm model.MoneyManagement) error {
+			subtotals.add(mm)
+			item, err := json.Marshal(toMoneyManagementResponse(mm, loc))
+			if err != nil {
+				return err
+			}
+			if !first {
+				if err := bw.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			first = false
+			_, err = bw.Write(item)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		summary, err := json.Marshal(subtotals.list())
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(bw, `],"subtotals":%s,"count":%d,"total_price":%d}`, summary, subtotals.count, subtotals.totalPrice); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+
+	cw, err := spreadsheet.NewWriter(w, req.Encoding)
+	if err != nil {
+		return err
+	}
+	// 列名は一括登録の既定の列名と揃え、書き出したファイルをそのまま取り込めるようにする
+	if err := cw.Write([]string{"purchased_at", "title", "category", "category_name", "unit_price", "quantity", "total_price"}); err != nil {
+		return err
+	}
+	err = mu.mr.EachMyMoneyManagement(userId, from, to, func(mm model.MoneyManagement) error {
+		subtotals.add(mm)
+		return cw.Write([]string{
+			mm.PurchasedAt.Format("2006/01/02"),
+			mm.Title,
+			mm.Category,
+			subtotals.names[mm.Category],
+			strconv.FormatUint(uint64(mm.UnitPrice), 10),
+			strconv.FormatUint(uint64(mm.Quantity), 10),
+			strconv.FormatUint(uint64(mm.TotalPrice), 10),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// 明細の後に空行を挟んでカテゴリーごとの小計と合計を書き出す
+	records := [][]string{{}, {"category", "category_name", "count", "total_price"}}
+	for _, v := range subtotals.list() {
+		records = append(records, []string{v.Category, v.Name, strconv.FormatUint(uint64(v.Count), 10), strconv.FormatUint(uint64(v.TotalPrice), 10)})
+	}
+	records = append(records, []string{"total", "合計", strconv.FormatUint(uint64(subtotals.count), 10), strconv.FormatUint(uint64(subtotals.totalPrice), 10)})
+	return cw.WriteAll(records)
+}
+
+// categorySubtotals は書き出し中の家計簿のカテゴリーごとの件数と合計金額を集計する
+type categorySubtotals struct {
+	keys       []string
+	names      map[string]string
+	subtotals  map[string]*model.MoneyManagementCategorySubtotalResponse
+	count      uint
+	totalPrice uint
+}
+
+func newCategorySubtotals(categories []model.Category) *categorySubtotals {
+	cs := &categorySubtotals{
+		names:     map[string]string{},
+		subtotals: map[string]*model.MoneyManagementCategorySubtotalResponse{},
+	}
+	for _, c := range categories {
+		cs.keys = append(cs.keys, c.Key)
+		cs.names[c.Key] = c.NameJa
+	}
+	return cs
+}
+
+func (cs *categorySubtotals) add(mm model.MoneyManagement) {
+	v, ok := cs.subtotals[mm.Category]
+	if !ok {
+		// 削除されたカテゴリーも末尾に集計する
+		if _, known := cs.names[mm.Category]; !known {
+			cs.keys = append(cs.keys, mm.Category)
+		}
+		v = &model.MoneyManagementCategorySubtotalResponse{Category: mm.Category, Name: cs.names[mm.Category]}
+		cs.subtotals[mm.Category] = v
+	}
+	v.Count++
+	v.TotalPrice += mm.TotalPrice
+	cs.count++
+	cs.totalPrice += mm.TotalPrice
+}
+
+// list はカテゴリーの表示順に、家計簿のあるカテゴリーの小計を返す
+func (cs *categorySubtotals) list() []model.MoneyManagementCategorySubtotalResponse {
+	list := []model.MoneyManagementCategorySubtotalResponse{}
+	for _, key := range cs.keys {
+		if v, ok := cs.subtotals[key]; ok {
+			list = append(list, *v)
+		}
+	}
+	return list
+}
+
+// toMoneyManagementResponse は購入日をユーザーのタイムゾーンでのその日の0時にしたレスポンスを作成する
+func toMoneyManagementResponse(moneyManagement model.MoneyManagement, loc *time.Location) model.MoneyManagementResponse {
+	purchasedAt := moneyManagement.PurchasedAt
+	return model.MoneyManagementResponse{
+		ID:          moneyManagement.ID,
+		Title:       moneyManagement.Title,
+		Category:    moneyManagement.Category,
+		UnitPrice:   moneyManagement.UnitPrice,
+		Quantity:    moneyManagement.Quantity,
+		TotalPrice:  moneyManagement.TotalPrice,
+		ProductId:   moneyManagement.ProductId,
+		ProductUrl:  moneyManagement.ProductUrl,
+		PurchasedAt: time.Date(purchasedAt.Year(), purchasedAt.Month(), purchasedAt.Day(), 0, 0, 0, 0, loc),
+		CreatedAt:   moneyManagement.CreatedAt,
+		UpdatedAt:   moneyManagement.UpdatedAt,
+	}
+}
+
+// normalizePurchasedAt は購入日をユーザーのタイムゾーンでの日付にし、更新日時を自動で設定させる
+// 以前のクライアントはupdated_atを購入日として送るため、purchased_atがない場合はupdated_atの日付を購入日とする
+func normalizePurchasedAt(moneyManagement *model.MoneyManagement, loc *time.Location) {
+	if moneyManagement.PurchasedAt.IsZero() {
+		moneyManagement.PurchasedAt = moneyManagement.UpdatedAt
+	}
+	if !moneyManagement.PurchasedAt.IsZero() {
+		moneyManagement.PurchasedAt = purchaseDate(moneyManagement.PurchasedAt, loc)
+	}
+	moneyManagement.UpdatedAt = time.Time{}
+}
+
+// purchaseDate はtのlocでの日付を、date型で保存する購入日（UTCの0時）にする
+func purchaseDate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayRange はlocでのdateの0時から翌日の0時までを返す
+// 夏時間の切り替え日も正しく1日分になるように、24時間後ではなく翌日の0時を終わりとする
+func dayRange(date time.Time, loc *time.Location) (time.Time, time.Time) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	return from, time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// monthRange はlocでのyearMonthの月初から翌月初までを返す
+func monthRange(yearMonth time.Time, loc *time.Location) (time.Time, time.Time) {
+	from := time.Date(yearMonth.Year(), yearMonth.Month(), 1, 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 1, 0)
+}
+
+// yearRange はlocでのyearMonthの年初から翌年初までを返す
+func yearRange(yearMonth time.Time, loc *time.Location) (time.Time, time.Time) {
+	from := time.Date(yearMonth.Year(), 1, 1, 0, 0, 0, 0, loc)
+	return from, from.AddDate(1, 0, 0)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+type rangeTest struct {
+	name     string
+	zone     string
+	date     time.Time
+	wantFrom string // RFC3339
+	wantTo   string
+	wantLen  time.Duration
+}
+
+func runRangeTests(t *testing.T, tests []rangeTest, rangeFunc func(time.Time, *time.Location) (time.Time, time.Time)) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.zone)
+			from, to := rangeFunc(tt.date, loc)
+			if want := mustParseTime(t, tt.wantFrom); !from.Equal(want) {
+				t.Errorf("from = %s, want %s", from.UTC().Format(time.RFC3339), tt.wantFrom)
+			}
+			if want := mustParseTime(t, tt.wantTo); !to.Equal(want) {
+				t.Errorf("to = %s, want %s", to.UTC().Format(time.RFC3339), tt.wantTo)
+			}
+			if tt.wantLen != 0 && to.Sub(from) != tt.wantLen {
+				t.Errorf("length = %s, want %s", to.Sub(from), tt.wantLen)
+			}
+			if from.Location() != loc {
+				t.Errorf("location = %s, want %s", from.Location(), loc)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayRange(t *testing.T) {
+	runRangeTests(t, []rangeTest{
+		{"ordinary day", "America/New_York", date(2024, 1, 15), "2024-01-15T05:00:00Z", "2024-01-16T05:00:00Z", 24 * time.Hour},
+		{"DST starts (23 hours)", "America/New_York", date(2024, 3, 10), "2024-03-10T05:00:00Z", "2024-03-11T04:00:00Z", 23 * time.Hour},
+		{"day before DST starts", "America/New_York", date(2024, 3, 9), "2024-03-09T05:00:00Z", "2024-03-10T05:00:00Z", 24 * time.Hour},
+		{"DST ends (25 hours)", "America/New_York", date(2024, 11, 3), "2024-11-03T04:00:00Z", "2024-11-04T05:00:00Z", 25 * time.Hour},
+		{"day after DST ends", "America/New_York", date(2024, 11, 4), "2024-11-04T05:00:00Z", "2024-11-05T05:00:00Z", 24 * time.Hour},
+		{"month end", "America/New_York", date(2024, 4, 30), "2024-04-30T04:00:00Z", "2024-05-01T04:00:00Z", 24 * time.Hour},
+		{"leap day", "Asia/Tokyo", date(2024, 2, 29), "2024-02-28T15:00:00Z", "2024-02-29T15:00:00Z", 24 * time.Hour},
+		{"day before leap day", "Asia/Tokyo", date(2024, 2, 28), "2024-02-27T15:00:00Z", "2024-02-28T15:00:00Z", 24 * time.Hour},
+		{"end of february in a common year", "Asia/Tokyo", date(2023, 2, 28), "2023-02-27T15:00:00Z", "2023-02-28T15:00:00Z", 24 * time.Hour},
+		{"year end", "Asia/Tokyo", date(2024, 12, 31), "2024-12-30T15:00:00Z", "2024-12-31T15:00:00Z", 24 * time.Hour},
+		{"year end in New York", "America/New_York", date(2024, 12, 31), "2024-12-31T05:00:00Z", "2025-01-01T05:00:00Z", 24 * time.Hour},
+	}, dayRange)
+}
+
+func TestMonthRange(t *testing.T) {
+	runRangeTests(t, []rangeTest{
+		{"month containing DST start", "America/New_York", date(2024, 3, 1), "2024-03-01T05:00:00Z", "2024-04-01T04:00:00Z", 31*24*time.Hour - time.Hour},
+		{"month containing DST end", "America/New_York", date(2024, 11, 1), "2024-11-01T04:00:00Z", "2024-12-01T05:00:00Z", 30*24*time.Hour + time.Hour},
+		{"any day of the month", "America/New_York", date(2024, 1, 31), "2024-01-01T05:00:00Z", "2024-02-01T05:00:00Z", 31 * 24 * time.Hour},
+		{"february in a leap year", "Asia/Tokyo", date(2024, 2, 10), "2024-01-31T15:00:00Z", "2024-02-29T15:00:00Z", 29 * 24 * time.Hour},
+		{"february in a common year", "Asia/Tokyo", date(2023, 2, 10), "2023-01-31T15:00:00Z", "2023-02-28T15:00:00Z", 28 * 24 * time.Hour},
+		{"century year is not a leap year", "UTC", date(2100, 2, 1), "2100-02-01T00:00:00Z", "2100-03-01T00:00:00Z", 28 * 24 * time.Hour},
+		{"december runs into the next year", "Asia/Tokyo", date(2024, 12, 1), "2024-11-30T15:00:00Z", "2024-12-31T15:00:00Z", 31 * 24 * time.Hour},
+		{"december in New York", "America/New_York", date(2024, 12, 31), "2024-12-01T05:00:00Z", "2025-01-01T05:00:00Z", 31 * 24 * time.Hour},
+	}, monthRange)
+}
+
+func TestYearRange(t *testing.T) {
+	runRangeTests(t, []rangeTest{
+		{"leap year", "UTC", date(2024, 6, 1), "2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z", 366 * 24 * time.Hour},
+		{"common year", "UTC", date(2023, 12, 31), "2023-01-01T00:00:00Z", "2024-01-01T00:00:00Z", 365 * 24 * time.Hour},
+		{"year in New York spans both DST changes", "America/New_York", date(2024, 3, 10), "2024-01-01T05:00:00Z", "2025-01-01T05:00:00Z", 366 * 24 * time.Hour},
+		{"year in Tokyo", "Asia/Tokyo", date(2024, 1, 1), "2023-12-31T15:00:00Z", "2024-12-31T15:00:00Z", 366 * 24 * time.Hour},
+	}, yearRange)
+}
+
+func TestPurchaseDate(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		t    string
+		want string
+	}{
+		{"same day in UTC", "Asia/Tokyo", "2024-03-10T03:00:00Z", "2024-03-10T00:00:00Z"},
+		{"next day in Tokyo", "Asia/Tokyo", "2024-03-10T16:00:00Z", "2024-03-11T00:00:00Z"},
+		{"previous day in New York", "America/New_York", "2024-03-10T04:30:00Z", "2024-03-09T00:00:00Z"},
+		{"DST start day in New York", "America/New_York", "2024-03-11T03:30:00Z", "2024-03-10T00:00:00Z"},
+		{"new year in Tokyo", "Asia/Tokyo", "2024-12-31T15:00:00Z", "2025-01-01T00:00:00Z"},
+		{"leap day in New York", "America/New_York", "2024-03-01T04:59:59Z", "2024-02-29T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := purchaseDate(mustParseTime(t, tt.t), mustLoadLocation(t, tt.zone))
+			if want := mustParseTime(t, tt.want); !got.Equal(want) || got.Location() != time.UTC {
+				t.Errorf("purchaseDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
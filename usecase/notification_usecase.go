@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/notifier"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type INotificationUsecase interface {
+	GetSetting(userId uint) (model.NotificationSettingResponse, error)
+	UpdateSetting(req model.NotificationSettingRequest, userId uint) (model.NotificationSettingResponse, error)
+	GetNotifications(userId uint, page int, pageSize int) ([]model.NotificationResponse, int, error)
+	MarkAsRead(userId uint, id uint) error
+	NotifyUser(userId uint, msg notifier.Message) error
+	SendDueReminders() error
+}
+
+type notificationUsecase struct {
+	nr        repository.INotificationRepository
+	nv        validator.INotificationValidator
+	notifiers map[string]notifier.Notifier
+}
+
+func NewNotificationUsecase(nr repository.INotificationRepository, nv validator.INotificationValidator, notifiers map[string]notifier.Notifier) INotificationUsecase {
+	return &notificationUsecase{nr, nv, notifiers}
+}
+
+func (nu *notificationUsecase) GetSetting(userId uint) (model.NotificationSettingResponse, error) {
+	setting := model.NotificationSetting{}
+	if err := nu.nr.GetSetting(&setting, userId); err != nil {
+		return model.NotificationSettingResponse{}, err
+	}
+	if setting.ID == 0 {
+		setting = defaultNotificationSetting(userId)
+	}
+	return toNotificationSettingResponse(setting), nil
+}
+
+func (nu *notificationUsecase) UpdateSetting(req model.NotificationSettingRequest, userId uint) (model.NotificationSettingResponse, error) {
+	if err := nu.nv.NotificationSettingValidator(req); err != nil {
+		return model.NotificationSettingResponse{}, err
+	}
+
+	setting := model.NotificationSetting{
+		EmailEnabled: req.EmailEnabled,
+		InAppEnabled: req.InAppEnabled,
+		WebhookUrl:   req.WebhookUrl,
+		LeadMinutes:  formatLeadMinutes(req.LeadMinutes),
+		UserId:       userId,
+	}
+	if err := nu.nr.UpsertSetting(&setting); err != nil {
+		return model.NotificationSettingResponse{}, err
+	}
+	return toNotificationSettingResponse(setting), nil
+}
+
+func (nu *notificationUsecase) GetNotifications(userId uint, page int, pageSize int) ([]model.NotificationResponse, int, error) {
+	notifications := []model.Notification{}
+	totalCount, err := nu.nr.GetNotifications(&notifications, userId, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resNotifications := []model.NotificationResponse{}
+	for _, v := range notifications {
+		n := model.NotificationResponse{
+			ID:        v.ID,
+			Title:     v.Title,
+			Body:      v.Body,
+			Link:      v.Link,
+			ReadAt:    v.ReadAt,
+			CreatedAt: v.CreatedAt,
+		}
+		resNotifications = append(resNotifications, n)
+	}
+	return resNotifications, totalCount, nil
+}
+
+func (nu *notificationUsecase) MarkAsRead(userId uint, id uint) error {
+	return nu.nr.MarkAsRead(userId, id)
+}
+
+// NotifyUser はユーザーの通知設定で有効な全ての送信方法で通知を送る
+func (nu *notificationUsecase) NotifyUser(userId uint, msg notifier.Message) error {
+	recipient := model.NotificationRecipient{}
+	if err := nu.nr.GetRecipient(&recipient, userId); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, channel := range notificationChannels(recipient) {
+		n, ok := nu.notifiers[channel]
+		if !ok {
+			continue
+		}
+		if err := n.Notify(toRecipient(recipient), msg); err != nil {
+			log.Printf("failed to send %s notification to user %d: %v", channel, userId, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed to send", failed)
+	}
+	return nil
+}
+
+// SendDueReminders は期限が通知タイミングに入った商品の通知を送る
+// 送信前に送信記録を作成し、既に記録がある場合は送らないため、再起動や重複実行でも二重に送信しない
+func (nu *notificationUsecase) SendDueReminders() error {
+	now := time.Now()
+	targets := []model.ProductReminderTarget{}
+	if err := nu.nr.GetReminderTargets(&targets, now, now.Add(validator.MaxReminderLeadMinutes*time.Minute)); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, t := range targets {
+		lead, ok := dueLeadMinutes(t, now)
+		if !ok {
+			continue
+		}
+
+		to := toRecipient(t.NotificationRecipient)
+		msg := reminderMessage(t, lead)
+
+		for _, channel := range notificationChannels(t.NotificationRecipient) {
+			n, ok := nu.notifiers[channel]
+			if !ok {
+				continue
+			}
+
+			delivery := model.ReminderDelivery{
+				LeadMinutes: lead,
+				Channel:     channel,
+				TimeLimit:   t.TimeLimit,
+				SentAt:      now,
+				ProductId:   t.ProductId,
+				UserId:      t.UserId,
+			}
+			claimed, err := nu.nr.ClaimDelivery(&delivery)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+
+			if err := n.Notify(to, msg); err != nil {
+				log.Printf("failed to send %s reminder for product %d: %v", channel, t.ProductId, err)
+				failed++
+				if err := nu.nr.ReleaseDelivery(&delivery); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d reminders failed to send", failed)
+	}
+	return nil
+}
+
+func defaultNotificationSetting(userId uint) model.NotificationSetting {
+	return model.NotificationSetting{
+		InAppEnabled: true,
+		LeadMinutes:  model.DefaultReminderLeadMinutes,
+		UserId:       userId,
+	}
+}
+
+func toNotificationSettingResponse(setting model.NotificationSetting) model.NotificationSettingResponse {
+	return model.NotificationSettingResponse{
+		EmailEnabled: setting.EmailEnabled,
+		InAppEnabled: setting.InAppEnabled,
+		WebhookUrl:   setting.WebhookUrl,
+		LeadMinutes:  parseLeadMinutes(setting.LeadMinutes),
+	}
+}
+
+// formatLeadMinutes は通知タイミングを重複を除いて長い順に並べ、カンマ区切りにする
+func formatLeadMinutes(leadMinutes []int) string {
+	seen := map[int]bool{}
+	unique := []int{}
+	for _, m := range leadMinutes {
+		if !seen[m] {
+			seen[m] = true
+			unique = append(unique, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(unique)))
+
+	values := []string{}
+	for _, m := range unique {
+		values = append(values, strconv.Itoa(m))
+	}
+	return strings.Join(values, ",")
+}
+
+func parseLeadMinutes(value string) []int {
+	leadMinutes := []int{}
+	for _, v := range strings.Split(value, ",") {
+		m, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || m <= 0 {
+			continue
+		}
+		leadMinutes = append(leadMinutes, m)
+	}
+	return leadMinutes
+}
+
+// dueLeadMinutes は期限までの残り時間が入っている通知タイミングのうち、最も短いものを返す
+// 商品の登録が遅く複数のタイミングに入っている場合も、通知は一度だけにする
+func dueLeadMinutes(t model.ProductReminderTarget, now time.Time) (int, bool) {
+	value := model.DefaultReminderLeadMinutes
+	if t.LeadMinutes != nil {
+		value = *t.LeadMinutes
+	}
+
+	remaining := t.TimeLimit.Sub(now)
+	lead, ok := 0, false
+	for _, m := range parseLeadMinutes(value) {
+		if remaining <= time.Duration(m)*time.Minute && (!ok || m < lead) {
+			lead, ok = m, true
+		}
+	}
+	return lead, ok
+}
+
+func notificationChannels(r model.NotificationRecipient) []string {
+	// 通知設定がない場合はアプリ内通知のみ
+	if r.InAppEnabled == nil {
+		return []string{model.NotificationChannelInApp}
+	}
+
+	channels := []string{}
+	if *r.InAppEnabled {
+		channels = append(channels, model.NotificationChannelInApp)
+	}
+	if r.EmailEnabled != nil && *r.EmailEnabled && r.Email != "" {
+		channels = append(channels, model.NotificationChannelEmail)
+	}
+	if r.WebhookUrl != nil && *r.WebhookUrl != "" {
+		channels = append(channels, model.NotificationChannelWebhook)
+	}
+	return channels
+}
+
+func toRecipient(r model.NotificationRecipient) notifier.Recipient {
+	to := notifier.Recipient{UserId: r.UserId, Email: r.Email}
+	if r.WebhookUrl != nil {
+		to.WebhookUrl = *r.WebhookUrl
+	}
+	return to
+}
+
+func reminderMessage(t model.ProductReminderTarget, lead int) notifier.Message {
+	return notifier.Message{
+		Title: fmt.Sprintf("「%s」の期限が近づいています", t.ProductName),
+		Body:  fmt.Sprintf("期限の%sです。期限: %s", formatLeadTime(lead), t.TimeLimit.In(model.LoadLocation(t.TimeZone)).Format("2006/01/02 15:04")),
+		Link:  os.Getenv("FE_URL"),
+	}
+}
+
+func formatLeadTime(minutes int) string {
+	switch {
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%d日前", minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d時間前", minutes/60)
+	default:
+		return fmt.Sprintf("%d分前", minutes)
+	}
+}
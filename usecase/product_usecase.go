@@ -1,64 +1,526 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"merchandise-review-list-backend/marketplace"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/notifier"
 	"merchandise-review-list-backend/repository"
 	"merchandise-review-list-backend/validator"
 	"time"
 )
 
+// ErrProductNotLinked はショッピングサイトの商品コードを持たない商品を更新しようとした場合のエラー
+var ErrProductNotLinked = errors.New("product is not linked to a marketplace item")
+
 type IProductUsecase interface {
 	CreateProduct(product model.Product) (model.ProductResponse, error)
-	UpdateTimeLimit(product model.Product, userId uint, productId uint) (model.ProductResponse, error)
+	UpdateProduct(product model.Product, userId uint, productId uint) (model.ProductResponse, error)
+	UpdateTimeLimit(req model.ProductTimeLimitRequest, userId uint, productId uint) (model.ProductResponse, error)
+	GetProductHistories(userId uint, productId uint) ([]model.ProductHistoryResponse, error)
+	RecordPriceSnapshot(req model.ProductPriceSnapshotRequest, userId uint, productId uint) (model.ProductPriceSnapshotResponse, error)
+	GetPriceTimeline(userId uint, productId uint, from string, to string) (model.ProductPriceTimelineResponse, error)
+	UpdateTargetPrice(req model.ProductTargetPriceRequest, userId uint, productId uint) (model.ProductResponse, error)
+	RefreshProduct(userId uint, productId uint) (model.ProductResponse, error)
+	LookupProduct(provider string, code string, url string) (model.ProductLookupResponse, error)
 	DeleteProduct(userId uint, productId uint) error
-	GetMyProducts(userId uint, page int, pageSize int) ([]model.ProductResponse, int, error)
+	DeleteProducts(userId uint, productIds []uint) (int, error)
+	GetMyProducts(userId uint, page int, pageSize int, deadline string, days int, archived bool) ([]model.ProductResponse, int, error)
+	PurchaseProduct(req model.ProductPurchaseRequest, userId uint, productId uint) (model.ProductPurchaseResponse, error)
 	GetMyProductsTimeLimitAll(userId uint, page int, pageSize int, sort bool) ([]model.ProductResponse, int, error)
 	GetMyProductsTimeLimitYearMonth(userId uint, yearMonth time.Time) ([]model.ProductYearMonthResponse, error)
 	GetMyProductsTimeLimitDate(userId uint, page int, pageSize int, date time.Time) ([]model.ProductResponse, int, error)
+	GetWishlistItems(userId uint, wishlistId uint) ([]model.WishlistItemResponse, error)
+	AddWishlistItem(userId uint, wishlistId uint, productId uint) error
+	RemoveWishlistItem(userId uint, wishlistId uint, productId uint) error
+	MarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error
+	UnmarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error
 }
 
 type productUsecase struct {
-	pr repository.IProductRepository
-	pv validator.IProductValidator
+	pr  repository.IProductRepository
+	pv  validator.IProductValidator
+	rr  repository.IReviewPostRepository
+	ur  repository.IUserRepository
+	psr repository.IProductPriceSnapshotRepository
+	nu  INotificationUsecase
+	mpr *marketplace.Registry
+	mv  validator.IMoneyManagementValidator
+	cgr repository.ICategoryRepository
 }
 
-func NweProductUsecase(pr repository.IProductRepository, pv validator.IProductValidator) IProductUsecase {
-	return &productUsecase{pr, pv}
+func NweProductUsecase(
+	pr repository.IProductRepository,
+	pv validator.IProductValidator,
+	rr repository.IReviewPostRepository,
+	ur repository.IUserRepository,
+	psr repository.IProductPriceSnapshotRepository,
+	nu INotificationUsecase,
+	mpr *marketplace.Registry,
+	mv validator.IMoneyManagementValidator,
+	cgr repository.ICategoryRepository,
+) IProductUsecase {
+	return &productUsecase{pr, pv, rr, ur, psr, nu, mpr, mv, cgr}
 }
 
 func (pu *productUsecase) CreateProduct(product model.Product) (model.ProductResponse, error) {
+	// 以前のクライアントは期限なしをゼロ値で送るため、nilとして扱う
+	if product.TimeLimit != nil && product.TimeLimit.IsZero() {
+		product.TimeLimit = nil
+	}
+	if err := pu.pv.ProductValidator(product, true); err != nil {
+		return model.ProductResponse{}, err
+	}
+	if err := pu.pv.TimeLimitValidator(product); err != nil {
+		return model.ProductResponse{}, err
+	}
+	if err := pu.checkDuplicate(product, product.UserId, 0); err != nil {
+		return model.ProductResponse{}, err
+	}
+
 	if err := pu.pr.CreateProduct(&product); err != nil {
 		return model.ProductResponse{}, err
 	}
 
-	resProduct := model.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Stock:       product.Stock,
-		Price:       product.Price,
-		Review:      product.Review,
-		Url:         product.Url,
-		Image:       product.Image,
-		Provider:    product.Provider,
-		TimeLimit:   product.TimeLimit,
-		CreatedAt:   product.CreatedAt,
+	loc, err := userLocation(pu.ur, product.UserId)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+	return pu.toProductResponse(product, loc)
+}
+
+// UpdateProduct は期限以外の項目を更新する
+// コードを持たない以前の商品は、providerとcodeを指定しなくても更新できる
+func (pu *productUsecase) UpdateProduct(product model.Product, userId uint, productId uint) (model.ProductResponse, error) {
+	current := model.Product{}
+	if err := pu.pr.GetProductById(&current, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+	if err := pu.pv.ProductValidator(product, current.Code != ""); err != nil {
+		return model.ProductResponse{}, err
+	}
+	if err := pu.checkDuplicate(product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err := pu.pr.UpdateProduct(&product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductResponse{}, err
 	}
-	return resProduct, nil
+	return pu.toProductResponse(product, loc)
 }
 
-func (pu *productUsecase) UpdateTimeLimit(product model.Product, userId uint, productId uint) (model.ProductResponse, error) {
-	if err := pu.pv.ProductValidator(product); err != nil {
+// UpdateTimeLimit は指定された方法で期限を変更する
+// snoozeは現在の期限（過ぎている場合や期限がない場合は現在日時）から延長する
+func (pu *productUsecase) UpdateTimeLimit(req model.ProductTimeLimitRequest, userId uint, productId uint) (model.ProductResponse, error) {
+	if req.Mode == "" && !req.TimeLimit.IsZero() {
+		req.Mode = model.ProductTimeLimitModeSet
+	}
+	if err := pu.pv.TimeLimitRequestValidator(req); err != nil {
 		return model.ProductResponse{}, err
 	}
-	if err := pu.pr.UpdateTimeLimit(&product, userId, productId); err != nil {
+
+	product := model.Product{}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
 		return model.ProductResponse{}, err
 	}
+	before := product.TimeLimit
+
+	history := model.ProductHistory{
+		Action:    req.Mode,
+		ProductId: productId,
+		UserId:    userId,
+	}
+	switch req.Mode {
+	case model.ProductTimeLimitModeSet:
+		timeLimit := req.TimeLimit
+		product.TimeLimit = &timeLimit
+	case model.ProductTimeLimitModeSnooze:
+		base := time.Now()
+		if product.TimeLimit != nil && product.TimeLimit.After(base) {
+			base = *product.TimeLimit
+		}
+		duration := snoozeDuration(req)
+		timeLimit := base.Add(duration)
+		product.TimeLimit = &timeLimit
+		history.Detail = duration.String()
+	case model.ProductTimeLimitModeClear:
+		product.TimeLimit = nil
+	}
+	history.BeforeTimeLimit = before
+	history.AfterTimeLimit = product.TimeLimit
+
+	if err := pu.pr.UpdateTimeLimit(&product, userId, productId, &history); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+	return pu.toProductResponse(product, loc)
+}
+
+func (pu *productUsecase) GetProductHistories(userId uint, productId uint) ([]model.ProductHistoryResponse, error) {
+	histories := []model.ProductHistory{}
+	if err := pu.pr.GetHistories(&histories, userId, productId); err != nil {
+		return nil, err
+	}
+
+	resHistories := []model.ProductHistoryResponse{}
+	for _, v := range histories {
+		h := model.ProductHistoryResponse{
+			ID:              v.ID,
+			Action:          v.Action,
+			Detail:          v.Detail,
+			BeforeTimeLimit: v.BeforeTimeLimit,
+			AfterTimeLimit:  v.AfterTimeLimit,
+			CreatedAt:       v.CreatedAt,
+		}
+		resHistories = append(resHistories, h)
+	}
+	return resHistories, nil
+}
+
+func snoozeDuration(req model.ProductTimeLimitRequest) time.Duration {
+	switch req.Snooze {
+	case model.ProductSnoozeHour:
+		return time.Hour
+	case model.ProductSnoozeWeek:
+		return 7 * 24 * time.Hour
+	case model.ProductSnoozeCustom:
+		return time.Duration(req.Minutes) * time.Minute
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// RecordPriceSnapshot は価格・在庫・評価の記録を追加する
+// 目標価格より高かった価格が目標価格以下になった場合は通知する
+func (pu *productUsecase) RecordPriceSnapshot(req model.ProductPriceSnapshotRequest, userId uint, productId uint) (model.ProductPriceSnapshotResponse, error) {
+	if req.Source == "" {
+		req.Source = model.ProductPriceSourceManual
+	}
+	if err := pu.pv.PriceSnapshotValidator(req); err != nil {
+		return model.ProductPriceSnapshotResponse{}, err
+	}
+
+	product := model.Product{}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductPriceSnapshotResponse{}, err
+	}
+	previousPrice := product.Price
+
+	snapshot := model.ProductPriceSnapshot{
+		Price:      req.Price,
+		Stock:      req.Stock,
+		Review:     req.Review,
+		Source:     req.Source,
+		ObservedAt: time.Now(),
+		ProductId:  productId,
+		UserId:     userId,
+	}
+	if req.ObservedAt != nil {
+		snapshot.ObservedAt = *req.ObservedAt
+	}
+	applied, err := pu.psr.RecordSnapshot(&snapshot)
+	if err != nil {
+		return model.ProductPriceSnapshotResponse{}, err
+	}
+
+	// 商品に反映された（最新の記録だった）場合のみ判定する
+	if applied && isPriceDrop(product.TargetPrice, previousPrice, snapshot.Price) {
+		product.Price = snapshot.Price
+		if err := pu.nu.NotifyUser(userId, priceDropMessage(product, previousPrice)); err != nil {
+			log.Printf("failed to notify price drop for product %d: %v", productId, err)
+		}
+	}
+
+	return toProductPriceSnapshotResponse(snapshot), nil
+}
+
+// GetPriceTimeline は価格・在庫の推移を返す
+// from, toは"2006-01-02"形式でユーザーのタイムゾーンの日付として扱い、toの日も含める
+func (pu *productUsecase) GetPriceTimeline(userId uint, productId uint, from string, to string) (model.ProductPriceTimelineResponse, error) {
+	product := model.Product{}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductPriceTimelineResponse{}, err
+	}
+
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductPriceTimelineResponse{}, err
+	}
+	var fromTime, toTime *time.Time
+	if from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return model.ProductPriceTimelineResponse{}, errors.New("invalid from format")
+		}
+		fromTime = &t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return model.ProductPriceTimelineResponse{}, errors.New("invalid to format")
+		}
+		t = t.AddDate(0, 0, 1)
+		toTime = &t
+	}
+
+	snapshots := []model.ProductPriceSnapshot{}
+	if err := pu.psr.GetSnapshots(&snapshots, userId, productId, fromTime, toTime); err != nil {
+		return model.ProductPriceTimelineResponse{}, err
+	}
+
+	res := model.ProductPriceTimelineResponse{
+		Snapshots:   []model.ProductPriceSnapshotResponse{},
+		TargetPrice: product.TargetPrice,
+	}
+	for i, v := range snapshots {
+		if i == 0 || v.Price < res.LowestPrice {
+			res.LowestPrice = v.Price
+		}
+		if v.Price > res.HighestPrice {
+			res.HighestPrice = v.Price
+		}
+		s := toProductPriceSnapshotResponse(v)
+		s.ObservedAt = s.ObservedAt.In(loc)
+		res.Snapshots = append(res.Snapshots, s)
+	}
+	return res, nil
+}
+
+func (pu *productUsecase) UpdateTargetPrice(req model.ProductTargetPriceRequest, userId uint, productId uint) (model.ProductResponse, error) {
+	if err := pu.pv.TargetPriceValidator(req); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	product := model.Product{TargetPrice: req.TargetPrice}
+	if err := pu.psr.UpdateTargetPrice(&product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+	return pu.toProductResponse(product, loc)
+}
+
+// RefreshProduct はショッピングサイトから現在の価格・在庫・評価を取得して記録する
+func (pu *productUsecase) RefreshProduct(userId uint, productId uint) (model.ProductResponse, error) {
+	product := model.Product{}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if product.Code == "" {
+		return model.ProductResponse{}, ErrProductNotLinked
+	}
+	p, ok := pu.mpr.Get(product.Provider)
+	if !ok {
+		return model.ProductResponse{}, errors.New("unsupported provider")
+	}
+	offer, err := p.Refresh(product.Code)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	req := model.ProductPriceSnapshotRequest{
+		Price:  offer.Price,
+		Stock:  offer.Stock,
+		Review: offer.Review,
+		Source: model.ProductPriceSourceImport,
+	}
+	if _, err := pu.RecordPriceSnapshot(req, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductResponse{}, err
+	}
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+	return pu.toProductResponse(product, loc)
+}
+
+// LookupProduct はショッピングサイトの商品情報を商品コード、またはURLから取得する
+// providerを省略した場合は、URLから判別できるショッピングサイトを探す
+func (pu *productUsecase) LookupProduct(provider string, code string, url string) (model.ProductLookupResponse, error) {
+	var item *marketplace.Item
+	var err error
+	switch {
+	case provider == "" && url != "":
+		item, err = pu.mpr.FindByURL(url)
+	case provider != "":
+		p, ok := pu.mpr.Get(provider)
+		if !ok {
+			return model.ProductLookupResponse{}, errors.New("unsupported provider")
+		}
+		if code != "" {
+			item, err = p.LookupByCode(code)
+		} else {
+			item, err = p.LookupByURL(url)
+		}
+	default:
+		return model.ProductLookupResponse{}, errors.New("code or url is required")
+	}
+	if err != nil {
+		return model.ProductLookupResponse{}, err
+	}
+
+	return model.ProductLookupResponse{
+		Provider:    item.Provider,
+		Code:        item.Code,
+		Name:        item.Name,
+		Description: item.Description,
+		Url:         item.Url,
+		Image:       item.Image,
+		Price:       item.Price,
+		Stock:       item.Stock,
+		Review:      item.Review,
+	}, nil
+}
+
+// PurchaseProduct は商品から家計簿を登録し、家計簿と商品を紐付ける
+// 家計簿の登録と商品の更新（アーカイブ・削除）は同じトランザクションで行う
+func (pu *productUsecase) PurchaseProduct(req model.ProductPurchaseRequest, userId uint, productId uint) (model.ProductPurchaseResponse, error) {
+	if req.After == "" {
+		req.After = model.ProductPurchaseKeep
+	}
+	if err := pu.pv.PurchaseValidator(req); err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+
+	product := model.Product{}
+	if err := pu.pr.GetProductById(&product, userId, productId); err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+
+	purchasedAt := time.Now()
+	if req.PurchasedAt != nil {
+		purchasedAt = *req.PurchasedAt
+	}
+	product.PurchasedAt = &purchasedAt
+	moneyManagement, err := pu.newPurchaseMoneyManagement(req, product, purchaseDate(purchasedAt, loc))
+	if err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+	categoryKeys, err := pu.cgr.GetCategoryKeys()
+	if err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+	if err := pu.mv.MoneyManagementValidator(moneyManagement, categoryKeys); err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+
+	if err := pu.pr.PurchaseProduct(&product, &moneyManagement, req.After); err != nil {
+		return model.ProductPurchaseResponse{}, err
+	}
+
+	res := model.ProductPurchaseResponse{
+		MoneyManagement: toMoneyManagementResponse(moneyManagement, loc),
+	}
+	if req.After != model.ProductPurchaseRemove {
+		p, err := pu.toProductResponse(product, loc)
+		if err != nil {
+			return model.ProductPurchaseResponse{}, err
+		}
+		res.Product = &p
+	}
+	return res, nil
+}
+
+// newPurchaseMoneyManagement はリクエストで省略された項目を商品の値で補って家計簿を作成する
+func (pu *productUsecase) newPurchaseMoneyManagement(req model.ProductPurchaseRequest, product model.Product, purchasedAt time.Time) (model.MoneyManagement, error) {
+	title := req.Title
+	if title == "" {
+		// 家計簿のタイトルの上限に合わせて切り詰める
+		title = truncateRunes(product.Name, 50)
+	}
+	category := req.Category
+	if category == "" {
+		c, err := pu.pr.GetReviewCategory(product.Provider, product.Code)
+		if err != nil {
+			return model.MoneyManagement{}, err
+		}
+		category = c
+	}
+	if category == "" {
+		category = "other"
+	}
+	unitPrice := product.Price
+	if req.UnitPrice != nil {
+		unitPrice = *req.UnitPrice
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	productId := product.ID
+	return model.MoneyManagement{
+		Title:       title,
+		Category:    category,
+		UnitPrice:   unitPrice,
+		Quantity:    quantity,
+		TotalPrice:  unitPrice * quantity,
+		ProductId:   &productId,
+		ProductUrl:  product.Url,
+		PurchasedAt: purchasedAt,
+		UserId:      product.UserId,
+	}, nil
+}
 
-	resProduct := model.ProductResponse{
-		TimeLimit: product.TimeLimit,
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// isPriceDrop は目標価格より高かった価格が目標価格以下になったかを返す
+// 目標価格以下のまま変動した場合は通知済みとみなし、再度通知しない
+func isPriceDrop(targetPrice *uint, previousPrice uint, price uint) bool {
+	if targetPrice == nil {
+		return false
+	}
+	return previousPrice > *targetPrice && price <= *targetPrice
+}
+
+func priceDropMessage(product model.Product, previousPrice uint) notifier.Message {
+	return notifier.Message{
+		Title: fmt.Sprintf("「%s」が目標価格以下になりました", product.Name),
+		Body:  fmt.Sprintf("価格: %d円（以前: %d円、目標: %d円）", product.Price, previousPrice, *product.TargetPrice),
+		Link:  product.Url,
+	}
+}
+
+func toProductPriceSnapshotResponse(snapshot model.ProductPriceSnapshot) model.ProductPriceSnapshotResponse {
+	return model.ProductPriceSnapshotResponse{
+		ID:         snapshot.ID,
+		Price:      snapshot.Price,
+		Stock:      snapshot.Stock,
+		Review:     snapshot.Review,
+		Source:     snapshot.Source,
+		ObservedAt: snapshot.ObservedAt,
 	}
-	return resProduct, nil
 }
 
 func (pu *productUsecase) DeleteProduct(userId uint, productId uint) error {
@@ -68,39 +530,66 @@ func (pu *productUsecase) DeleteProduct(userId uint, productId uint) error {
 	return nil
 }
 
-func (pu *productUsecase) GetMyProducts(userId uint, page int, pageSize int) ([]model.ProductResponse, int, error) {
-	product := []model.Product{}
+func (pu *productUsecase) DeleteProducts(userId uint, productIds []uint) (int, error) {
+	if len(productIds) == 0 {
+		return 0, errors.New("product_ids is required")
+	}
+	return pu.pr.DeleteProducts(userId, productIds)
+}
 
-	totalCount, err := pu.pr.GetMyProducts(&product, userId, page, pageSize)
+// checkDuplicate は同じ商品が既に保存されている場合にエラーを返す
+// コードのない商品は一意制約の対象外のため確認しない
+func (pu *productUsecase) checkDuplicate(product model.Product, userId uint, productId uint) error {
+	if product.Code == "" {
+		return nil
+	}
+	exists, err := pu.pr.ExistsProduct(userId, product.Provider, product.Code, productId)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	if exists {
+		return errors.New("product already exists")
 	}
+	return nil
+}
 
-	resProducts := []model.ProductResponse{}
-	for _, product := range product {
+// GetMyProducts は保存した商品を返す。deadlineを指定した場合は期限で絞り込む
+// dueWithinの「days日以内」はユーザーのタイムゾーンでの日数で計算する
+// archivedがtrueの場合はアーカイブした商品のみ返す
+func (pu *productUsecase) GetMyProducts(userId uint, page int, pageSize int, deadline string, days int, archived bool) ([]model.ProductResponse, int, error) {
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		// product.TimeLimitをJSTに変換
-		jstTimeLimit := product.TimeLimit.In(time.FixedZone("Asia/Tokyo", 0))
-
-		p := model.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Stock:       product.Stock,
-			Price:       product.Price,
-			Review:      product.Review,
-			Url:         product.Url,
-			Image:       product.Image,
-			Provider:    product.Provider,
-			TimeLimit:   jstTimeLimit,
-			CreatedAt:   product.CreatedAt,
-		}
-		resProducts = append(resProducts, p)
+	filter := newDeadlineFilter(deadline, days, time.Now().In(loc))
+	if err := pu.pv.DeadlineFilterValidator(filter); err != nil {
+		return nil, 0, err
 	}
 
+	product := []model.Product{}
+	totalCount, err := pu.pr.GetMyProducts(&product, userId, page, pageSize, filter, archived)
+	if err != nil {
+		return nil, 0, err
+	}
+	resProducts, err := pu.toProductResponses(product, loc)
+	if err != nil {
+		return nil, 0, err
+	}
 	return resProducts, totalCount, nil
 }
 
+// newDeadlineFilter は期限による絞り込みの条件を作成する
+// nowはユーザーのタイムゾーンでの現在日時で、夏時間をまたぐ場合もdays日後の同じ時刻までとする
+func newDeadlineFilter(deadline string, days int, now time.Time) model.ProductDeadlineFilter {
+	return model.ProductDeadlineFilter{
+		Deadline: deadline,
+		Days:     days,
+		Now:      now,
+		Until:    now.AddDate(0, 0, days),
+	}
+}
+
 func (pu *productUsecase) GetMyProductsTimeLimitAll(userId uint, page int, pageSize int, sort bool) ([]model.ProductResponse, int, error) {
 	product := []model.Product{}
 
@@ -109,47 +598,46 @@ func (pu *productUsecase) GetMyProductsTimeLimitAll(userId uint, page int, pageS
 		return nil, 0, err
 	}
 
-	resProducts := []model.ProductResponse{}
-	for _, product := range product {
-
-		// product.TimeLimitをJSTに変換
-		jstTimeLimit := product.TimeLimit.In(time.FixedZone("Asia/Tokyo", 0))
-
-		p := model.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Stock:       product.Stock,
-			Price:       product.Price,
-			Review:      product.Review,
-			Url:         product.Url,
-			Image:       product.Image,
-			Provider:    product.Provider,
-			TimeLimit:   jstTimeLimit,
-			CreatedAt:   product.CreatedAt,
-		}
-		resProducts = append(resProducts, p)
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	resProducts, err := pu.toProductResponses(product, loc)
+	if err != nil {
+		return nil, 0, err
 	}
-
 	return resProducts, totalCount, nil
 }
 
+// GetMyProductsTimeLimitYearMonth は指定した年月に期限がある日の一覧を返す（同じ日の商品は1件にまとめる）
+// 月・日の境界はユーザーのタイムゾーンで計算する
 func (pu *productUsecase) GetMyProductsTimeLimitYearMonth(userId uint, yearMonth time.Time) ([]model.ProductYearMonthResponse, error) {
-	product := []model.Product{}
-
-	err := pu.pr.GetMyProductsTimeLimitYearMonth(&product, userId, yearMonth)
+	loc, err := userLocation(pu.ur, userId)
 	if err != nil {
 		return nil, err
 	}
+	from, to := monthRange(yearMonth, loc)
+
+	product := []model.Product{}
+	if err := pu.pr.GetMyProductsTimeLimitYearMonth(&product, userId, from, to); err != nil {
+		return nil, err
+	}
 
 	resProducts := []model.ProductYearMonthResponse{}
+	seen := map[string]bool{}
 	for _, product := range product {
-
-		// product.TimeLimitをJSTに変換
-		jstTimeLimit := product.TimeLimit.In(time.FixedZone("Asia/Tokyo", 0))
+		if product.TimeLimit == nil {
+			continue
+		}
+		localTimeLimit := product.TimeLimit.In(loc)
+		day := localTimeLimit.Format("2006-01-02")
+		if seen[day] {
+			continue
+		}
+		seen[day] = true
 
 		p := model.ProductYearMonthResponse{
-			TimeLimit: jstTimeLimit,
+			TimeLimit: localTimeLimit,
 		}
 		resProducts = append(resProducts, p)
 	}
@@ -158,34 +646,162 @@ func (pu *productUsecase) GetMyProductsTimeLimitYearMonth(userId uint, yearMonth
 }
 
 func (pu *productUsecase) GetMyProductsTimeLimitDate(userId uint, page int, pageSize int, date time.Time) ([]model.ProductResponse, int, error) {
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	from, to := dayRange(date, loc)
+
 	product := []model.Product{}
+	totalCount, err := pu.pr.GetMyProductsTimeLimitDate(&product, userId, page, pageSize, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	totalCount, err := pu.pr.GetMyProductsTimeLimitDate(&product, userId, page, pageSize, date)
+	resProducts, err := pu.toProductResponses(product, loc)
 	if err != nil {
 		return nil, 0, err
 	}
+	return resProducts, totalCount, nil
+}
+
+// GetWishlistItems は共有リストの商品を取得する。権限の確認はリポジトリのクエリで行う
+func (pu *productUsecase) GetWishlistItems(userId uint, wishlistId uint) ([]model.WishlistItemResponse, error) {
+	items := []model.WishlistItem{}
+	if err := pu.pr.GetWishlistItems(&items, userId, wishlistId); err != nil {
+		return nil, err
+	}
+
+	loc, err := userLocation(pu.ur, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	products := []model.Product{}
+	for _, v := range items {
+		products = append(products, v.Product)
+	}
+	resProducts, err := pu.toProductResponses(products, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	resItems := []model.WishlistItemResponse{}
+	for i, v := range items {
+		p := resProducts[i]
+		// メモは商品を保存したユーザーのみに見せる
+		if v.Product.UserId != userId {
+			p.Notes = ""
+		}
+
+		item := model.WishlistItemResponse{
+			ID:       v.ID,
+			Product:  p,
+			AddedBy:  v.AddedBy,
+			BoughtBy: v.BoughtBy,
+			BoughtAt: v.BoughtAt,
+		}
+		if v.Buyer != nil {
+			item.BoughtName = v.Buyer.Name
+		}
+		resItems = append(resItems, item)
+	}
+	return resItems, nil
+}
+
+func (pu *productUsecase) AddWishlistItem(userId uint, wishlistId uint, productId uint) error {
+	item := model.WishlistItem{
+		WishlistId: wishlistId,
+		ProductId:  productId,
+		AddedBy:    userId,
+	}
+	return pu.pr.AddWishlistItem(&item)
+}
+
+func (pu *productUsecase) RemoveWishlistItem(userId uint, wishlistId uint, productId uint) error {
+	return pu.pr.RemoveWishlistItem(userId, wishlistId, productId)
+}
+
+func (pu *productUsecase) MarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error {
+	return pu.pr.MarkWishlistItemBought(userId, wishlistId, productId)
+}
+
+func (pu *productUsecase) UnmarkWishlistItemBought(userId uint, wishlistId uint, productId uint) error {
+	return pu.pr.UnmarkWishlistItemBought(userId, wishlistId, productId)
+}
+
+func (pu *productUsecase) toProductResponses(products []model.Product, loc *time.Location) ([]model.ProductResponse, error) {
+	aggregates, err := pu.getReviewAggregates(products)
+	if err != nil {
+		return nil, err
+	}
 
 	resProducts := []model.ProductResponse{}
-	for _, product := range product {
+	for _, product := range products {
+		resProducts = append(resProducts, newProductResponse(product, loc, aggregates))
+	}
+	return resProducts, nil
+}
+
+func (pu *productUsecase) toProductResponse(product model.Product, loc *time.Location) (model.ProductResponse, error) {
+	aggregates, err := pu.getReviewAggregates([]model.Product{product})
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+	return newProductResponse(product, loc, aggregates), nil
+}
 
-		// product.TimeLimitをJSTに変換
-		jstTimeLimit := product.TimeLimit.In(time.FixedZone("Asia/Tokyo", 0))
-
-		p := model.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Stock:       product.Stock,
-			Price:       product.Price,
-			Review:      product.Review,
-			Url:         product.Url,
-			Image:       product.Image,
-			Provider:    product.Provider,
-			TimeLimit:   jstTimeLimit,
-			CreatedAt:   product.CreatedAt,
+// getReviewAggregates は商品（provider, code）ごとのレビュー集計をまとめて取得する。
+// コードのない商品はレビューと紐付かないため集計しない
+func (pu *productUsecase) getReviewAggregates(products []model.Product) (map[model.ProductKey]model.ProductReviewAggregateResponse, error) {
+	keys := []model.ProductKey{}
+	for _, product := range products {
+		if product.Code != "" {
+			keys = append(keys, model.ProductKey{Provider: product.Provider, Code: product.Code})
 		}
-		resProducts = append(resProducts, p)
 	}
+	return pu.rr.GetProductReviewAggregates(keys)
+}
 
-	return resProducts, totalCount, nil
+// newProductResponse は同じ商品（provider, code）に投稿されたレビューの集計を付与したレスポンスを作成する
+// 期限はユーザーのタイムゾーンに変換する
+func newProductResponse(product model.Product, loc *time.Location, aggregates map[model.ProductKey]model.ProductReviewAggregateResponse) model.ProductResponse {
+	aggregate, ok := aggregates[model.ProductKey{Provider: product.Provider, Code: product.Code}]
+	if !ok || product.Code == "" {
+		aggregate = model.ProductReviewAggregateResponse{
+			Histogram: []model.ProductReviewHistogramResponse{},
+			Criteria:  []model.CriterionAggregateResponse{},
+		}
+	}
+
+	var timeLimit *time.Time
+	overdue := false
+	if product.TimeLimit != nil {
+		t := product.TimeLimit.In(loc)
+		timeLimit = &t
+		overdue = t.Before(time.Now())
+	}
+
+	p := model.ProductResponse{
+		ID:              product.ID,
+		Name:            product.Name,
+		Description:     product.Description,
+		Stock:           product.Stock,
+		Price:           product.Price,
+		Review:          product.Review,
+		Url:             product.Url,
+		Image:           product.Image,
+		Code:            product.Code,
+		Provider:        product.Provider,
+		TimeLimit:       timeLimit,
+		Overdue:         overdue,
+		TargetPrice:     product.TargetPrice,
+		PurchasedAt:     product.PurchasedAt,
+		ArchivedAt:      product.ArchivedAt,
+		Notes:           product.Notes,
+		Priority:        product.Priority,
+		CreatedAt:       product.CreatedAt,
+		ReviewAggregate: aggregate,
+	}
+	return p
 }
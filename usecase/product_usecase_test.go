@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"errors"
+	"merchandise-review-list-backend/marketplace"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/notifier"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"testing"
+	"time"
+)
+
+// fakeProductRepository はメモリ上の商品を返し、受け取った検索範囲を記録する
+type fakeProductRepository struct {
+	repository.IProductRepository
+	products map[uint]model.Product
+	from     time.Time
+	to       time.Time
+	filter   model.ProductDeadlineFilter
+}
+
+func (pr *fakeProductRepository) GetProductById(product *model.Product, userId uint, productId uint) error {
+	p, ok := pr.products[productId]
+	if !ok || p.UserId != userId {
+		return errors.New("record not found")
+	}
+	*product = p
+	return nil
+}
+
+func (pr *fakeProductRepository) GetMyProducts(product *[]model.Product, userId uint, page int, pageSize int, filter model.ProductDeadlineFilter, archived bool) (int, error) {
+	pr.filter = filter
+	return 0, nil
+}
+
+func (pr *fakeProductRepository) GetMyProductsTimeLimitYearMonth(product *[]model.Product, userId uint, from time.Time, to time.Time) error {
+	pr.from, pr.to = from, to
+	return nil
+}
+
+func (pr *fakeProductRepository) GetMyProductsTimeLimitDate(product *[]model.Product, userId uint, page int, pageSize int, from time.Time, to time.Time) (int, error) {
+	pr.from, pr.to = from, to
+	return 0, nil
+}
+
+// fakeProductPriceSnapshotRepository は記録を保持し、商品の価格・在庫・評価を更新する
+type fakeProductPriceSnapshotRepository struct {
+	repository.IProductPriceSnapshotRepository
+	pr        *fakeProductRepository
+	snapshots []model.ProductPriceSnapshot
+}
+
+func (psr *fakeProductPriceSnapshotRepository) RecordSnapshot(snapshot *model.ProductPriceSnapshot) (bool, error) {
+	psr.snapshots = append(psr.snapshots, *snapshot)
+	p := psr.pr.products[snapshot.ProductId]
+	p.Price, p.Stock, p.Review = snapshot.Price, snapshot.Stock, snapshot.Review
+	psr.pr.products[snapshot.ProductId] = p
+	return true, nil
+}
+
+type fakeNotificationUsecase struct {
+	INotificationUsecase
+	messages []notifier.Message
+}
+
+func (nu *fakeNotificationUsecase) NotifyUser(userId uint, msg notifier.Message) error {
+	nu.messages = append(nu.messages, msg)
+	return nil
+}
+
+func newTestProductUsecase(pr repository.IProductRepository, timeZone string) *productUsecase {
+	ur := &fakeUserRepository{users: map[uint]model.User{1: {ID: 1, TimeZone: timeZone}}}
+	return &productUsecase{pr: pr, pv: validator.NewProductValidator(), rr: newFakeReviewPostRepository(), ur: ur}
+}
+
+func TestNewDeadlineFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		now       string // RFC3339
+		days      int
+		wantUntil string
+		wantLen   time.Duration
+	}{
+		{"across DST start", "America/New_York", "2024-03-09T12:00:00-05:00", 1, "2024-03-10T12:00:00-04:00", 23 * time.Hour},
+		{"across DST end", "America/New_York", "2024-11-02T12:00:00-04:00", 1, "2024-11-03T12:00:00-05:00", 25 * time.Hour},
+		{"a week across DST start", "America/New_York", "2024-03-05T09:00:00-05:00", 7, "2024-03-12T09:00:00-04:00", 7*24*time.Hour - time.Hour},
+		{"into leap day", "Asia/Tokyo", "2024-02-28T09:00:00+09:00", 1, "2024-02-29T09:00:00+09:00", 24 * time.Hour},
+		{"over leap day", "Asia/Tokyo", "2024-02-28T09:00:00+09:00", 2, "2024-03-01T09:00:00+09:00", 48 * time.Hour},
+		{"over february in a common year", "Asia/Tokyo", "2023-02-28T09:00:00+09:00", 1, "2023-03-01T09:00:00+09:00", 24 * time.Hour},
+		{"over month end", "Asia/Tokyo", "2024-04-30T23:30:00+09:00", 1, "2024-05-01T23:30:00+09:00", 24 * time.Hour},
+		{"over year end", "Asia/Tokyo", "2024-12-31T23:30:00+09:00", 1, "2025-01-01T23:30:00+09:00", 24 * time.Hour},
+		{"zero days", "America/New_York", "2024-03-10T12:00:00-04:00", 0, "2024-03-10T12:00:00-04:00", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustParseTime(t, tt.now).In(mustLoadLocation(t, tt.zone))
+			filter := newDeadlineFilter("dueWithin", tt.days, now)
+			if want := mustParseTime(t, tt.wantUntil); !filter.Until.Equal(want) {
+				t.Errorf("Until = %s, want %s", filter.Until, tt.wantUntil)
+			}
+			if got := filter.Until.Sub(filter.Now); got != tt.wantLen {
+				t.Errorf("Until - Now = %s, want %s", got, tt.wantLen)
+			}
+			if filter.Deadline != "dueWithin" || filter.Days != tt.days || !filter.Now.Equal(now) {
+				t.Errorf("filter = %+v", filter)
+			}
+		})
+	}
+}
+
+func TestGetMyProductsUsesUserTimeZone(t *testing.T) {
+	pr := &fakeProductRepository{}
+	pu := newTestProductUsecase(pr, "America/New_York")
+
+	if _, _, err := pu.GetMyProducts(1, 1, 10, "dueWithin", 3, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := pr.filter.Now.Location().String(); got != "America/New_York" {
+		t.Errorf("Now location = %s, want America/New_York", got)
+	}
+	if want := pr.filter.Now.AddDate(0, 0, 3); !pr.filter.Until.Equal(want) {
+		t.Errorf("Until = %s, want %s", pr.filter.Until, want)
+	}
+}
+
+func TestGetMyProductsTimeLimitDateRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		date     time.Time
+		wantFrom string
+		wantTo   string
+	}{
+		{"DST start day", "America/New_York", date(2024, 3, 10), "2024-03-10T05:00:00Z", "2024-03-11T04:00:00Z"},
+		{"DST end day", "America/New_York", date(2024, 11, 3), "2024-11-03T04:00:00Z", "2024-11-04T05:00:00Z"},
+		{"leap day", "Asia/Tokyo", date(2024, 2, 29), "2024-02-28T15:00:00Z", "2024-02-29T15:00:00Z"},
+		{"year end", "Asia/Tokyo", date(2024, 12, 31), "2024-12-30T15:00:00Z", "2024-12-31T15:00:00Z"},
+		{"default time zone", "", date(2024, 1, 1), "2023-12-31T15:00:00Z", "2024-01-01T15:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &fakeProductRepository{}
+			if _, _, err := newTestProductUsecase(pr, tt.zone).GetMyProductsTimeLimitDate(1, 1, 10, tt.date); err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParseTime(t, tt.wantFrom); !pr.from.Equal(want) {
+				t.Errorf("from = %s, want %s", pr.from.UTC().Format(time.RFC3339), tt.wantFrom)
+			}
+			if want := mustParseTime(t, tt.wantTo); !pr.to.Equal(want) {
+				t.Errorf("to = %s, want %s", pr.to.UTC().Format(time.RFC3339), tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestGetMyProductsTimeLimitYearMonthRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		month    time.Time
+		wantFrom string
+		wantTo   string
+	}{
+		{"month containing DST start", "America/New_York", date(2024, 3, 1), "2024-03-01T05:00:00Z", "2024-04-01T04:00:00Z"},
+		{"february in a leap year", "Asia/Tokyo", date(2024, 2, 1), "2024-01-31T15:00:00Z", "2024-02-29T15:00:00Z"},
+		{"december", "Asia/Tokyo", date(2024, 12, 1), "2024-11-30T15:00:00Z", "2024-12-31T15:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &fakeProductRepository{}
+			if _, err := newTestProductUsecase(pr, tt.zone).GetMyProductsTimeLimitYearMonth(1, tt.month); err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParseTime(t, tt.wantFrom); !pr.from.Equal(want) {
+				t.Errorf("from = %s, want %s", pr.from.UTC().Format(time.RFC3339), tt.wantFrom)
+			}
+			if want := mustParseTime(t, tt.wantTo); !pr.to.Equal(want) {
+				t.Errorf("to = %s, want %s", pr.to.UTC().Format(time.RFC3339), tt.wantTo)
+			}
+		})
+	}
+}
+
+// newMarketplaceTestUsecase はFakeProviderだけを登録した商品のユースケースを作成する
+func newMarketplaceTestUsecase(t *testing.T, products ...model.Product) (*productUsecase, *fakeProductRepository, *fakeProductPriceSnapshotRepository, *fakeNotificationUsecase) {
+	t.Helper()
+	t.Setenv("PRODUCT_PROVIDERS", "")
+	mpr := marketplace.NewRegistry()
+	mpr.Register(marketplace.NewFakeProvider("demo",
+		marketplace.Item{Code: "A-1", Name: "商品A", Description: "説明", Url: "https://demo.example.com/items/A-1", Image: "https://demo.example.com/a.png", Price: 1500, Stock: true, Review: 4.2},
+		marketplace.Item{Code: "B-2", Name: "商品B", Url: "https://demo.example.com/items/B-2", Price: 800, Stock: false, Review: 3.0},
+	))
+	mpr.Register(marketplace.NewFakeProvider("other",
+		marketplace.Item{Code: "C-3", Name: "商品C", Url: "https://other.example.com/c/3", Price: 300},
+	))
+
+	pr := &fakeProductRepository{products: map[uint]model.Product{}}
+	for _, p := range products {
+		pr.products[p.ID] = p
+	}
+	psr := &fakeProductPriceSnapshotRepository{pr: pr}
+	nu := &fakeNotificationUsecase{}
+	pu := newTestProductUsecase(pr, "Asia/Tokyo")
+	pu.psr, pu.nu, pu.mpr = psr, nu, mpr
+	return pu, pr, psr, nu
+}
+
+func TestLookupProduct(t *testing.T) {
+	pu, _, _, _ := newMarketplaceTestUsecase(t)
+
+	tests := []struct {
+		name     string
+		provider string
+		code     string
+		url      string
+		want     model.ProductLookupResponse
+		wantErr  error
+	}{
+		{
+			name:     "by code",
+			provider: "demo",
+			code:     "A-1",
+			want:     model.ProductLookupResponse{Provider: "demo", Code: "A-1", Name: "商品A", Description: "説明", Url: "https://demo.example.com/items/A-1", Image: "https://demo.example.com/a.png", Price: 1500, Stock: true, Review: 4.2},
+		},
+		{
+			name:     "by url with provider",
+			provider: "demo",
+			url:      "https://demo.example.com/items/B-2",
+			want:     model.ProductLookupResponse{Provider: "demo", Code: "B-2", Name: "商品B", Url: "https://demo.example.com/items/B-2", Price: 800, Review: 3.0},
+		},
+		{
+			name: "finds the provider from url",
+			url:  "https://other.example.com/c/3/",
+			want: model.ProductLookupResponse{Provider: "other", Code: "C-3", Name: "商品C", Url: "https://other.example.com/c/3", Price: 300},
+		},
+		{name: "unknown code", provider: "demo", code: "Z-9", wantErr: marketplace.ErrNotFound},
+		{name: "url of another provider", provider: "demo", url: "https://other.example.com/c/3", wantErr: marketplace.ErrUnsupportedURL},
+		{name: "url no provider supports", url: "https://unknown.example.com/items/1", wantErr: marketplace.ErrUnsupportedURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pu.LookupProduct(tt.provider, tt.code, tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupProduct() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LookupProduct() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupProductInvalidRequest(t *testing.T) {
+	pu, _, _, _ := newMarketplaceTestUsecase(t)
+
+	if _, err := pu.LookupProduct("unknown", "A-1", ""); err == nil {
+		t.Error("unknown provider: no error")
+	}
+	if _, err := pu.LookupProduct("", "A-1", ""); err == nil {
+		t.Error("code without provider: no error")
+	}
+	if _, err := pu.LookupProduct("", "", ""); err == nil {
+		t.Error("empty request: no error")
+	}
+}
+
+func TestRefreshProduct(t *testing.T) {
+	targetPrice := uint(1000)
+	pu, pr, psr, nu := newMarketplaceTestUsecase(t,
+		model.Product{ID: 1, Name: "商品A", Provider: "demo", Code: "A-1", Price: 2000, Stock: false, Review: 3.0, TargetPrice: &targetPrice, UserId: 1},
+	)
+
+	res, err := pu.RefreshProduct(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Price != 1500 || !res.Stock || res.Review != 4.2 {
+		t.Errorf("response = price %d, stock %t, review %v", res.Price, res.Stock, res.Review)
+	}
+	if p := pr.products[1]; p.Price != 1500 || !p.Stock || p.Review != 4.2 {
+		t.Errorf("product = price %d, stock %t, review %v", p.Price, p.Stock, p.Review)
+	}
+	if len(psr.snapshots) != 1 {
+		t.Fatalf("snapshots = %d, want 1", len(psr.snapshots))
+	}
+	if s := psr.snapshots[0]; s.Source != model.ProductPriceSourceImport || s.Price != 1500 || s.ProductId != 1 || s.UserId != 1 {
+		t.Errorf("snapshot = %+v", s)
+	}
+	// 目標価格を上回っていたので通知しない
+	if len(nu.messages) != 0 {
+		t.Errorf("messages = %v, want none", nu.messages)
+	}
+}
+
+func TestRefreshProductNotifiesPriceDrop(t *testing.T) {
+	targetPrice := uint(1500)
+	pu, _, _, nu := newMarketplaceTestUsecase(t,
+		model.Product{ID: 1, Name: "商品A", Provider: "demo", Code: "A-1", Price: 2000, TargetPrice: &targetPrice, UserId: 1},
+	)
+
+	if _, err := pu.RefreshProduct(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(nu.messages) != 1 {
+		t.Fatalf("messages = %d, want 1", len(nu.messages))
+	}
+}
+
+func TestRefreshProductErrors(t *testing.T) {
+	pu, _, psr, _ := newMarketplaceTestUsecase(t,
+		model.Product{ID: 1, Provider: "demo", Code: "Z-9", UserId: 1},
+		model.Product{ID: 2, Provider: "unknown", Code: "A-1", UserId: 1},
+		model.Product{ID: 3, Provider: "demo", Code: "A-1", UserId: 2},
+		model.Product{ID: 5, Provider: "demo", UserId: 1},
+	)
+
+	tests := []struct {
+		name      string
+		productId uint
+		wantErr   error
+	}{
+		{"item removed from the provider", 1, marketplace.ErrNotFound},
+		{"unknown provider", 2, nil},
+		{"product of another user", 3, nil},
+		{"missing product", 4, nil},
+		{"product without code", 5, ErrProductNotLinked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pu.RefreshProduct(1, tt.productId)
+			if err == nil {
+				t.Fatal("RefreshProduct() error = nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RefreshProduct() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(psr.snapshots) != 0 {
+		t.Errorf("snapshots = %d, want 0", len(psr.snapshots))
+	}
+}
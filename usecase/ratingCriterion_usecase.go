@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+)
+
+type IRatingCriterionUsecase interface {
+	GetCriteriaByCategory(category string) ([]model.RatingCriterionResponse, error)
+}
+
+type ratingCriterionUsecase struct {
+	cr repository.IRatingCriterionRepository
+}
+
+func NewRatingCriterionUsecase(cr repository.IRatingCriterionRepository) IRatingCriterionUsecase {
+	return &ratingCriterionUsecase{cr}
+}
+
+func (cu *ratingCriterionUsecase) GetCriteriaByCategory(category string) ([]model.RatingCriterionResponse, error) {
+	criteria := []model.RatingCriterion{}
+	if err := cu.cr.GetCriteriaByCategory(&criteria, category); err != nil {
+		return nil, err
+	}
+
+	resCriteria := []model.RatingCriterionResponse{}
+	for _, v := range criteria {
+		c := model.RatingCriterionResponse{
+			Key:    v.Key,
+			Name:   v.Name,
+			Min:    v.Min,
+			Max:    v.Max,
+			Step:   v.Step,
+			Weight: v.Weight,
+		}
+		resCriteria = append(resCriteria, c)
+	}
+	return resCriteria, nil
+}
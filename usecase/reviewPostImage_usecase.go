@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"merchandise-review-list-backend/imaging"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/storage"
+	"merchandise-review-list-backend/validator"
+	"net/http"
+)
+
+type IReviewPostImageUsecase interface {
+	UploadImages(userId uint, postId uint, files [][]byte) ([]model.ReviewPostImageResponse, error)
+	DeleteImage(userId uint, postId uint, id uint) error
+	ReorderImages(userId uint, postId uint, imageIds []uint) ([]model.ReviewPostImageResponse, error)
+}
+
+type reviewPostImageUsecase struct {
+	ir repository.IReviewPostImageRepository
+	iv validator.IReviewPostImageValidator
+	rr repository.IReviewPostRepository
+	bs storage.BlobStore
+}
+
+func NewReviewPostImageUsecase(
+	ir repository.IReviewPostImageRepository,
+	iv validator.IReviewPostImageValidator,
+	rr repository.IReviewPostRepository,
+	bs storage.BlobStore,
+) IReviewPostImageUsecase {
+	return &reviewPostImageUsecase{ir, iv, rr, bs}
+}
+
+func (iu *reviewPostImageUsecase) UploadImages(userId uint, postId uint, files [][]byte) ([]model.ReviewPostImageResponse, error) {
+	if err := iu.checkOwner(userId, postId); err != nil {
+		return nil, err
+	}
+
+	images := []model.ReviewPostImage{}
+	if err := iu.ir.GetImagesByPostId(&images, postId); err != nil {
+		return nil, err
+	}
+
+	// 全ファイルを先に検証してから保存する
+	for _, f := range files {
+		if err := iu.iv.ReviewPostImageValidator(http.DetectContentType(f), int64(len(f)), len(images)+len(files)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 保存を始める前に全ファイルをデコードしておき、途中の画像で失敗してファイルが残らないようにする
+	processed := []imaging.Processed{}
+	for _, f := range files {
+		p, err := imaging.Process(f, http.DetectContentType(f))
+		if err != nil {
+			return nil, err
+		}
+		processed = append(processed, p)
+	}
+
+	position, err := iu.ir.GetMaxPosition(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	newImages := []model.ReviewPostImage{}
+	for _, p := range processed {
+		position++
+		image, err := iu.store(postId, p, position)
+		if err != nil {
+			deleteAllBlobs(iu.bs, newImages)
+			return nil, err
+		}
+		newImages = append(newImages, image)
+	}
+	if err := iu.ir.CreateImages(&newImages); err != nil {
+		deleteAllBlobs(iu.bs, newImages)
+		return nil, err
+	}
+
+	resImages := []model.ReviewPostImageResponse{}
+	for _, v := range newImages {
+		resImages = append(resImages, toReviewPostImageResponse(v))
+	}
+	return resImages, nil
+}
+
+func (iu *reviewPostImageUsecase) DeleteImage(userId uint, postId uint, id uint) error {
+	if err := iu.checkOwner(userId, postId); err != nil {
+		return err
+	}
+
+	image := model.ReviewPostImage{}
+	if err := iu.ir.GetImageById(&image, postId, id); err != nil {
+		return err
+	}
+	if err := iu.ir.DeleteImage(postId, id); err != nil {
+		return err
+	}
+	deleteBlobs(iu.bs, image)
+	return nil
+}
+
+func (iu *reviewPostImageUsecase) ReorderImages(userId uint, postId uint, imageIds []uint) ([]model.ReviewPostImageResponse, error) {
+	if err := iu.checkOwner(userId, postId); err != nil {
+		return nil, err
+	}
+
+	images := []model.ReviewPostImage{}
+	if err := iu.ir.GetImagesByPostId(&images, postId); err != nil {
+		return nil, err
+	}
+	if len(imageIds) != len(images) {
+		return nil, errors.New("image_ids must contain every image of the post")
+	}
+
+	if err := iu.ir.UpdatePositions(postId, imageIds); err != nil {
+		return nil, err
+	}
+
+	images = []model.ReviewPostImage{}
+	if err := iu.ir.GetImagesByPostId(&images, postId); err != nil {
+		return nil, err
+	}
+	resImages := []model.ReviewPostImageResponse{}
+	for _, v := range images {
+		resImages = append(resImages, toReviewPostImageResponse(v))
+	}
+	return resImages, nil
+}
+
+func (iu *reviewPostImageUsecase) checkOwner(userId uint, postId uint) error {
+	reviewPost := model.ReviewPost{}
+	if err := iu.rr.GetReviewPostById(&reviewPost, postId); err != nil {
+		return err
+	}
+	if reviewPost.UserId != userId {
+		return errors.New("object does not exist")
+	}
+	return nil
+}
+
+// store は加工済みの画像のオリジナルとサムネイルをBlobStoreに保存する
+func (iu *reviewPostImageUsecase) store(postId uint, processed imaging.Processed, position int) (model.ReviewPostImage, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return model.ReviewPostImage{}, err
+	}
+
+	image := model.ReviewPostImage{
+		Key:         fmt.Sprintf("reviewPosts/%d/%s", postId, hex.EncodeToString(random)),
+		Ext:         processed.Original.Ext,
+		ContentType: processed.Original.ContentType,
+		Width:       processed.Original.Width,
+		Height:      processed.Original.Height,
+		Position:    position,
+		PostId:      postId,
+	}
+
+	var err error
+	image.Url, err = iu.bs.Put(image.Key+"_original"+image.Ext, processed.Original.Data, processed.Original.ContentType)
+	if err != nil {
+		return model.ReviewPostImage{}, err
+	}
+
+	for _, t := range imaging.ThumbnailSizes {
+		thumb := processed.Thumbnails[t.Name]
+		url, err := iu.bs.Put(image.Key+"_"+t.Name+thumb.Ext, thumb.Data, thumb.ContentType)
+		if err != nil {
+			deleteBlobs(iu.bs, image)
+			return model.ReviewPostImage{}, err
+		}
+		switch t.Name {
+		case "small":
+			image.SmallUrl = url
+		case "medium":
+			image.MediumUrl = url
+		case "large":
+			image.LargeUrl = url
+		}
+	}
+	return image, nil
+}
+
+// deleteBlobs は保存済みのファイルを削除する（存在しないものは無視）
+func deleteBlobs(bs storage.BlobStore, image model.ReviewPostImage) {
+	bs.Delete(image.Key + "_original" + image.Ext)
+	for _, t := range imaging.ThumbnailSizes {
+		bs.Delete(image.Key + "_" + t.Name + ".jpg")
+	}
+}
+
+func deleteAllBlobs(bs storage.BlobStore, images []model.ReviewPostImage) {
+	for _, image := range images {
+		deleteBlobs(bs, image)
+	}
+}
+
+func toReviewPostImageResponse(image model.ReviewPostImage) model.ReviewPostImageResponse {
+	return model.ReviewPostImageResponse{
+		ID:        image.ID,
+		Url:       image.Url,
+		SmallUrl:  image.SmallUrl,
+		MediumUrl: image.MediumUrl,
+		LargeUrl:  image.LargeUrl,
+		Width:     image.Width,
+		Height:    image.Height,
+		Position:  image.Position,
+	}
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"testing"
+)
+
+type fakeBlobStore struct {
+	blobs   map[string][]byte
+	failPut int // 指定した回数目のPutを失敗させる（0は失敗させない）
+	puts    int
+}
+
+func (bs *fakeBlobStore) Put(key string, data []byte, contentType string) (string, error) {
+	bs.puts++
+	if bs.puts == bs.failPut {
+		return "", errors.New("put failed")
+	}
+	bs.blobs[key] = data
+	return "/uploads/" + key, nil
+}
+
+func (bs *fakeBlobStore) Delete(key string) error {
+	delete(bs.blobs, key)
+	return nil
+}
+
+type fakeReviewPostImageRepository struct {
+	repository.IReviewPostImageRepository
+	images     []model.ReviewPostImage
+	failCreate bool
+}
+
+func (ir *fakeReviewPostImageRepository) CreateImages(images *[]model.ReviewPostImage) error {
+	if ir.failCreate {
+		return errors.New("insert failed")
+	}
+	for i := range *images {
+		(*images)[i].ID = uint(len(ir.images) + 1)
+		ir.images = append(ir.images, (*images)[i])
+	}
+	return nil
+}
+
+func (ir *fakeReviewPostImageRepository) GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error {
+	*images = append(*images, ir.images...)
+	return nil
+}
+
+func (ir *fakeReviewPostImageRepository) GetMaxPosition(postId uint) (int, error) {
+	return len(ir.images) - 1, nil
+}
+
+type fakeImageOwnerRepository struct {
+	repository.IReviewPostRepository
+}
+
+func (rr *fakeImageOwnerRepository) GetReviewPostById(reviewPost *model.ReviewPost, postId uint) error {
+	reviewPost.ID = postId
+	reviewPost.UserId = 1
+	return nil
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadImages(t *testing.T) {
+	img := testPNG(t)
+	// 1枚あたりオリジナルとサムネイル3種類の4ファイルを保存する
+	const blobsPerImage = 4
+
+	tests := []struct {
+		name       string
+		files      [][]byte
+		failPut    int
+		failCreate bool
+		wantErr    bool
+		wantImages int
+	}{
+		{name: "stores every image", files: [][]byte{img, img}, wantImages: 2},
+		{name: "invalid file stores nothing", files: [][]byte{img, append([]byte("\x89PNG\r\n\x1a\n"), 0)}, wantErr: true},
+		{name: "blob failure on second image removes the first", files: [][]byte{img, img}, failPut: blobsPerImage + 2, wantErr: true},
+		{name: "insert failure removes every blob", files: [][]byte{img, img}, failCreate: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := &fakeBlobStore{blobs: map[string][]byte{}, failPut: tt.failPut}
+			ir := &fakeReviewPostImageRepository{failCreate: tt.failCreate}
+			iu := NewReviewPostImageUsecase(ir, validator.NewReviewPostImageValidator(), &fakeImageOwnerRepository{}, bs)
+
+			res, err := iu.UploadImages(1, 10, tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadImages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(res) != tt.wantImages || len(ir.images) != tt.wantImages {
+				t.Errorf("got %d responses and %d rows, want %d", len(res), len(ir.images), tt.wantImages)
+			}
+			if len(bs.blobs) != tt.wantImages*blobsPerImage {
+				t.Errorf("%d blobs remain, want %d", len(bs.blobs), tt.wantImages*blobsPerImage)
+			}
+		})
+	}
+}
+
+func TestUploadImagesRejectsOtherUsersPost(t *testing.T) {
+	bs := &fakeBlobStore{blobs: map[string][]byte{}}
+	iu := NewReviewPostImageUsecase(&fakeReviewPostImageRepository{}, validator.NewReviewPostImageValidator(), &fakeImageOwnerRepository{}, bs)
+
+	if _, err := iu.UploadImages(2, 10, [][]byte{testPNG(t)}); err == nil {
+		t.Fatal("UploadImages() allowed another user's post")
+	}
+	if len(bs.blobs) != 0 {
+		t.Errorf("%d blobs stored", len(bs.blobs))
+	}
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"time"
+)
+
+const (
+	// 経過時間による減衰の強さ（Hacker Newsと同じ値）
+	rankingGravity = 1.8
+	// コメントはいいねより手間がかかるので重みを大きくする
+	rankingCommentWeight = 2.0
+)
+
+// rankingPeriods は集計期間ごとの対象とする長さ
+var rankingPeriods = map[string]time.Duration{
+	model.RankingPeriodDay:   24 * time.Hour,
+	model.RankingPeriodWeek:  7 * 24 * time.Hour,
+	model.RankingPeriodMonth: 30 * 24 * time.Hour,
+}
+
+type IReviewPostScoreUsecase interface {
+	ComputeScores() error
+	GetRanking(period string, category string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error)
+}
+
+type reviewPostScoreUsecase struct {
+	rsr repository.IReviewPostScoreRepository
+	ru  IReviewPostUsecase
+}
+
+func NewReviewPostScoreUsecase(rsr repository.IReviewPostScoreRepository, ru IReviewPostUsecase) IReviewPostScoreUsecase {
+	return &reviewPostScoreUsecase{rsr, ru}
+}
+
+// ComputeScores は全ての集計期間のスコアを再計算する（バックグラウンドジョブから定期的に呼ばれる）
+func (rsu *reviewPostScoreUsecase) ComputeScores() error {
+	now := time.Now()
+	for period, duration := range rankingPeriods {
+		activities := []model.ReviewPostActivity{}
+		if err := rsu.rsr.GetActivities(&activities, now.Add(-duration)); err != nil {
+			return err
+		}
+
+		scores := []model.ReviewPostScore{}
+		for _, v := range activities {
+			scores = append(scores, model.ReviewPostScore{
+				Period:     period,
+				Category:   v.Category,
+				Score:      calculateRankingScore(v.Likes, v.Comments, now.Sub(v.PublishedAt)),
+				Likes:      v.Likes,
+				Comments:   v.Comments,
+				ComputedAt: now,
+				PostId:     v.PostId,
+			})
+		}
+		if err := rsu.rsr.ReplaceScores(period, scores); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rsu *reviewPostScoreUsecase) GetRanking(period string, category string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error) {
+	if period == "" {
+		period = model.RankingPeriodWeek
+	}
+	if _, ok := rankingPeriods[period]; !ok {
+		return nil, 0, errors.New("period must be day, week or month")
+	}
+	if category == "" {
+		category = "all"
+	}
+
+	postIds, totalCount, err := rsu.rsr.GetRankedPostIds(period, category, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resReviewPosts, err := rsu.ru.GetReviewPostsByIds(postIds, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resReviewPosts, totalCount, nil
+}
+
+// calculateRankingScore は反応数を公開からの経過時間で減衰させたスコアを返す
+// score = (likes + comments * weight) / (経過時間[h] + 2) ^ gravity
+func calculateRankingScore(likes uint, comments uint, age time.Duration) float64 {
+	points := float64(likes) + float64(comments)*rankingCommentWeight
+	hours := math.Max(age.Hours(), 0)
+	return points / math.Pow(hours+2, rankingGravity)
+}
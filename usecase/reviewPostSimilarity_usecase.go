@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"errors"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/similarity"
+	"time"
+)
+
+const (
+	similarReviewPostLimit = 10
+	// 類似度を計算する対象とする新しい投稿の件数
+	similarCandidateLimit = 1000
+	// キャッシュの有効期間（対象の投稿が更新された場合も再計算する）
+	similarCacheTTL = time.Hour
+)
+
+type IReviewPostSimilarityUsecase interface {
+	GetSimilarReviewPosts(postId uint, userId uint) ([]model.ReviewPostResponse, error)
+}
+
+type reviewPostSimilarityUsecase struct {
+	rsr repository.IReviewPostSimilarityRepository
+	rr  repository.IReviewPostRepository
+	ru  IReviewPostUsecase
+}
+
+func NewReviewPostSimilarityUsecase(
+	rsr repository.IReviewPostSimilarityRepository,
+	rr repository.IReviewPostRepository,
+	ru IReviewPostUsecase,
+) IReviewPostSimilarityUsecase {
+	return &reviewPostSimilarityUsecase{rsr, rr, ru}
+}
+
+func (rsu *reviewPostSimilarityUsecase) GetSimilarReviewPosts(postId uint, userId uint) ([]model.ReviewPostResponse, error) {
+	reviewPost := model.ReviewPost{}
+	if err := rsu.rr.GetReviewPostById(&reviewPost, postId); err != nil {
+		return nil, err
+	}
+	if reviewPost.Status != model.ReviewPostStatusPublished {
+		return nil, errors.New("object does not exist")
+	}
+
+	similarities := []model.ReviewPostSimilarity{}
+	if err := rsu.rsr.GetSimilarities(&similarities, postId); err != nil {
+		return nil, err
+	}
+	if !isSimilarityCacheFresh(similarities, reviewPost) {
+		var err error
+		if similarities, err = rsu.computeSimilarities(reviewPost); err != nil {
+			return nil, err
+		}
+	}
+
+	postIds := []uint{}
+	for _, v := range similarities {
+		postIds = append(postIds, v.SimilarPostId)
+	}
+	return rsu.ru.GetReviewPostsByIds(postIds, userId)
+}
+
+// computeSimilarities は新しい投稿の中から類似する投稿を計算してキャッシュする
+func (rsu *reviewPostSimilarityUsecase) computeSimilarities(reviewPost model.ReviewPost) ([]model.ReviewPostSimilarity, error) {
+	candidates := []model.ReviewPost{}
+	if err := rsu.rsr.GetCandidates(&candidates, similarCandidateLimit); err != nil {
+		return nil, err
+	}
+
+	postIds := []uint{reviewPost.ID}
+	for _, v := range candidates {
+		postIds = append(postIds, v.ID)
+	}
+	tags, err := rsu.rsr.GetTagsByPostIds(postIds)
+	if err != nil {
+		return nil, err
+	}
+
+	corpus := []similarity.Document{}
+	for _, v := range candidates {
+		corpus = append(corpus, toSimilarityDocument(v, tags[v.ID]))
+	}
+	results := similarity.Rank(toSimilarityDocument(reviewPost, tags[reviewPost.ID]), corpus, similarReviewPostLimit)
+
+	now := time.Now()
+	similarities := []model.ReviewPostSimilarity{}
+	for _, v := range results {
+		similarities = append(similarities, model.ReviewPostSimilarity{
+			Score:         v.Score,
+			ComputedAt:    now,
+			PostId:        reviewPost.ID,
+			SimilarPostId: v.ID,
+		})
+	}
+	if err := rsu.rsr.ReplaceSimilarities(reviewPost.ID, similarities); err != nil {
+		return nil, err
+	}
+	return similarities, nil
+}
+
+func isSimilarityCacheFresh(similarities []model.ReviewPostSimilarity, reviewPost model.ReviewPost) bool {
+	if len(similarities) == 0 {
+		return false
+	}
+	computedAt := similarities[0].ComputedAt
+	return time.Since(computedAt) < similarCacheTTL && computedAt.After(reviewPost.UpdatedAt)
+}
+
+func toSimilarityDocument(reviewPost model.ReviewPost, tags []string) similarity.Document {
+	return similarity.Document{
+		ID:       reviewPost.ID,
+		Text:     reviewPost.Title + "\n" + reviewPost.Text,
+		Category: reviewPost.Category,
+		Tags:     tags,
+	}
+}
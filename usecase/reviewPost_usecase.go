@@ -1,9 +1,15 @@
 package usecase
 
 import (
+	"errors"
+	"log"
+	"math"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
 	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/storage"
 	"merchandise-review-list-backend/validator"
+	"time"
 )
 
 type IReviewPostUsecase interface {
@@ -14,75 +20,186 @@ type IReviewPostUsecase interface {
 	GetReviewPostById(postId uint) (model.ReviewPostResponse, error)
 	GetReviewPostLists(category string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error)
 	GetMyLikes(userId uint, page int, pageSize int) ([]model.ReviewPostResponse, int, error)
+	GetProductReviews(provider string, code string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, model.ProductReviewAggregateResponse, error)
+	GetReviewPostRevisions(postId uint) ([]model.ReviewPostRevisionResponse, error)
+	PublishScheduledReviewPosts() error
+	GetReviewPostsByIds(postIds []uint, userId uint) ([]model.ReviewPostResponse, error)
 }
 
 type reviewPostUsecase struct {
-	rr repository.IReviewPostRepository
-	rv validator.IReviewPostValidator
-	lr repository.ILikeRepository
+	rr  repository.IReviewPostRepository
+	rv  validator.IReviewPostValidator
+	lr  repository.ILikeRepository
+	cr  repository.IRatingCriterionRepository
+	cgr repository.ICategoryRepository
+	mr  repository.IModerationRepository
+	md  moderation.Moderator
+	bs  storage.BlobStore
 }
 
 func NewReviewPostUsecase(
 	rr repository.IReviewPostRepository,
 	rv validator.IReviewPostValidator,
 	lr repository.ILikeRepository,
+	cr repository.IRatingCriterionRepository,
+	cgr repository.ICategoryRepository,
+	mr repository.IModerationRepository,
+	md moderation.Moderator,
+	bs storage.BlobStore,
 ) IReviewPostUsecase {
-	return &reviewPostUsecase{rr, rv, lr}
+	return &reviewPostUsecase{rr, rv, lr, cr, cgr, mr, md, bs}
 }
 
 func (ru *reviewPostUsecase) CreateReviewPost(reviewPost model.ReviewPost) (model.ReviewPostResponse, error) {
-	if err := ru.rv.ReviewPostValidator(reviewPost); err != nil {
+	if reviewPost.Status == "" {
+		reviewPost.Status = model.ReviewPostStatusPublished
+	}
+	if err := ru.validateReviewPost(&reviewPost, nil); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+	verdict, err := ru.moderateReviewPost(&reviewPost, nil)
+	if err != nil {
 		return model.ReviewPostResponse{}, err
 	}
+	reviewPost.EditedAt = nil
 	if err := ru.rr.CreateReviewPost(&reviewPost); err != nil {
 		return model.ReviewPostResponse{}, err
 	}
-	resReviewPost := model.ReviewPostResponse{
-		ID:        reviewPost.ID,
-		Title:     reviewPost.Title,
-		Text:      reviewPost.Text,
-		Image:     reviewPost.Image,
-		Category:  reviewPost.Category,
-		CreatedAt: reviewPost.CreatedAt,
-		User: model.ReviewPostUserResponse{
-			ID:    reviewPost.User.ID,
-			Name:  reviewPost.User.Name,
-			Image: reviewPost.User.Image,
-		},
-		UserId: reviewPost.UserId,
+	if err := ru.createModerationHold(verdict, reviewPost.ID, reviewPost.UserId); err != nil {
+		return model.ReviewPostResponse{}, err
 	}
-	return resReviewPost, nil
+	return ru.toReviewPostResponse(reviewPost, reviewPost.UserId)
 }
 
 func (ru *reviewPostUsecase) UpdateReviewPost(reviewPost model.ReviewPost, userId uint, postId uint) (model.ReviewPostResponse, error) {
-	if err := ru.rv.ReviewPostValidator(reviewPost); err != nil {
+	current := model.ReviewPost{}
+	if err := ru.rr.GetReviewPostById(&current, postId); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+	if current.UserId != userId {
+		return model.ReviewPostResponse{}, errors.New("object does not exist")
+	}
+	reviewPost.UserId = userId
+
+	if reviewPost.Status == "" {
+		reviewPost.Status = current.Status
+		// 確認待ちの投稿を編集した場合は、改めて検査したうえで公開する
+		if current.Status == model.ReviewPostStatusPending {
+			reviewPost.Status = model.ReviewPostStatusPublished
+		}
+	}
+	if err := ru.validateReviewPost(&reviewPost, &current); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+	verdict, err := ru.moderateReviewPost(&reviewPost, &current)
+	if err != nil {
 		return model.ReviewPostResponse{}, err
 	}
 	if err := ru.rr.UpdateReviewPost(&reviewPost, userId, postId); err != nil {
 		return model.ReviewPostResponse{}, err
 	}
-	resReviewPost := model.ReviewPostResponse{
-		ID:        reviewPost.ID,
-		Title:     reviewPost.Title,
-		Text:      reviewPost.Text,
-		Image:     reviewPost.Image,
-		Review:    reviewPost.Review,
-		Category:  reviewPost.Category,
-		CreatedAt: reviewPost.CreatedAt,
-		User: model.ReviewPostUserResponse{
-			ID:    reviewPost.User.ID,
-			Name:  reviewPost.User.Name,
-			Image: reviewPost.User.Image,
-		},
+	if err := ru.createModerationHold(verdict, postId, userId); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+	return ru.toReviewPostResponse(reviewPost, userId)
+}
+
+// moderateReviewPost は公開される投稿のタイトルと本文を検査し、保留の場合は確認待ちの状態にする
+// 下書きと、公開済みの投稿でタイトル・本文が変わらない更新は検査しない
+func (ru *reviewPostUsecase) moderateReviewPost(reviewPost *model.ReviewPost, current *model.ReviewPost) (moderation.Verdict, error) {
+	if reviewPost.Status == model.ReviewPostStatusDraft {
+		return moderation.Verdict{Action: moderation.ActionAllow}, nil
+	}
+	if current != nil && current.Status == model.ReviewPostStatusPublished &&
+		current.Title == reviewPost.Title && current.Text == reviewPost.Text {
+		return moderation.Verdict{Action: moderation.ActionAllow}, nil
+	}
+
+	verdict, err := moderate(ru.md, &moderation.Content{
+		Kind:   moderation.KindReviewPost,
 		UserId: reviewPost.UserId,
+		Fields: []*string{&reviewPost.Title, &reviewPost.Text},
+	})
+	if err != nil {
+		return moderation.Verdict{}, err
+	}
+	if verdict.Action == moderation.ActionHold {
+		reviewPost.Status = model.ReviewPostStatusPending
+	}
+	return verdict, nil
+}
+
+func (ru *reviewPostUsecase) createModerationHold(verdict moderation.Verdict, postId uint, userId uint) error {
+	if verdict.Action != moderation.ActionHold {
+		return nil
+	}
+	hold := newModerationHold(moderation.KindReviewPost, postId, userId, verdict)
+	return ru.mr.CreateHold(&hold)
+}
+
+// validateReviewPost は公開状態に応じた検証を行い、公開日時を設定する
+// currentは更新前の投稿（新規作成の場合はnil）
+func (ru *reviewPostUsecase) validateReviewPost(reviewPost *model.ReviewPost, current *model.ReviewPost) error {
+	if current != nil && current.Status == model.ReviewPostStatusPublished && reviewPost.Status != model.ReviewPostStatusPublished {
+		return errors.New("published review post cannot be changed to draft or scheduled")
+	}
+
+	reviewPost.Tags = normalizeTags(reviewPost.Tags)
+
+	// 評価項目ごとの点数が送られた場合は、全項目が揃っていれば総合評価を算出する
+	if len(reviewPost.Ratings) > 0 {
+		criteria := []model.RatingCriterion{}
+		if err := ru.cr.GetCriteriaByCategory(&criteria, reviewPost.Category); err != nil {
+			return err
+		}
+		draft := reviewPost.Status == model.ReviewPostStatusDraft
+		if err := ru.rv.ReviewPostRatingsValidator(reviewPost.Ratings, criteria, draft); err != nil {
+			return err
+		}
+		if len(reviewPost.Ratings) == len(criteria) {
+			reviewPost.Review = calculateOverallReview(reviewPost.Ratings, criteria)
+		}
+	}
+
+	categoryKeys, err := ru.cgr.GetCategoryKeys()
+	if err != nil {
+		return err
+	}
+
+	switch reviewPost.Status {
+	case model.ReviewPostStatusDraft:
+		if err := ru.rv.ReviewPostDraftValidator(*reviewPost, categoryKeys); err != nil {
+			return err
+		}
+		reviewPost.PublishedAt = nil
+	case model.ReviewPostStatusScheduled:
+		if err := ru.rv.ReviewPostValidator(*reviewPost, categoryKeys); err != nil {
+			return err
+		}
+	default:
+		if err := ru.rv.ReviewPostValidator(*reviewPost, categoryKeys); err != nil {
+			return err
+		}
+		if current != nil && current.Status == model.ReviewPostStatusPublished {
+			reviewPost.PublishedAt = current.PublishedAt
+		} else {
+			now := time.Now()
+			reviewPost.PublishedAt = &now
+		}
 	}
-	return resReviewPost, nil
+	return nil
 }
 
+// DeleteReviewPost は投稿を削除する。画像の行は投稿と一緒に削除されるため、削除後に保存済みのファイルも削除する
 func (ru *reviewPostUsecase) DeleteReviewPost(userId uint, postId uint) error {
+	images := []model.ReviewPostImage{}
+	if err := ru.rr.GetImagesByPostId(&images, postId); err != nil {
+		return err
+	}
 	if err := ru.rr.DeleteReviewPost(userId, postId); err != nil {
 		return err
 	}
+	deleteAllBlobs(ru.bs, images)
 	return nil
 }
 
@@ -93,54 +210,34 @@ func (ru *reviewPostUsecase) GetMyReviewPosts(userId uint, page int, pageSize in
 		return nil, 0, err
 	}
 
-	resReviewPosts := []model.ReviewPostResponse{}
-
-	for _, v := range reviewPosts {
-
-		likes := []model.Like{}
-		err = ru.rr.GetLikesByPostId(&likes, v.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		likeCount := uint(len(likes))
-		likeId := uint(0)
-
-		for _, like := range likes {
-			if like.UserId == userId {
-				likeId = uint(like.ID)
-			}
-		}
+	resReviewPosts, err := ru.toReviewPostResponses(reviewPosts, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resReviewPosts, totalCount, nil
+}
 
-		comments := []model.Comment{}
-		err = ru.rr.GetCommentsByPostId(&comments, v.ID)
-		if err != nil {
-			return nil, 0, err
-		}
+// calculateOverallReview は各項目の点数を0〜1に正規化して重み付き平均をとり、1〜5の総合評価（小数第1位）に換算する
+func calculateOverallReview(ratings []model.ReviewPostRating, criteria []model.RatingCriterion) float64 {
+	scores := map[string]float64{}
+	for _, r := range ratings {
+		scores[r.CriterionKey] = r.Score
+	}
 
-		commentCount := uint(len(comments))
-
-		r := model.ReviewPostResponse{
-			ID:        v.ID,
-			Title:     v.Title,
-			Text:      v.Text,
-			Image:     v.Image,
-			Review:    v.Review,
-			Category:  v.Category,
-			CreatedAt: v.CreatedAt,
-			User: model.ReviewPostUserResponse{
-				ID:    v.User.ID,
-				Name:  v.User.Name,
-				Image: v.User.Image,
-			},
-			UserId:       v.UserId,
-			LikeCount:    likeCount,
-			LikeId:       likeId,
-			CommentCount: commentCount,
+	total, totalWeight := 0.0, 0.0
+	for _, c := range criteria {
+		if c.Max <= c.Min || c.Weight <= 0 {
+			continue
 		}
-		resReviewPosts = append(resReviewPosts, r)
+		total += c.Weight * (scores[c.Key] - c.Min) / (c.Max - c.Min)
+		totalWeight += c.Weight
 	}
-	return resReviewPosts, totalCount, nil
+	if totalWeight == 0 {
+		return validator.ReviewMin
+	}
+
+	overall := validator.ReviewMin + (validator.ReviewMax-validator.ReviewMin)*total/totalWeight
+	return math.Round(overall*10) / 10
 }
 
 func (ru *reviewPostUsecase) GetReviewPostById(postId uint) (model.ReviewPostResponse, error) {
@@ -148,26 +245,11 @@ func (ru *reviewPostUsecase) GetReviewPostById(postId uint) (model.ReviewPostRes
 	if err := ru.rr.GetReviewPostById(&reviewPost, postId); err != nil {
 		return model.ReviewPostResponse{}, err
 	}
-	user, err := ru.rr.GetUserById(reviewPost.UserId)
-	if err != nil {
-		return model.ReviewPostResponse{}, err
+	// 下書き・予約投稿は他のユーザーには見せない
+	if reviewPost.Status != model.ReviewPostStatusPublished {
+		return model.ReviewPostResponse{}, errors.New("object does not exist")
 	}
-	resReviewPost := model.ReviewPostResponse{
-		ID:        reviewPost.ID,
-		Title:     reviewPost.Title,
-		Text:      reviewPost.Text,
-		Image:     reviewPost.Image,
-		Review:    reviewPost.Review,
-		Category:  reviewPost.Category,
-		CreatedAt: reviewPost.CreatedAt,
-		User: model.ReviewPostUserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Image: user.Image,
-		},
-		UserId: reviewPost.UserId,
-	}
-	return resReviewPost, nil
+	return ru.toReviewPostResponse(reviewPost, 0)
 }
 
 func (ru *reviewPostUsecase) GetReviewPostLists(category string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error) {
@@ -178,55 +260,9 @@ func (ru *reviewPostUsecase) GetReviewPostLists(category string, page int, pageS
 		return nil, 0, err
 	}
 
-	resReviewPosts := []model.ReviewPostResponse{}
-	for _, v := range reviewPosts {
-		user, err := ru.rr.GetUserById(v.UserId)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		likes := []model.Like{}
-		err = ru.rr.GetLikesByPostId(&likes, v.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		likeCount := uint(len(likes))
-		likeId := uint(0)
-		for _, like := range likes {
-			if like.UserId == userId {
-				likeId = uint(like.ID)
-			}
-		}
-
-		comments := []model.Comment{}
-		err = ru.rr.GetCommentsByPostId(&comments, v.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		commentCount := uint(len(comments))
-
-		r := model.ReviewPostResponse{
-			ID:        v.ID,
-			Title:     v.Title,
-			Text:      v.Text,
-			Image:     v.Image,
-			Review:    v.Review,
-			Category:  v.Category,
-			CreatedAt: v.CreatedAt,
-			User: model.ReviewPostUserResponse{
-				ID:    user.ID,
-				Name:  user.Name,
-				Image: user.Image,
-			},
-			UserId:       v.UserId,
-			LikeCount:    likeCount,
-			LikeId:       likeId,
-			CommentCount: commentCount,
-		}
-
-		resReviewPosts = append(resReviewPosts, r)
+	resReviewPosts, err := ru.toReviewPostResponses(reviewPosts, userId)
+	if err != nil {
+		return nil, 0, err
 	}
 	return resReviewPosts, totalCount, nil
 }
@@ -237,65 +273,223 @@ func (ru *reviewPostUsecase) GetMyLikes(userId uint, page int, pageSize int) ([]
 		return nil, 0, err
 	}
 
-	postIds, errGetMyLike := ru.lr.GetMyLikePostIdsByUserId(userId, page, pageSize)
-	if errGetMyLike != nil {
+	postIds, err := ru.lr.GetMyLikePostIdsByUserId(userId, page, pageSize)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	resLikePosts := []model.ReviewPostResponse{}
+	resLikePosts, err := ru.GetReviewPostsByIds(postIds, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resLikePosts, totalLikeCount, nil
+}
 
-	for _, v := range postIds {
-		likes := []model.Like{}
-		err = ru.rr.GetLikesByPostId(&likes, v)
-		if err != nil {
-			return nil, 0, err
-		}
+func (ru *reviewPostUsecase) GetProductReviews(provider string, code string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, model.ProductReviewAggregateResponse, error) {
+	reviewPosts := []model.ReviewPost{}
+	totalCount, err := ru.rr.GetReviewPostsByProduct(&reviewPosts, provider, code, page, pageSize)
+	if err != nil {
+		return nil, 0, model.ProductReviewAggregateResponse{}, err
+	}
+
+	resReviewPosts, err := ru.toReviewPostResponses(reviewPosts, userId)
+	if err != nil {
+		return nil, 0, model.ProductReviewAggregateResponse{}, err
+	}
+
+	aggregate := model.ProductReviewAggregateResponse{}
+	if err := ru.rr.GetProductReviewAggregate(&aggregate, provider, code); err != nil {
+		return nil, 0, model.ProductReviewAggregateResponse{}, err
+	}
+	return resReviewPosts, totalCount, aggregate, nil
+}
+
+func (ru *reviewPostUsecase) GetReviewPostRevisions(postId uint) ([]model.ReviewPostRevisionResponse, error) {
+	reviewPost := model.ReviewPost{}
+	if err := ru.rr.GetReviewPostById(&reviewPost, postId); err != nil {
+		return nil, err
+	}
+	if reviewPost.Status != model.ReviewPostStatusPublished {
+		return nil, errors.New("object does not exist")
+	}
 
-		likeCount := uint(len(likes))
-		likeId := uint(0)
-		for _, like := range likes {
-			if like.UserId == userId {
-				likeId = uint(like.ID)
-			}
+	revisions := []model.ReviewPostRevision{}
+	if err := ru.rr.GetRevisionsByPostId(&revisions, postId); err != nil {
+		return nil, err
+	}
+
+	// 履歴は更新前の内容なので、最後の版は現在の投稿と比較する
+	versions := []model.ReviewPostRevision{}
+	versions = append(versions, revisions...)
+	versions = append(versions, model.ReviewPostRevision{
+		Title:           reviewPost.Title,
+		Text:            reviewPost.Text,
+		Image:           reviewPost.Image,
+		Review:          reviewPost.Review,
+		Category:        reviewPost.Category,
+		ProductProvider: reviewPost.ProductProvider,
+		ProductCode:     reviewPost.ProductCode,
+	})
+
+	resRevisions := []model.ReviewPostRevisionResponse{}
+	for i, v := range revisions {
+		resRevisions = append(resRevisions, model.ReviewPostRevisionResponse{
+			Revision: i + 1,
+			EditedAt: v.CreatedAt,
+			Changes:  diffReviewPostRevision(versions[i], versions[i+1]),
+		})
+	}
+	return resRevisions, nil
+}
+
+func diffReviewPostRevision(before model.ReviewPostRevision, after model.ReviewPostRevision) []model.ReviewPostFieldDiffResponse {
+	diffs := []model.ReviewPostFieldDiffResponse{}
+	add := func(field string, b interface{}, a interface{}) {
+		if b != a {
+			diffs = append(diffs, model.ReviewPostFieldDiffResponse{Field: field, Before: b, After: a})
 		}
+	}
+	add("title", before.Title, after.Title)
+	add("text", before.Text, after.Text)
+	add("image", before.Image, after.Image)
+	add("review", before.Review, after.Review)
+	add("category", before.Category, after.Category)
+	add("product_provider", before.ProductProvider, after.ProductProvider)
+	add("product_code", before.ProductCode, after.ProductCode)
+	return diffs
+}
+
+// PublishScheduledReviewPosts は公開日時を過ぎた予約投稿を公開する（バックグラウンドで定期実行）
+func (ru *reviewPostUsecase) PublishScheduledReviewPosts() error {
+	count, err := ru.rr.PublishScheduledReviewPosts(time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("published %d scheduled review posts", count)
+	}
+	return nil
+}
 
+// GetReviewPostsByIds は指定した順番で公開済みの投稿を返す（下書き・予約投稿は含めない）
+func (ru *reviewPostUsecase) GetReviewPostsByIds(postIds []uint, userId uint) ([]model.ReviewPostResponse, error) {
+	resReviewPosts := []model.ReviewPostResponse{}
+	for _, v := range postIds {
 		post := model.ReviewPost{}
 		if err := ru.rr.GetReviewPostById(&post, v); err != nil {
-			return nil, 0, err
+			return nil, err
+		}
+		if post.Status != model.ReviewPostStatusPublished {
+			continue
 		}
 
-		user, err := ru.rr.GetUserById(post.UserId)
+		r, err := ru.toReviewPostResponse(post, userId)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
+		resReviewPosts = append(resReviewPosts, r)
+	}
+	return resReviewPosts, nil
+}
 
-		comments := []model.Comment{}
-		err = ru.rr.GetCommentsByPostId(&comments, v)
+func (ru *reviewPostUsecase) toReviewPostResponses(reviewPosts []model.ReviewPost, userId uint) ([]model.ReviewPostResponse, error) {
+	resReviewPosts := []model.ReviewPostResponse{}
+	for _, v := range reviewPosts {
+		r, err := ru.toReviewPostResponse(v, userId)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
+		resReviewPosts = append(resReviewPosts, r)
+	}
+	return resReviewPosts, nil
+}
 
-		commentCount := uint(len(comments))
-
-		p := model.ReviewPostResponse{
-			ID:        post.ID,
-			Title:     post.Title,
-			Text:      post.Text,
-			Image:     post.Image,
-			Review:    post.Review,
-			Category:  post.Category,
-			CreatedAt: post.CreatedAt,
-			User: model.ReviewPostUserResponse{
-				ID:    user.ID,
-				Name:  user.Name,
-				Image: user.Image,
-			},
-			UserId:       post.UserId,
-			LikeCount:    likeCount,
-			LikeId:       likeId,
-			CommentCount: commentCount,
+// toReviewPostResponse は投稿者・いいね・コメント・画像を付与したレスポンスを作成する
+// userIdは閲覧中のユーザー（未ログインの場合は0）で、いいね済みかの判定に使用する
+func (ru *reviewPostUsecase) toReviewPostResponse(v model.ReviewPost, userId uint) (model.ReviewPostResponse, error) {
+	user := &v.User
+	if user.ID == 0 {
+		u, err := ru.rr.GetUserById(v.UserId)
+		if err != nil {
+			return model.ReviewPostResponse{}, err
 		}
-		resLikePosts = append(resLikePosts, p)
+		user = u
 	}
-	return resLikePosts, totalLikeCount, nil
+
+	likes := []model.Like{}
+	if err := ru.rr.GetLikesByPostId(&likes, v.ID); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+
+	likeCount := uint(len(likes))
+	likeId := uint(0)
+	for _, like := range likes {
+		if userId != 0 && like.UserId == userId {
+			likeId = uint(like.ID)
+		}
+	}
+
+	comments := []model.Comment{}
+	if err := ru.rr.GetCommentsByPostId(&comments, v.ID); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+
+	commentCount := uint(len(comments))
+
+	images, err := ru.getImages(v.ID)
+	if err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+
+	ratings := []model.ReviewPostRatingResponse{}
+	if err := ru.rr.GetRatingsByPostId(&ratings, v.ID); err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+
+	tags, err := ru.rr.GetTagsByPostId(v.ID)
+	if err != nil {
+		return model.ReviewPostResponse{}, err
+	}
+
+	r := model.ReviewPostResponse{
+		ID:              v.ID,
+		Title:           v.Title,
+		Text:            v.Text,
+		Image:           v.Image,
+		Images:          images,
+		Review:          v.Review,
+		Ratings:         ratings,
+		Category:        v.Category,
+		Tags:            tags,
+		ProductProvider: v.ProductProvider,
+		ProductCode:     v.ProductCode,
+		CreatedAt:       v.CreatedAt,
+		Edited:          v.EditedAt != nil,
+		EditedAt:        v.EditedAt,
+		Status:          v.Status,
+		PublishedAt:     v.PublishedAt,
+		User: model.ReviewPostUserResponse{
+			ID:    user.ID,
+			Name:  user.Name,
+			Image: user.Image,
+		},
+		UserId:       v.UserId,
+		LikeCount:    likeCount,
+		LikeId:       likeId,
+		CommentCount: commentCount,
+	}
+	return r, nil
+}
+
+func (ru *reviewPostUsecase) getImages(postId uint) ([]model.ReviewPostImageResponse, error) {
+	images := []model.ReviewPostImage{}
+	if err := ru.rr.GetImagesByPostId(&images, postId); err != nil {
+		return nil, err
+	}
+
+	resImages := []model.ReviewPostImageResponse{}
+	for _, v := range images {
+		resImages = append(resImages, toReviewPostImageResponse(v))
+	}
+	return resImages, nil
 }
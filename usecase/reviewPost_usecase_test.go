@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"errors"
+	"merchandise-review-list-backend/imaging"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeReviewPostRepository struct {
+	repository.IReviewPostRepository
+	posts  map[uint]model.ReviewPost
+	images map[uint][]model.ReviewPostImage
+}
+
+func newFakeReviewPostRepository(posts ...model.ReviewPost) *fakeReviewPostRepository {
+	rr := &fakeReviewPostRepository{posts: map[uint]model.ReviewPost{}, images: map[uint][]model.ReviewPostImage{}}
+	for _, p := range posts {
+		rr.posts[p.ID] = p
+	}
+	return rr
+}
+
+func (rr *fakeReviewPostRepository) CreateReviewPost(reviewPost *model.ReviewPost) error {
+	reviewPost.ID = uint(len(rr.posts) + 1)
+	rr.posts[reviewPost.ID] = *reviewPost
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) UpdateReviewPost(reviewPost *model.ReviewPost, userId uint, postId uint) error {
+	reviewPost.ID = postId
+	rr.posts[postId] = *reviewPost
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetReviewPostById(reviewPost *model.ReviewPost, postId uint) error {
+	p, ok := rr.posts[postId]
+	if !ok {
+		return errors.New("record not found")
+	}
+	*reviewPost = p
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetUserById(id uint) (*model.User, error) {
+	return &model.User{ID: id}, nil
+}
+
+func (rr *fakeReviewPostRepository) GetLikesByPostId(likes *[]model.Like, postId uint) error {
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetCommentsByPostId(comments *[]model.Comment, postId uint) error {
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetImagesByPostId(images *[]model.ReviewPostImage, postId uint) error {
+	*images = append(*images, rr.images[postId]...)
+	return nil
+}
+
+// DeleteReviewPost は投稿と画像の行を削除する（外部キーのON DELETE CASCADEと同じ）
+func (rr *fakeReviewPostRepository) DeleteReviewPost(userId uint, postId uint) error {
+	p, ok := rr.posts[postId]
+	if !ok || p.UserId != userId {
+		return errors.New("object does not exist")
+	}
+	delete(rr.posts, postId)
+	delete(rr.images, postId)
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetRatingsByPostId(ratings *[]model.ReviewPostRatingResponse, postId uint) error {
+	return nil
+}
+
+func (rr *fakeReviewPostRepository) GetTagsByPostId(postId uint) ([]string, error) {
+	return []string{}, nil
+}
+
+func (rr *fakeReviewPostRepository) GetProductReviewAggregates(keys []model.ProductKey) (map[model.ProductKey]model.ProductReviewAggregateResponse, error) {
+	return map[model.ProductKey]model.ProductReviewAggregateResponse{}, nil
+}
+
+type fakeCategoryRepository struct {
+	repository.ICategoryRepository
+}
+
+func (cgr *fakeCategoryRepository) GetCategoryKeys() ([]string, error) {
+	return []string{"food", "book"}, nil
+}
+
+type fakeModerationRepository struct {
+	repository.IModerationRepository
+	holds []model.ModerationHold
+}
+
+func (mr *fakeModerationRepository) CreateHold(hold *model.ModerationHold) error {
+	hold.ID = uint(len(mr.holds) + 1)
+	mr.holds = append(mr.holds, *hold)
+	return nil
+}
+
+type fakeLikeRepository struct {
+	repository.ILikeRepository
+	postIds []uint
+}
+
+func (lr *fakeLikeRepository) GetMyLikeCount(userId uint) (int, error) {
+	return len(lr.postIds), nil
+}
+
+func (lr *fakeLikeRepository) GetMyLikePostIdsByUserId(userId uint, page int, pageSize int) ([]uint, error) {
+	return lr.postIds, nil
+}
+
+// testModerator は「casino」を保留、「バカ」をマスク、「死ね」を拒否する
+func testModerator() moderation.Moderator {
+	return moderation.NewPipeline(
+		moderation.NewBannedWordFilter([]string{"死ね"}, moderation.ActionReject),
+		moderation.NewBannedWordFilter([]string{"casino"}, moderation.ActionHold),
+		moderation.NewBannedWordFilter([]string{"バカ"}, moderation.ActionMask),
+	)
+}
+
+func newTestReviewPostUsecase(rr *fakeReviewPostRepository, mr *fakeModerationRepository, lr *fakeLikeRepository) IReviewPostUsecase {
+	return NewReviewPostUsecase(rr, validator.NewReviewPostValidator(), lr, nil, &fakeCategoryRepository{}, mr, testModerator(), &fakeBlobStore{blobs: map[string][]byte{}})
+}
+
+func testReviewPost(id uint, status string, text string) model.ReviewPost {
+	var publishedAt *time.Time
+	if status == model.ReviewPostStatusPublished {
+		t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		publishedAt = &t
+	}
+	return model.ReviewPost{
+		ID:          id,
+		Title:       "タイトル",
+		Text:        text,
+		Review:      4,
+		Category:    "food",
+		Status:      status,
+		PublishedAt: publishedAt,
+		UserId:      1,
+	}
+}
+
+func TestCreateReviewPostModerationStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		text       string
+		wantErr    bool
+		wantStatus string
+		wantText   string
+		wantHold   bool
+	}{
+		{name: "clean post is published", text: "美味しい", wantStatus: model.ReviewPostStatusPublished, wantText: "美味しい"},
+		{name: "held post is pending", text: "casinoで稼ぐ", wantStatus: model.ReviewPostStatusPending, wantText: "casinoで稼ぐ", wantHold: true},
+		{name: "masked post is published", text: "バカ高い", wantStatus: model.ReviewPostStatusPublished, wantText: "**高い"},
+		{name: "rejected post is not stored", text: "死ね", wantErr: true},
+		{name: "draft is not moderated", status: model.ReviewPostStatusDraft, text: "casinoで稼ぐ", wantStatus: model.ReviewPostStatusDraft, wantText: "casinoで稼ぐ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := newFakeReviewPostRepository()
+			mr := &fakeModerationRepository{}
+			ru := newTestReviewPostUsecase(rr, mr, &fakeLikeRepository{})
+
+			post := testReviewPost(0, tt.status, tt.text)
+			res, err := ru.CreateReviewPost(post)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateReviewPost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(rr.posts) != 0 || len(mr.holds) != 0 {
+					t.Errorf("stored %d posts and %d holds", len(rr.posts), len(mr.holds))
+				}
+				return
+			}
+
+			stored := rr.posts[res.ID]
+			if stored.Status != tt.wantStatus || res.Status != tt.wantStatus {
+				t.Errorf("status = %q (response %q), want %q", stored.Status, res.Status, tt.wantStatus)
+			}
+			if stored.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", stored.Text, tt.wantText)
+			}
+			if tt.wantHold {
+				if len(mr.holds) != 1 || mr.holds[0].Kind != moderation.KindReviewPost || mr.holds[0].TargetId != res.ID ||
+					mr.holds[0].Status != model.ModerationHoldStatusPending {
+					t.Errorf("holds = %+v", mr.holds)
+				}
+			} else if len(mr.holds) != 0 {
+				t.Errorf("unexpected holds %+v", mr.holds)
+			}
+		})
+	}
+}
+
+func TestUpdateReviewPostModerationStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    model.ReviewPost
+		status     string
+		text       string
+		wantErr    bool
+		wantStatus string
+		wantHold   bool
+	}{
+		{
+			name:       "editing a pending post publishes it once clean",
+			current:    testReviewPost(1, model.ReviewPostStatusPending, "casinoで稼ぐ"),
+			text:       "美味しい",
+			wantStatus: model.ReviewPostStatusPublished,
+		},
+		{
+			name:       "editing a pending post keeps it pending while still held",
+			current:    testReviewPost(1, model.ReviewPostStatusPending, "casinoで稼ぐ"),
+			text:       "casinoで稼ぐ",
+			wantStatus: model.ReviewPostStatusPending,
+			wantHold:   true,
+		},
+		{
+			name:       "published post with new held text becomes pending",
+			current:    testReviewPost(1, model.ReviewPostStatusPublished, "美味しい"),
+			text:       "casinoで稼ぐ",
+			wantStatus: model.ReviewPostStatusPending,
+			wantHold:   true,
+		},
+		{
+			// 承認済みの投稿は本文を変えない限り検査し直さない
+			name:       "published post with unchanged text is not moderated again",
+			current:    testReviewPost(1, model.ReviewPostStatusPublished, "casinoで稼ぐ"),
+			text:       "casinoで稼ぐ",
+			wantStatus: model.ReviewPostStatusPublished,
+		},
+		{
+			name:    "published post cannot go back to draft",
+			current: testReviewPost(1, model.ReviewPostStatusPublished, "美味しい"),
+			status:  model.ReviewPostStatusDraft,
+			text:    "美味しい",
+			wantErr: true,
+		},
+		{
+			name:    "rejected text keeps the published post unchanged",
+			current: testReviewPost(1, model.ReviewPostStatusPublished, "美味しい"),
+			text:    "死ね",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := newFakeReviewPostRepository(tt.current)
+			mr := &fakeModerationRepository{}
+			ru := newTestReviewPostUsecase(rr, mr, &fakeLikeRepository{})
+
+			post := testReviewPost(0, tt.status, tt.text)
+			_, err := ru.UpdateReviewPost(post, 1, tt.current.ID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateReviewPost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !reflect.DeepEqual(rr.posts[tt.current.ID], tt.current) {
+					t.Errorf("post was changed to %+v", rr.posts[tt.current.ID])
+				}
+				return
+			}
+
+			if got := rr.posts[tt.current.ID].Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+			if (len(mr.holds) == 1) != tt.wantHold || len(mr.holds) > 1 {
+				t.Errorf("holds = %+v, wantHold %v", mr.holds, tt.wantHold)
+			}
+		})
+	}
+}
+
+func TestUpdateReviewPostRejectsOtherUser(t *testing.T) {
+	rr := newFakeReviewPostRepository(testReviewPost(1, model.ReviewPostStatusPublished, "美味しい"))
+	ru := newTestReviewPostUsecase(rr, &fakeModerationRepository{}, &fakeLikeRepository{})
+
+	if _, err := ru.UpdateReviewPost(testReviewPost(0, "", "書き換え"), 2, 1); err == nil {
+		t.Fatal("UpdateReviewPost() allowed another user")
+	}
+}
+
+// visibilityPosts は公開状態ごとの投稿（IDは公開済み・確認待ち・下書き・予約投稿の順）
+func visibilityPosts() []model.ReviewPost {
+	return []model.ReviewPost{
+		testReviewPost(1, model.ReviewPostStatusPublished, "公開済み"),
+		testReviewPost(2, model.ReviewPostStatusPending, "確認待ち"),
+		testReviewPost(3, model.ReviewPostStatusDraft, "下書き"),
+		testReviewPost(4, model.ReviewPostStatusScheduled, "予約投稿"),
+	}
+}
+
+func TestGetReviewPostByIdVisibility(t *testing.T) {
+	ru := newTestReviewPostUsecase(newFakeReviewPostRepository(visibilityPosts()...), &fakeModerationRepository{}, &fakeLikeRepository{})
+
+	for _, post := range visibilityPosts() {
+		t.Run(post.Status, func(t *testing.T) {
+			res, err := ru.GetReviewPostById(post.ID)
+			visible := post.Status == model.ReviewPostStatusPublished
+			if (err == nil) != visible {
+				t.Fatalf("GetReviewPostById() error = %v, want visible %v", err, visible)
+			}
+			if visible && res.ID != post.ID {
+				t.Errorf("ID = %d, want %d", res.ID, post.ID)
+			}
+		})
+	}
+}
+
+func TestListingsOnlyIncludePublishedPosts(t *testing.T) {
+	rr := newFakeReviewPostRepository(visibilityPosts()...)
+	lr := &fakeLikeRepository{postIds: []uint{4, 3, 2, 1}}
+	ru := newTestReviewPostUsecase(rr, &fakeModerationRepository{}, lr)
+
+	ids := func(posts []model.ReviewPostResponse) []uint {
+		res := []uint{}
+		for _, p := range posts {
+			res = append(res, p.ID)
+		}
+		return res
+	}
+
+	posts, err := ru.GetReviewPostsByIds([]uint{4, 3, 2, 1}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(posts); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("GetReviewPostsByIds() = %v, want [1]", got)
+	}
+
+	likes, _, err := ru.GetMyLikes(1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(likes); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("GetMyLikes() = %v, want [1]", got)
+	}
+}
+
+func TestDeleteReviewPostDeletesBlobs(t *testing.T) {
+	post := testReviewPost(1, model.ReviewPostStatusPublished, "レビュー")
+	rr := newFakeReviewPostRepository(post)
+	rr.images[1] = []model.ReviewPostImage{{ID: 1, Key: "posts/1/a", Ext: ".png", PostId: 1}}
+
+	bs := &fakeBlobStore{blobs: map[string][]byte{}}
+	keys := []string{"posts/1/a_original.png"}
+	for _, size := range imaging.ThumbnailSizes {
+		keys = append(keys, "posts/1/a_"+size.Name+".jpg")
+	}
+	for _, key := range keys {
+		bs.blobs[key] = []byte("data")
+	}
+	bs.blobs["posts/2/b_original.png"] = []byte("other post")
+	ru := NewReviewPostUsecase(rr, validator.NewReviewPostValidator(), &fakeLikeRepository{}, nil, &fakeCategoryRepository{}, &fakeModerationRepository{}, testModerator(), bs)
+
+	// 他のユーザーの投稿は削除せず、ファイルも残す
+	if err := ru.DeleteReviewPost(post.UserId+1, 1); err == nil {
+		t.Fatal("deleted another user's post")
+	}
+	if len(bs.blobs) != len(keys)+1 {
+		t.Fatalf("blobs = %d, want %d", len(bs.blobs), len(keys)+1)
+	}
+
+	if err := ru.DeleteReviewPost(post.UserId, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, ok := bs.blobs[key]; ok {
+			t.Errorf("%s was not deleted", key)
+		}
+	}
+	if _, ok := bs.blobs["posts/2/b_original.png"]; !ok {
+		t.Error("blob of another post was deleted")
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/repository"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultTagLimit     = 10
+	maxTagLimit         = 50
+	defaultTrendingHour = 24 * 7
+)
+
+type ITagUsecase interface {
+	GetReviewPostsByTag(tag string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error)
+	AutocompleteTags(prefix string, limit int) ([]model.TagResponse, error)
+	GetTrendingTags(hours int, limit int) ([]model.TagResponse, error)
+}
+
+type tagUsecase struct {
+	tr repository.ITagRepository
+	ru IReviewPostUsecase
+}
+
+func NewTagUsecase(tr repository.ITagRepository, ru IReviewPostUsecase) ITagUsecase {
+	return &tagUsecase{tr, ru}
+}
+
+func (tu *tagUsecase) GetReviewPostsByTag(tag string, page int, pageSize int, userId uint) ([]model.ReviewPostResponse, int, error) {
+	postIds, totalCount, err := tu.tr.GetPostIdsByTag(normalizeTag(tag), page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resReviewPosts, err := tu.ru.GetReviewPostsByIds(postIds, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resReviewPosts, totalCount, nil
+}
+
+func (tu *tagUsecase) AutocompleteTags(prefix string, limit int) ([]model.TagResponse, error) {
+	tags := []model.TagResponse{}
+	prefix = normalizeTag(prefix)
+	if prefix == "" {
+		return tags, nil
+	}
+
+	if err := tu.tr.SearchTagsByPrefix(&tags, prefix, tagLimit(limit)); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTrendingTags は直近hours時間に付けられた回数の多いタグを返す
+func (tu *tagUsecase) GetTrendingTags(hours int, limit int) ([]model.TagResponse, error) {
+	if hours <= 0 {
+		hours = defaultTrendingHour
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	tags := []model.TagResponse{}
+	if err := tu.tr.GetTrendingTags(&tags, since, tagLimit(limit)); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func tagLimit(limit int) int {
+	if limit <= 0 {
+		return defaultTagLimit
+	}
+	if limit > maxTagLimit {
+		return maxTagLimit
+	}
+	return limit
+}
+
+// normalizeTag は全角・半角を揃えて小文字にし、先頭の#を除いて空白をハイフンにする
+// 例: "＃Gluten Free" → "gluten-free"
+func normalizeTag(tag string) string {
+	tag = norm.NFKC.String(tag)
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.TrimLeft(tag, "#")
+	return strings.Join(strings.FieldsFunc(tag, unicode.IsSpace), "-")
+}
+
+// normalizeTags は正規化したタグを重複を除いて返す（空のタグは除く）
+// nilの場合はタグを変更しないことを表すのでnilのまま返す
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+	return normalized
+}
@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/moderation"
 	"merchandise-review-list-backend/repository"
 	"merchandise-review-list-backend/validator"
 
@@ -24,21 +26,28 @@ type IUserUsecase interface {
 type userUsecase struct {
 	ur repository.IUserRepository
 	uv validator.IUserValidator
+	md moderation.Moderator
 }
 
-func NweUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator) IUserUsecase {
-	return &userUsecase{ur, uv}
+func NweUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator, md moderation.Moderator) IUserUsecase {
+	return &userUsecase{ur, uv, md}
 }
 
 func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 	if err := uu.uv.UserValidate(user); err != nil {
 		return model.UserResponse{}, err
 	}
+	if err := uu.moderateName(&user, 0); err != nil {
+		return model.UserResponse{}, err
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), 10)
 	if err != nil {
 		return model.UserResponse{}, err
 	}
-	newUser := model.User{Email: user.Email, Password: string(hash), Name: user.Name, Image: user.Image}
+	if user.TimeZone == "" {
+		user.TimeZone = model.DefaultTimeZone
+	}
+	newUser := model.User{Email: user.Email, Password: string(hash), Name: user.Name, Image: user.Image, TimeZone: user.TimeZone}
 	if err := uu.ur.CreateUser(&newUser); err != nil {
 		return model.UserResponse{}, err
 	}
@@ -48,6 +57,7 @@ func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 		Name:      newUser.Name,
 		Image:     newUser.Image,
 		Admin:     newUser.Admin,
+		TimeZone:  newUser.TimeZone,
 		CreatedAt: newUser.CreatedAt,
 	}
 	return resUser, nil
@@ -99,6 +109,7 @@ func (uu *userUsecase) GetLoggedInUser(tokenString string) (*model.UserResponse,
 			Name:      user.Name,
 			Image:     user.Image,
 			Admin:     user.Admin,
+			TimeZone:  user.TimeZone,
 			CreatedAt: user.CreatedAt,
 		}, nil
 	} else {
@@ -110,6 +121,9 @@ func (uu *userUsecase) UpdateUser(user model.User, id uint) (model.UserResponse,
 	if err := uu.uv.UpdateUserValidate(user); err != nil {
 		return model.UserResponse{}, err
 	}
+	if err := uu.moderateName(&user, id); err != nil {
+		return model.UserResponse{}, err
+	}
 	if err := uu.ur.UpdateUser(&user, id); err != nil {
 		return model.UserResponse{}, err
 	}
@@ -119,6 +133,7 @@ func (uu *userUsecase) UpdateUser(user model.User, id uint) (model.UserResponse,
 		Name:      user.Name,
 		Image:     user.Image,
 		Admin:     user.Admin,
+		TimeZone:  user.TimeZone,
 		CreatedAt: user.CreatedAt,
 	}
 	return resUser, nil
@@ -130,3 +145,30 @@ func (uu *userUsecase) DeleteUser(id uint) error {
 	}
 	return nil
 }
+
+// moderateName はユーザー名を検査する
+// ユーザー名は確認待ちにできないため、保留と判定された場合も登録できない
+func (uu *userUsecase) moderateName(user *model.User, id uint) error {
+	verdict, err := moderate(uu.md, &moderation.Content{
+		Kind:   moderation.KindUserName,
+		UserId: id,
+		Fields: []*string{&user.Name},
+	})
+	if err != nil {
+		return err
+	}
+	if verdict.Action == moderation.ActionHold {
+		return errors.New("contains inappropriate content")
+	}
+	return nil
+}
+
+// userLocation はユーザーが設定したタイムゾーンを返す
+// 日付・月・年の境界の計算はすべてこのタイムゾーンで行う
+func userLocation(ur repository.IUserRepository, userId uint) (*time.Location, error) {
+	user := model.User{}
+	if err := ur.GetUserByID(&user, userId); err != nil {
+		return nil, err
+	}
+	return user.Location(), nil
+}
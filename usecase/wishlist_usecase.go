@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/notifier"
+	"merchandise-review-list-backend/repository"
+	"merchandise-review-list-backend/validator"
+	"os"
+)
+
+type IWishlistUsecase interface {
+	CreateWishlist(wishlist model.Wishlist) (model.WishlistResponse, error)
+	UpdateWishlist(wishlist model.Wishlist, userId uint, id uint) (model.WishlistResponse, error)
+	DeleteWishlist(userId uint, id uint) error
+	GetMyWishlists(userId uint) ([]model.WishlistResponse, error)
+	GetWishlistById(userId uint, id uint) (model.WishlistDetailResponse, error)
+	InviteMember(req model.WishlistInviteRequest, userId uint, id uint) (model.WishlistResponse, error)
+	UpdateMemberRole(userId uint, id uint, memberId uint, role string) (model.WishlistResponse, error)
+	RemoveMember(userId uint, id uint, memberId uint) error
+	AcceptInvite(userId uint, id uint) (model.WishlistDetailResponse, error)
+	AddItem(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error)
+	RemoveItem(userId uint, id uint, productId uint) error
+	MarkBought(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error)
+	UnmarkBought(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error)
+}
+
+type wishlistUsecase struct {
+	wr repository.IWishlistRepository
+	wv validator.IWishlistValidator
+	ur repository.IUserRepository
+	pu IProductUsecase
+	nu INotificationUsecase
+}
+
+func NewWishlistUsecase(
+	wr repository.IWishlistRepository,
+	wv validator.IWishlistValidator,
+	ur repository.IUserRepository,
+	pu IProductUsecase,
+	nu INotificationUsecase,
+) IWishlistUsecase {
+	return &wishlistUsecase{wr, wv, ur, pu, nu}
+}
+
+func (wu *wishlistUsecase) CreateWishlist(wishlist model.Wishlist) (model.WishlistResponse, error) {
+	if err := wu.wv.WishlistValidator(wishlist); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	if err := wu.wr.CreateWishlist(&wishlist); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	return wu.toWishlistResponse(wishlist, wishlist.UserId)
+}
+
+func (wu *wishlistUsecase) UpdateWishlist(wishlist model.Wishlist, userId uint, id uint) (model.WishlistResponse, error) {
+	if err := wu.wv.WishlistValidator(wishlist); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	if err := wu.wr.UpdateWishlist(&wishlist, userId, id); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	return wu.toWishlistResponse(wishlist, userId)
+}
+
+func (wu *wishlistUsecase) DeleteWishlist(userId uint, id uint) error {
+	if err := wu.wr.DeleteWishlist(userId, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wu *wishlistUsecase) GetMyWishlists(userId uint) ([]model.WishlistResponse, error) {
+	wishlists := []model.Wishlist{}
+	if err := wu.wr.GetMyWishlists(&wishlists, userId); err != nil {
+		return nil, err
+	}
+
+	resWishlists := []model.WishlistResponse{}
+	for _, v := range wishlists {
+		w, err := wu.toWishlistResponse(v, userId)
+		if err != nil {
+			return nil, err
+		}
+		resWishlists = append(resWishlists, w)
+	}
+	return resWishlists, nil
+}
+
+func (wu *wishlistUsecase) GetWishlistById(userId uint, id uint) (model.WishlistDetailResponse, error) {
+	wishlist := model.Wishlist{}
+	if err := wu.wr.GetWishlistById(&wishlist, userId, id); err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+	return wu.toWishlistDetailResponse(wishlist, userId)
+}
+
+// InviteMember はメールアドレスで登録済みのユーザーを招待し、アプリ内通知などで知らせる
+func (wu *wishlistUsecase) InviteMember(req model.WishlistInviteRequest, userId uint, id uint) (model.WishlistResponse, error) {
+	if err := wu.wv.WishlistInviteValidator(req); err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	wishlist := model.Wishlist{}
+	if err := wu.wr.GetWishlistById(&wishlist, userId, id); err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	invitee := model.User{}
+	if err := wu.ur.GetUserByEmail(&invitee, req.Email); err != nil {
+		return model.WishlistResponse{}, errors.New("user does not exist")
+	}
+
+	member := model.WishlistMember{
+		Role:       req.Role,
+		WishlistId: wishlist.ID,
+		UserId:     invitee.ID,
+	}
+	if err := wu.wr.AddMember(&member, userId); err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	msg := notifier.Message{
+		Title: fmt.Sprintf("共有リスト「%s」に招待されました", wishlist.Name),
+		Body:  "リストを開いて招待を承認してください。",
+		Link:  os.Getenv("FE_URL"),
+	}
+	// 通知の失敗で招待は取り消さない
+	if err := wu.nu.NotifyUser(invitee.ID, msg); err != nil {
+		log.Printf("failed to notify wishlist invite to user %d: %v", invitee.ID, err)
+	}
+
+	return wu.toWishlistResponse(wishlist, userId)
+}
+
+func (wu *wishlistUsecase) UpdateMemberRole(userId uint, id uint, memberId uint, role string) (model.WishlistResponse, error) {
+	if err := wu.wv.WishlistRoleValidator(role); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	if err := wu.wr.UpdateMemberRole(userId, id, memberId, role); err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	wishlist := model.Wishlist{}
+	if err := wu.wr.GetWishlistById(&wishlist, userId, id); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	return wu.toWishlistResponse(wishlist, userId)
+}
+
+func (wu *wishlistUsecase) RemoveMember(userId uint, id uint, memberId uint) error {
+	if err := wu.wr.DeleteMember(userId, id, memberId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wu *wishlistUsecase) AcceptInvite(userId uint, id uint) (model.WishlistDetailResponse, error) {
+	if err := wu.wr.AcceptInvite(userId, id); err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+	return wu.GetWishlistById(userId, id)
+}
+
+func (wu *wishlistUsecase) AddItem(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error) {
+	if err := wu.pu.AddWishlistItem(userId, id, productId); err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+	return wu.GetWishlistById(userId, id)
+}
+
+func (wu *wishlistUsecase) RemoveItem(userId uint, id uint, productId uint) error {
+	if err := wu.pu.RemoveWishlistItem(userId, id, productId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wu *wishlistUsecase) MarkBought(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error) {
+	if err := wu.pu.MarkWishlistItemBought(userId, id, productId); err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+	return wu.GetWishlistById(userId, id)
+}
+
+func (wu *wishlistUsecase) UnmarkBought(userId uint, id uint, productId uint) (model.WishlistDetailResponse, error) {
+	if err := wu.pu.UnmarkWishlistItemBought(userId, id, productId); err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+	return wu.GetWishlistById(userId, id)
+}
+
+func (wu *wishlistUsecase) toWishlistDetailResponse(wishlist model.Wishlist, userId uint) (model.WishlistDetailResponse, error) {
+	w, err := wu.toWishlistResponse(wishlist, userId)
+	if err != nil {
+		return model.WishlistDetailResponse{}, err
+	}
+
+	// 承認前の招待では商品を見せない
+	items := []model.WishlistItemResponse{}
+	if w.Accepted {
+		items, err = wu.pu.GetWishlistItems(userId, wishlist.ID)
+		if err != nil {
+			return model.WishlistDetailResponse{}, err
+		}
+	}
+
+	return model.WishlistDetailResponse{
+		WishlistResponse: w,
+		Items:            items,
+	}, nil
+}
+
+// toWishlistResponse はメンバー一覧と、ログインユーザーの権限を付与したレスポンスを作成する
+func (wu *wishlistUsecase) toWishlistResponse(wishlist model.Wishlist, userId uint) (model.WishlistResponse, error) {
+	members := []model.WishlistMember{}
+	if err := wu.wr.GetMembers(&members, wishlist.ID); err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	w := model.WishlistResponse{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Members:   []model.WishlistMemberResponse{},
+		CreatedAt: wishlist.CreatedAt,
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+	for _, v := range members {
+		if v.UserId == userId {
+			w.Role = v.Role
+			w.Accepted = v.AcceptedAt != nil
+		}
+		w.Members = append(w.Members, model.WishlistMemberResponse{
+			ID:         v.ID,
+			UserId:     v.UserId,
+			Name:       v.User.Name,
+			Email:      v.User.Email,
+			Role:       v.Role,
+			AcceptedAt: v.AcceptedAt,
+		})
+	}
+	return w, nil
+}
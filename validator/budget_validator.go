@@ -7,7 +7,8 @@ import (
 )
 
 type IBudgetValidator interface {
-	BudgetValidator(budget model.Budget) error
+	BudgetValidator(budget model.Budget, categoryKeys []string) error
+	BudgetExportValidator(req model.BudgetExportRequest) error
 }
 
 type budgetValidator struct{}
@@ -16,7 +17,7 @@ func NewBudgetValidator() IBudgetValidator {
 	return &budgetValidator{}
 }
 
-func (bv *budgetValidator) BudgetValidator(budget model.Budget) error {
+func (bv *budgetValidator) BudgetValidator(budget model.Budget, categoryKeys []string) error {
 	return validation.ValidateStruct(&budget,
 		validation.Field(
 			&budget.Month,
@@ -29,5 +30,37 @@ func (bv *budgetValidator) BudgetValidator(budget model.Budget) error {
 			validation.Required.Error("month is required"),
 			validation.RuneLength(1, 4).Error("limites max 4 char"),
 		),
+		// カテゴリーごとの予算のキーはcategoriesテーブルに登録されたものに限る
+		validation.Field(
+			&budget.Amounts,
+			validation.By(func(value interface{}) error {
+				rule := categoryIn(categoryKeys)
+				for key := range value.(map[string]uint) {
+					if err := rule.Validate(key); err != nil {
+						return err
+					}
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+func (bv *budgetValidator) BudgetExportValidator(req model.BudgetExportRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Year,
+			validation.Required.Error("year is required"),
+			validation.Date("2006").Error("year must be YYYY"),
+		),
+		validation.Field(
+			&req.Format,
+			validation.Required.Error("format is required"),
+			validation.In(model.ExportFormatCSV, model.ExportFormatJSON).Error("format must be csv or json"),
+		),
+		validation.Field(
+			&req.Encoding,
+			validation.By(exportEncoding),
+		),
 	)
 }
@@ -0,0 +1,12 @@
+package validator
+
+import validation "github.com/go-ozzo/ozzo-validation/v4"
+
+// categoryIn はcategoriesテーブルに登録されたキーのいずれかであることを検証するルールを返す
+func categoryIn(categoryKeys []string) validation.Rule {
+	keys := make([]interface{}, len(categoryKeys))
+	for i, k := range categoryKeys {
+		keys[i] = k
+	}
+	return validation.In(keys...).Error("invalid category")
+}
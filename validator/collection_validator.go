@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"merchandise-review-list-backend/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ICollectionValidator interface {
+	CollectionValidator(collection model.Collection) error
+}
+
+type collectionValidator struct{}
+
+func NewCollectionValidator() ICollectionValidator {
+	return &collectionValidator{}
+}
+
+func (cv *collectionValidator) CollectionValidator(collection model.Collection) error {
+	return validation.ValidateStruct(&collection,
+		validation.Field(
+			&collection.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limites max 50 char"),
+		),
+		validation.Field(
+			&collection.Description,
+			validation.RuneLength(0, 200).Error("limites max 200 char"),
+		),
+		validation.Field(
+			&collection.Visibility,
+			validation.Required.Error("visibility is required"),
+			validation.In(model.CollectionVisibilityPrivate, model.CollectionVisibilityShared).Error("visibility must be private or shared"),
+		),
+	)
+}
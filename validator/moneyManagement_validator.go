@@ -1,13 +1,18 @@
 package validator
 
 import (
+	"fmt"
 	"merchandise-review-list-backend/model"
+	"merchandise-review-list-backend/spreadsheet"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type IMoneyManagementValidator interface {
-	MoneyManagementValidator(moneyManagement model.MoneyManagement) error
+	MoneyManagementValidator(moneyManagement model.MoneyManagement, categoryKeys []string) error
+	MoneyManagementImportValidator(req model.MoneyManagementImportRequest) error
+	MoneyManagementExportValidator(req model.MoneyManagementExportRequest) error
 }
 
 type moneyManagementValidator struct{}
@@ -16,7 +21,7 @@ func NewMoneyManagementValidator() IMoneyManagementValidator {
 	return &moneyManagementValidator{}
 }
 
-func (mv *moneyManagementValidator) MoneyManagementValidator(moneyManagement model.MoneyManagement) error {
+func (mv *moneyManagementValidator) MoneyManagementValidator(moneyManagement model.MoneyManagement, categoryKeys []string) error {
 	return validation.ValidateStruct(&moneyManagement,
 		validation.Field(
 			&moneyManagement.Title,
@@ -26,6 +31,7 @@ func (mv *moneyManagementValidator) MoneyManagementValidator(moneyManagement mod
 		validation.Field(
 			&moneyManagement.Category,
 			validation.Required.Error("category is required"),
+			categoryIn(categoryKeys),
 		),
 		validation.Field(
 			&moneyManagement.UnitPrice,
@@ -40,8 +46,92 @@ func (mv *moneyManagementValidator) MoneyManagementValidator(moneyManagement mod
 			validation.Required.Error("totalPrice is required"),
 		),
 		validation.Field(
-			&moneyManagement.UpdatedAt,
-			validation.Required.Error("updatedAt is required"),
+			&moneyManagement.PurchasedAt,
+			validation.Required.Error("purchasedAt is required"),
 		),
 	)
 }
+
+const (
+	// MaxMoneyManagementImportSize は一括登録で取り込めるファイルのサイズの上限（5MB）
+	MaxMoneyManagementImportSize = 5 * 1024 * 1024
+	// MaxMoneyManagementImportRows は一括登録で取り込める行数の上限
+	MaxMoneyManagementImportRows = 1000
+)
+
+func (mv *moneyManagementValidator) MoneyManagementImportValidator(req model.MoneyManagementImportRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Format,
+			validation.Required.Error("format is required"),
+			validation.In(model.MoneyManagementImportFormatCSV, model.MoneyManagementImportFormatJSON).Error("format must be csv or json"),
+		),
+		validation.Field(
+			&req.Data,
+			validation.Required.Error("file is required"),
+			validation.Length(0, MaxMoneyManagementImportSize).Error("file is too large (max 5MB)"),
+		),
+		validation.Field(
+			&req.Mapping,
+			validation.By(func(value interface{}) error {
+				for field := range value.(map[string]string) {
+					if _, ok := model.DefaultMoneyManagementImportMapping[field]; !ok {
+						return fmt.Errorf("unknown mapping field: %s", field)
+					}
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+func (mv *moneyManagementValidator) MoneyManagementExportValidator(req model.MoneyManagementExportRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.From,
+			validation.Required.Error("from is required"),
+			validation.Date("2006-01-02").Error("from must be YYYY-MM-DD"),
+		),
+		validation.Field(
+			&req.To,
+			validation.Required.Error("to is required"),
+			validation.Date("2006-01-02").Error("to must be YYYY-MM-DD"),
+			validation.By(func(value interface{}) error {
+				from, err1 := time.Parse("2006-01-02", req.From)
+				to, err2 := time.Parse("2006-01-02", value.(string))
+				if err1 == nil && err2 == nil && to.Before(from) {
+					return fmt.Errorf("to must be on or after from")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&req.Format,
+			validation.Required.Error("format is required"),
+			validation.In(model.ExportFormatCSV, model.ExportFormatJSON).Error("format must be csv or json"),
+		),
+		validation.Field(
+			&req.Encoding,
+			validation.By(exportEncoding),
+		),
+	)
+}
+
+// exportEncoding は書き出しに対応した文字コードか検証する（空の場合はUTF-8）
+func exportEncoding(value interface{}) error {
+	_, err := spreadsheet.NormalizeEncoding(value.(string))
+	return err
+}
+
+// FieldErrors は検証エラーを項目名とメッセージの対応にする（項目ごとのエラーでない場合は"row"に入れる）
+func FieldErrors(err error) map[string]string {
+	errs := map[string]string{}
+	if fieldErrs, ok := err.(validation.Errors); ok {
+		for field, e := range fieldErrs {
+			errs[field] = e.Error()
+		}
+		return errs
+	}
+	errs["row"] = err.Error()
+	return errs
+}
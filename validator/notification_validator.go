@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"merchandise-review-list-backend/model"
+	"net/url"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type INotificationValidator interface {
+	NotificationSettingValidator(req model.NotificationSettingRequest) error
+}
+
+const (
+	MaxReminderLeadTimes   = 5
+	MaxReminderLeadMinutes = 30 * 24 * 60
+)
+
+type notificationValidator struct{}
+
+func NewNotificationValidator() INotificationValidator {
+	return &notificationValidator{}
+}
+
+func (nv *notificationValidator) NotificationSettingValidator(req model.NotificationSettingRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.WebhookUrl,
+			validation.RuneLength(0, 2048).Error("limites max 2048 char"),
+			is.URL.Error("is not valid url format"),
+			// 送信時にも確認するが、保存時点でhttps以外は受け付けない
+			validation.By(func(value interface{}) error {
+				webhookUrl, _ := value.(string)
+				if u, err := url.Parse(webhookUrl); webhookUrl != "" && (err != nil || u.Scheme != "https") {
+					return validation.NewError("validation_https", "webhook url must use https")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&req.LeadMinutes,
+			validation.Length(0, MaxReminderLeadTimes).Error("limited max 5 lead times"),
+			validation.Each(
+				validation.Min(1).Error("lead minutes must be between 1 and 43200"),
+				validation.Max(MaxReminderLeadMinutes).Error("lead minutes must be between 1 and 43200"),
+			),
+		),
+	)
+}
@@ -0,0 +1,27 @@
+package validator
+
+import (
+	"merchandise-review-list-backend/model"
+	"testing"
+)
+
+func TestNotificationSettingValidatorWebhookUrl(t *testing.T) {
+	tests := []struct {
+		webhookUrl string
+		wantErr    bool
+	}{
+		{"", false},
+		{"https://hooks.example.com/abc", false},
+		{"http://hooks.example.com/abc", true},
+		{"ftp://hooks.example.com/abc", true},
+		{"not a url", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.webhookUrl, func(t *testing.T) {
+			err := NewNotificationValidator().NotificationSettingValidator(model.NotificationSettingRequest{WebhookUrl: tt.webhookUrl})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NotificationSettingValidator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
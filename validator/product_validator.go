@@ -2,13 +2,32 @@ package validator
 
 import (
 	"merchandise-review-list-backend/model"
+	"regexp"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
 
+const (
+	// MaxProductPrice は登録できる価格の上限
+	MaxProductPrice = 100000000
+	// MaxSnoozeMinutes は期限を一度に延長できる長さ（1年）
+	MaxSnoozeMinutes = 60 * 24 * 365
+	// MaxDueWithinDays は期限が近い商品を絞り込む日数の上限
+	MaxDueWithinDays = 365
+)
+
+var productProviderPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
 type IProductValidator interface {
-	ProductValidator(product model.Product) error
+	ProductValidator(product model.Product, requireCode bool) error
+	TimeLimitValidator(product model.Product) error
+	TimeLimitRequestValidator(req model.ProductTimeLimitRequest) error
+	DeadlineFilterValidator(filter model.ProductDeadlineFilter) error
+	PriceSnapshotValidator(req model.ProductPriceSnapshotRequest) error
+	TargetPriceValidator(req model.ProductTargetPriceRequest) error
+	PurchaseValidator(req model.ProductPurchaseRequest) error
 }
 
 type productValidator struct{}
@@ -17,28 +36,198 @@ func NewProductValidator() IProductValidator {
 	return &productValidator{}
 }
 
-func (pv *productValidator) ProductValidator(product model.Product) error {
+// ProductValidator は商品の登録・更新時の検証（期限はTimeLimitValidatorで検証する）
+// requireCodeがfalseの場合はproviderとcodeを省略できる（コードを持たない以前の商品の更新）
+func (pv *productValidator) ProductValidator(product model.Product, requireCode bool) error {
 	return validation.ValidateStruct(&product,
 		validation.Field(
-			&product.TimeLimit,
+			&product.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 255).Error("limites max 255 char"),
+		),
+		validation.Field(
+			&product.Url,
+			validation.Required.Error("url is required"),
+			is.URL.Error("is not valid url format"),
+		),
+		validation.Field(
+			&product.Image,
+			is.URL.Error("is not valid url format"),
+		),
+		validation.Field(
+			&product.Price,
+			validation.Max(uint(MaxProductPrice)).Error("price is too large"),
+		),
+		validation.Field(
+			&product.TargetPrice,
+			validation.Max(uint(MaxProductPrice)).Error("target_price is too large"),
+		),
+		validation.Field(
+			&product.Provider,
+			validation.When(requireCode,
+				validation.Required.Error("provider is required"),
+				validation.Match(productProviderPattern).Error("provider must be lowercase alphanumeric"),
+			),
+			validation.RuneLength(0, 50).Error("limites max 50 char"),
+		),
+		validation.Field(
+			&product.Code,
+			validation.When(requireCode, validation.Required.Error("code is required")),
+			validation.RuneLength(0, 255).Error("limites max 255 char"),
+		),
+		validation.Field(
+			&product.Notes,
+			validation.RuneLength(0, 1000).Error("limites max 1000 char"),
+		),
+		validation.Field(
+			&product.Priority,
+			validation.Min(model.ProductPriorityNone).Error("priority must be between 0 and 3"),
+			validation.Max(model.ProductPriorityHigh).Error("priority must be between 0 and 3"),
+		),
+	)
+}
 
-			validation.When(product.TimeLimit != time.Time{},
-				validation.By(func(value interface{}) error {
-					timeLimit, ok := value.(time.Time)
-					if !ok {
-						return validation.NewError("validation_type", "invalid TimeLimit type")
-					}
+// TimeLimitValidator は期限のみを更新する場合の検証
+func (pv *productValidator) TimeLimitValidator(product model.Product) error {
+	return validation.ValidateStruct(&product,
+		validation.Field(
+			&product.TimeLimit,
+			validation.By(futureTimeLimit),
+		),
+	)
+}
 
-					now := time.Now()
+// TimeLimitRequestValidator は期限の変更方法ごとに必要な項目を検証する
+func (pv *productValidator) TimeLimitRequestValidator(req model.ProductTimeLimitRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Mode,
+			validation.Required.Error("mode is required"),
+			validation.In(model.ProductTimeLimitModeSet, model.ProductTimeLimitModeSnooze, model.ProductTimeLimitModeClear).Error("mode must be set, snooze or clear"),
+		),
+		validation.Field(
+			&req.TimeLimit,
+			validation.When(req.Mode == model.ProductTimeLimitModeSet,
+				validation.Required.Error("timeLimit is required"),
+				validation.By(futureTimeLimit),
+			),
+		),
+		validation.Field(
+			&req.Snooze,
+			validation.When(req.Mode == model.ProductTimeLimitModeSnooze,
+				validation.Required.Error("snooze is required"),
+				validation.In(model.ProductSnoozeHour, model.ProductSnoozeDay, model.ProductSnoozeWeek, model.ProductSnoozeCustom).Error("snooze must be 1h, 1d, 1w or custom"),
+			),
+		),
+		validation.Field(
+			&req.Minutes,
+			validation.When(req.Mode == model.ProductTimeLimitModeSnooze && req.Snooze == model.ProductSnoozeCustom,
+				validation.Required.Error("minutes is required"),
+				validation.Min(1).Error("minutes must be between 1 and 525600"),
+				validation.Max(MaxSnoozeMinutes).Error("minutes must be between 1 and 525600"),
+			),
+		),
+	)
+}
 
-					// 現在日時よりも過去の場合はエラー
-					if timeLimit.Before(now) {
-						return validation.NewError("validation_future", "TimeLimit must be in the future")
-					}
+// DeadlineFilterValidator は期限による絞り込みの条件を検証する
+func (pv *productValidator) DeadlineFilterValidator(filter model.ProductDeadlineFilter) error {
+	return validation.ValidateStruct(&filter,
+		validation.Field(
+			&filter.Deadline,
+			validation.In(model.ProductDeadlineWith, model.ProductDeadlineWithout, model.ProductDeadlineOverdue, model.ProductDeadlineDueWithin).Error("deadline must be with, without, overdue or dueWithin"),
+		),
+		validation.Field(
+			&filter.Days,
+			validation.When(filter.Deadline == model.ProductDeadlineDueWithin,
+				validation.Required.Error("days is required"),
+				validation.Min(1).Error("days must be between 1 and 365"),
+				validation.Max(MaxDueWithinDays).Error("days must be between 1 and 365"),
+			),
+		),
+	)
+}
 
+// PriceSnapshotValidator は価格の記録の検証
+func (pv *productValidator) PriceSnapshotValidator(req model.ProductPriceSnapshotRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Price,
+			validation.Max(uint(MaxProductPrice)).Error("price is too large"),
+		),
+		validation.Field(
+			&req.Review,
+			validation.Min(0.0).Error("review must be between 0 and 5"),
+			validation.Max(ReviewMax).Error("review must be between 0 and 5"),
+		),
+		validation.Field(
+			&req.Source,
+			validation.In(model.ProductPriceSourceManual, model.ProductPriceSourceImport).Error("source must be manual or import"),
+		),
+		validation.Field(
+			&req.ObservedAt,
+			validation.By(func(value interface{}) error {
+				observedAt, ok := value.(*time.Time)
+				if !ok || observedAt == nil {
 					return nil
-				}),
-			),
+				}
+				if observedAt.After(time.Now()) {
+					return validation.NewError("validation_past", "observed_at must not be in the future")
+				}
+				return nil
+			}),
 		),
 	)
 }
+
+func (pv *productValidator) TargetPriceValidator(req model.ProductTargetPriceRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.TargetPrice,
+			validation.Max(uint(MaxProductPrice)).Error("target_price is too large"),
+		),
+	)
+}
+
+// PurchaseValidator は商品からの家計簿の登録の検証（家計簿の項目はMoneyManagementValidatorで検証する）
+func (pv *productValidator) PurchaseValidator(req model.ProductPurchaseRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.UnitPrice,
+			validation.Max(uint(MaxProductPrice)).Error("unit_price is too large"),
+		),
+		validation.Field(
+			&req.Quantity,
+			validation.Max(uint(1000)).Error("quantity must be 1000 or less"),
+		),
+		validation.Field(
+			&req.After,
+			validation.In(model.ProductPurchaseKeep, model.ProductPurchaseArchive, model.ProductPurchaseRemove).Error("after must be keep, archive or remove"),
+		),
+	)
+}
+
+// futureTimeLimit は期限が未来であるか確認する（期限なしの場合は確認しない）
+func futureTimeLimit(value interface{}) error {
+	var timeLimit time.Time
+	switch v := value.(type) {
+	case time.Time:
+		timeLimit = v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		timeLimit = *v
+	default:
+		return validation.NewError("validation_type", "invalid TimeLimit type")
+	}
+
+	now := time.Now()
+
+	// 現在日時よりも過去の場合はエラー
+	if timeLimit.Before(now) {
+		return validation.NewError("validation_future", "TimeLimit must be in the future")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"merchandise-review-list-backend/model"
+	"testing"
+)
+
+func TestProductValidatorRequireCode(t *testing.T) {
+	base := model.Product{Name: "商品", Url: "https://example.com/item"}
+	withCode := base
+	withCode.Provider, withCode.Code = "rakuten", "shop:123"
+	invalidProvider := withCode
+	invalidProvider.Provider = "Rakuten"
+
+	tests := []struct {
+		name        string
+		product     model.Product
+		requireCode bool
+		wantErr     bool
+	}{
+		{"create requires provider and code", base, true, true},
+		{"create with provider and code", withCode, true, false},
+		{"create checks provider format", invalidProvider, true, true},
+		{"legacy product can be updated without code", base, false, false},
+		{"legacy product can be given a code", withCode, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewProductValidator().ProductValidator(tt.product, tt.requireCode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProductValidator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	MaxReviewPostImageSize  = 5 << 20 // 5MB
+	MaxReviewPostImageCount = 10
+)
+
+type IReviewPostImageValidator interface {
+	ReviewPostImageValidator(contentType string, size int64, imageCount int) error
+}
+
+type reviewPostImageValidator struct{}
+
+func NewReviewPostImageValidator() IReviewPostImageValidator {
+	return &reviewPostImageValidator{}
+}
+
+// contentTypeはクライアントの申告ではなくファイルの中身から判定したものを渡す
+func (iv *reviewPostImageValidator) ReviewPostImageValidator(contentType string, size int64, imageCount int) error {
+	return validation.Errors{
+		"content_type": validation.Validate(contentType,
+			validation.Required.Error("content type is required"),
+			validation.In("image/jpeg", "image/png").Error("only jpeg and png are allowed"),
+		),
+		"size": validation.Validate(size,
+			validation.Required.Error("file is empty"),
+			validation.Max(int64(MaxReviewPostImageSize)).Error("limited max 5MB"),
+		),
+		"image_count": validation.Validate(imageCount,
+			validation.Max(MaxReviewPostImageCount).Error("limited max 10 images per post"),
+		),
+	}.Filter()
+}
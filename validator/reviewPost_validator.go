@@ -1,22 +1,36 @@
 package validator
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"merchandise-review-list-backend/model"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type IReviewPostValidator interface {
-	ReviewPostValidator(reviewPost model.ReviewPost) error
+	ReviewPostValidator(reviewPost model.ReviewPost, categoryKeys []string) error
+	ReviewPostDraftValidator(reviewPost model.ReviewPost, categoryKeys []string) error
+	ReviewPostRatingsValidator(ratings []model.ReviewPostRating, criteria []model.RatingCriterion, draft bool) error
 }
 
+const (
+	ReviewMin  = 1.0
+	ReviewMax  = 5.0
+	ReviewStep = 0.5
+
+	MaxTagsPerPost = 10
+)
+
 type reviewPostValidator struct{}
 
 func NewReviewPostValidator() IReviewPostValidator {
 	return &reviewPostValidator{}
 }
 
-func (rv *reviewPostValidator) ReviewPostValidator(reviewPost model.ReviewPost) error {
+func (rv *reviewPostValidator) ReviewPostValidator(reviewPost model.ReviewPost, categoryKeys []string) error {
 	return validation.ValidateStruct(&reviewPost,
 		validation.Field(
 			&reviewPost.Title,
@@ -28,9 +42,133 @@ func (rv *reviewPostValidator) ReviewPostValidator(reviewPost model.ReviewPost)
 			validation.Required.Error("text is required"),
 			validation.RuneLength(1, 150).Error("limites max 150 char"),
 		),
+		validation.Field(
+			&reviewPost.Category,
+			categoryIn(categoryKeys),
+		),
 		validation.Field(
 			&reviewPost.Review,
 			validation.Required.Error("review is required"),
+			validation.Min(ReviewMin).Error("review must be between 1 and 5"),
+			validation.Max(ReviewMax).Error("review must be between 1 and 5"),
+			// 評価項目から算出した総合評価は刻み幅を問わない
+			validation.When(len(reviewPost.Ratings) == 0, validation.By(func(value interface{}) error {
+				if !isStep(value.(float64), ReviewMin, ReviewStep) {
+					return validation.NewError("validation_step", "review must be in steps of 0.5")
+				}
+				return nil
+			})),
+		),
+		// 商品と紐付ける場合はproviderとcodeの両方が必要
+		validation.Field(
+			&reviewPost.ProductProvider,
+			validation.When(reviewPost.ProductCode != "", validation.Required.Error("product_provider is required")),
+			validation.RuneLength(0, 50).Error("limites max 50 char"),
+		),
+		validation.Field(
+			&reviewPost.ProductCode,
+			validation.When(reviewPost.ProductProvider != "", validation.Required.Error("product_code is required")),
+			validation.RuneLength(0, 255).Error("limites max 255 char"),
+		),
+		validation.Field(
+			&reviewPost.Tags,
+			validation.Length(0, MaxTagsPerPost).Error("limited max 10 tags"),
+			validation.Each(validation.RuneLength(1, 30).Error("limites max 30 char")),
+		),
+		validation.Field(
+			&reviewPost.Status,
+			validation.In(model.ReviewPostStatusDraft, model.ReviewPostStatusScheduled, model.ReviewPostStatusPublished).Error("invalid status"),
+		),
+		// 予約投稿の場合は未来の公開日時が必要
+		validation.Field(
+			&reviewPost.PublishedAt,
+			validation.When(reviewPost.Status == model.ReviewPostStatusScheduled,
+				validation.Required.Error("published_at is required"),
+				validation.By(func(value interface{}) error {
+					publishedAt, ok := value.(*time.Time)
+					if !ok || publishedAt == nil {
+						return nil
+					}
+					if publishedAt.Before(time.Now()) {
+						return validation.NewError("validation_future", "published_at must be in the future")
+					}
+					return nil
+				}),
+			),
 		),
 	)
 }
+
+// ReviewPostDraftValidator は下書き用の検証で、未入力の項目は許容し文字数のみ確認する
+func (rv *reviewPostValidator) ReviewPostDraftValidator(reviewPost model.ReviewPost, categoryKeys []string) error {
+	return validation.ValidateStruct(&reviewPost,
+		validation.Field(
+			&reviewPost.Title,
+			validation.RuneLength(0, 50).Error("limites max 50 char"),
+		),
+		validation.Field(
+			&reviewPost.Text,
+			validation.RuneLength(0, 150).Error("limites max 150 char"),
+		),
+		validation.Field(
+			&reviewPost.Category,
+			categoryIn(categoryKeys),
+		),
+		validation.Field(
+			&reviewPost.Tags,
+			validation.Length(0, MaxTagsPerPost).Error("limited max 10 tags"),
+			validation.Each(validation.RuneLength(1, 30).Error("limites max 30 char")),
+		),
+	)
+}
+
+// ReviewPostRatingsValidator はカテゴリーの評価項目設定に従って各項目の点数を検証する
+// 下書きの場合は未入力の項目を許容する
+func (rv *reviewPostValidator) ReviewPostRatingsValidator(ratings []model.ReviewPostRating, criteria []model.RatingCriterion, draft bool) error {
+	if len(criteria) == 0 {
+		return errors.New("this category has no rating criteria")
+	}
+
+	criterionByKey := map[string]model.RatingCriterion{}
+	for _, c := range criteria {
+		criterionByKey[c.Key] = c
+	}
+
+	errs := validation.Errors{}
+	scored := map[string]bool{}
+	for _, r := range ratings {
+		c, ok := criterionByKey[r.CriterionKey]
+		if !ok {
+			errs[r.CriterionKey] = errors.New("unknown rating criterion")
+			continue
+		}
+		if scored[r.CriterionKey] {
+			errs[r.CriterionKey] = errors.New("duplicate rating criterion")
+			continue
+		}
+		scored[r.CriterionKey] = true
+
+		if r.Score < c.Min || r.Score > c.Max {
+			errs[r.CriterionKey] = fmt.Errorf("score must be between %g and %g", c.Min, c.Max)
+		} else if !isStep(r.Score, c.Min, c.Step) {
+			errs[r.CriterionKey] = fmt.Errorf("score must be in steps of %g", c.Step)
+		}
+	}
+
+	if !draft {
+		for _, c := range criteria {
+			if !scored[c.Key] {
+				errs[c.Key] = errors.New("score is required")
+			}
+		}
+	}
+	return errs.Filter()
+}
+
+func isStep(value float64, min float64, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	n := (value - min) / step
+	return math.Abs(n-math.Round(n)) < 1e-9
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"merchandise-review-list-backend/model"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -37,6 +38,10 @@ func (uv *userValidator) UserValidate(user model.User) error {
 			validation.Required.Error("name is required"),
 			validation.RuneLength(1, 30).Error("limited min 1 max 30 char"),
 		),
+		validation.Field(
+			&user.TimeZone,
+			validation.By(validTimeZone),
+		),
 	)
 }
 
@@ -53,5 +58,25 @@ func (uv *userValidator) UpdateUserValidate(user model.User) error {
 			validation.Required.Error("name is required"),
 			validation.RuneLength(1, 30).Error("limited min 6 max 30 char"),
 		),
+		validation.Field(
+			&user.TimeZone,
+			validation.By(validTimeZone),
+		),
 	)
 }
+
+// validTimeZone はIANAのタイムゾーン名として読み込めるか確認する（未指定は許容する）
+func validTimeZone(value interface{}) error {
+	name, _ := value.(string)
+	if name == "" {
+		return nil
+	}
+	// "Local"はサーバーの設定に依存するため受け付けない
+	if name == "Local" {
+		return validation.NewError("validation_time_zone", "invalid time zone")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return validation.NewError("validation_time_zone", "invalid time zone")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"merchandise-review-list-backend/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type IWishlistValidator interface {
+	WishlistValidator(wishlist model.Wishlist) error
+	WishlistInviteValidator(req model.WishlistInviteRequest) error
+	WishlistRoleValidator(role string) error
+}
+
+type wishlistValidator struct{}
+
+func NewWishlistValidator() IWishlistValidator {
+	return &wishlistValidator{}
+}
+
+func (wv *wishlistValidator) WishlistValidator(wishlist model.Wishlist) error {
+	return validation.ValidateStruct(&wishlist,
+		validation.Field(
+			&wishlist.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limites max 50 char"),
+		),
+	)
+}
+
+func (wv *wishlistValidator) WishlistInviteValidator(req model.WishlistInviteRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Email,
+			validation.Required.Error("email is required"),
+			is.Email.Error("is not valid email format"),
+		),
+		validation.Field(
+			&req.Role,
+			validation.Required.Error("role is required"),
+			validation.In(model.WishlistRoleEditor, model.WishlistRoleViewer).Error("role must be editor or viewer"),
+		),
+	)
+}
+
+// WishlistRoleValidator は招待・変更できる権限か確認する（ownerは作成者のみ）
+func (wv *wishlistValidator) WishlistRoleValidator(role string) error {
+	return validation.Validate(role,
+		validation.Required.Error("role is required"),
+		validation.In(model.WishlistRoleEditor, model.WishlistRoleViewer).Error("role must be editor or viewer"),
+	)
+}